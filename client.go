@@ -31,12 +31,26 @@ type ListOptions struct {
 	Filter string
 }
 
+// rsqlEqual returns an RSQL filter matching field equal to value. The value
+// is quoted, so spaces, commas, semicolons, and quotes in it are matched
+// literally instead of changing the query.
+func rsqlEqual(field, value string) string {
+	return field + `=="` + rsqlEscaper.Replace(value) + `"`
+}
+
+// rsqlEscaper escapes the characters that are special inside a quoted RSQL value.
+var rsqlEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
 // Client manages communication with the Terrakube API.
 type Client struct {
-	baseURL    *url.URL
-	token      string
-	httpClient *http.Client
-	userAgent  string
+	baseURL           *url.URL
+	uiURL             *url.URL
+	token             string
+	httpClient        *http.Client
+	userAgent         string
+	stateCache        *stateCache
+	validateTemplates bool
+	secretResolver    SecretResolver
 
 	Organizations         *OrganizationService
 	Workspaces            *WorkspaceService
@@ -69,6 +83,7 @@ type Client struct {
 	GithubAppTokens       *GithubAppTokenService
 	Addresses             *AddressService
 	Operations            *OperationsService
+	Registry              *RegistryService
 }
 
 // Option configures a Client.
@@ -92,6 +107,25 @@ func WithEndpoint(endpoint string) Option {
 	}
 }
 
+// WithUIEndpoint sets the Terrakube UI URL used to build links to jobs and
+// workspaces. It defaults to the API endpoint.
+func WithUIEndpoint(endpoint string) Option {
+	return func(c *Client) error {
+		if endpoint == "" {
+			return fmt.Errorf("UI endpoint must not be empty")
+		}
+		if !strings.HasPrefix(endpoint, "http://") && !strings.HasPrefix(endpoint, "https://") {
+			endpoint = "https://" + endpoint
+		}
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return fmt.Errorf("invalid UI endpoint URL: %w", err)
+		}
+		c.uiURL = u
+		return nil
+	}
+}
+
 // WithToken sets the API bearer token.
 func WithToken(token string) Option {
 	return func(c *Client) error {
@@ -129,6 +163,39 @@ func WithUserAgent(ua string) Option {
 	}
 }
 
+// WithStateCache keeps the latest Terraform state of each workspace in
+// memory, checked against its MD5 checksum, so repeated Workspaces.Outputs
+// calls only download a state when the workspace's latest state has changed.
+func WithStateCache() Option {
+	return func(c *Client) error {
+		c.stateCache = &stateCache{states: map[string]cachedState{}}
+		return nil
+	}
+}
+
+// WithTemplateValidation makes Templates.Create and Templates.Update run
+// Templates.Validate on the template content before sending it.
+func WithTemplateValidation() Option {
+	return func(c *Client) error {
+		c.validateTemplates = true
+		return nil
+	}
+}
+
+// WithSecretResolver expands secret references, such as env://NAME or
+// file:///path, in the values passed to the Create and Update methods of
+// Variables, OrganizationVariables, and CollectionItems. Resolved values are
+// sent as sensitive; the caller's struct is not modified.
+func WithSecretResolver(r SecretResolver) Option {
+	return func(c *Client) error {
+		if r == nil {
+			return fmt.Errorf("secret resolver must not be nil")
+		}
+		c.secretResolver = r
+		return nil
+	}
+}
+
 // NewClient creates a new Terrakube API client. It returns an error if WithEndpoint or WithToken are not provided.
 func NewClient(opts ...Option) (*Client, error) {
 	c := &Client{
@@ -148,6 +215,9 @@ func NewClient(opts ...Option) (*Client, error) {
 	if c.token == "" {
 		return nil, fmt.Errorf("token is required: use WithToken()")
 	}
+	if c.uiURL == nil {
+		c.uiURL = c.baseURL
+	}
 
 	c.Organizations = &OrganizationService{crudService[Organization]{client: c}}
 	c.Workspaces = &WorkspaceService{crudService[Workspace]{client: c, filterKey: "filter[workspace]"}}
@@ -180,6 +250,7 @@ func NewClient(opts ...Option) (*Client, error) {
 	c.GithubAppTokens = &GithubAppTokenService{crudService[GithubAppToken]{client: c}}
 	c.Addresses = &AddressService{crudService[Address]{client: c, filterKey: "filter[address]"}}
 	c.Operations = &OperationsService{client: c}
+	c.Registry = &RegistryService{client: c}
 
 	return c, nil
 }
@@ -193,6 +264,12 @@ func (c *Client) apiPath(segments ...string) string {
 	return p
 }
 
+// JobURL returns the Terrakube UI link to a job's run page.
+func (c *Client) JobURL(orgID, workspaceID, jobID string) string {
+	rel := &url.URL{Path: path.Join("/organizations", orgID, "workspaces", workspaceID, "runs", jobID)}
+	return c.uiURL.ResolveReference(rel).String()
+}
+
 // request builds an authenticated JSON:API HTTP request.
 func (c *Client) request(ctx context.Context, method, reqPath string, body interface{}) (*http.Request, error) {
 	return c.requestWithQuery(ctx, method, reqPath, nil, body)
@@ -347,6 +424,83 @@ func (c *Client) doRaw(_ context.Context, req *http.Request, v interface{}) (*ht
 	return resp, nil
 }
 
+// download retrieves a raw file from the Terrakube server. ref may be an API
+// path or an absolute URL; the bearer token is only sent to the configured
+// endpoint's host.
+func (c *Client) download(ctx context.Context, ref string) ([]byte, error) {
+	return c.transfer(ctx, http.MethodGet, ref, "", nil)
+}
+
+// upload sends a raw file to the Terrakube server with the given method and
+// content type. ref follows the same rules as download.
+func (c *Client) upload(ctx context.Context, method, ref, contentType string, data []byte) error {
+	_, err := c.transfer(ctx, method, ref, contentType, data)
+	return err
+}
+
+// transfer performs a raw, non-JSON:API request and returns the response body.
+func (c *Client) transfer(ctx context.Context, method, ref, contentType string, data []byte) ([]byte, error) {
+	rel, err := url.Parse(ref)
+	if err != nil {
+		return nil, fmt.Errorf("invalid file reference %q: %w", ref, err)
+	}
+	u := c.baseURL.ResolveReference(rel)
+
+	var buf io.Reader
+	if data != nil {
+		buf = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), buf)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() //nolint:errcheck // response body close errors are inconsequential
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response body: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, &APIError{
+			StatusCode: resp.StatusCode,
+			Method:     req.Method,
+			Path:       req.URL.Path,
+			Body:       body,
+		}
+	}
+
+	return body, nil
+}
+
+// Do sends an HTTP request using the client's transport and credentials, for
+// endpoints this package does not wrap, such as the TFE-compatible API. A
+// relative request URL is resolved against the endpoint. The bearer token is
+// only added to requests for the endpoint's host, and the User-Agent header is
+// set unless the request already has one. The caller must close the response body.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	if !req.URL.IsAbs() {
+		req.URL = c.baseURL.ResolveReference(req.URL)
+		req.Host = req.URL.Host
+	}
+	if req.URL.Host == c.baseURL.Host {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	return c.httpClient.Do(req)
+}
+
 // validateID checks that a resource ID is not empty.
 func validateID(field, value string) error {
 	if value == "" {
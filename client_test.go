@@ -183,3 +183,84 @@ func TestClient_UserAgent(t *testing.T) {
 
 	_, _ = c.Organizations.Get(context.Background(), "1")
 }
+
+func TestClient_JobURL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		opts []terrakube.Option
+		want string
+	}{
+		{
+			name: "defaults to endpoint",
+			opts: nil,
+			want: "https://api.example.com/organizations/org-1/workspaces/ws-1/runs/job-1",
+		},
+		{
+			name: "UI endpoint",
+			opts: []terrakube.Option{terrakube.WithUIEndpoint("ui.example.com")},
+			want: "https://ui.example.com/organizations/org-1/workspaces/ws-1/runs/job-1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			opts := append([]terrakube.Option{
+				terrakube.WithEndpoint("https://api.example.com"),
+				terrakube.WithToken("tok"),
+			}, tt.opts...)
+			c, err := terrakube.NewClient(opts...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := c.JobURL("org-1", "ws-1", "job-1"); got != tt.want {
+				t.Errorf("JobURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_Do(t *testing.T) {
+	t.Parallel()
+
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			t.Errorf("token sent to another host: %q", auth)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer other.Close()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "Bearer test-token" {
+			t.Errorf("Authorization = %q, want %q", auth, "Bearer test-token")
+		}
+		if r.URL.Path != "/remote/tfe/v2/ping" {
+			t.Errorf("path = %q, want %q", r.URL.Path, "/remote/tfe/v2/ping")
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	c, err := terrakube.NewClient(
+		terrakube.WithEndpoint(srv.URL),
+		terrakube.WithToken("test-token"),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, ref := range []string{"/remote/tfe/v2/ping", other.URL + "/file"} {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, ref, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		resp, err := c.Do(req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		_ = resp.Body.Close()
+	}
+}
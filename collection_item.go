@@ -55,7 +55,9 @@ func (s *CollectionItemService) Get(ctx context.Context, orgID, collectionID, id
 }
 
 // Create creates a new item in the given collection.
-// It returns a *ValidationError if orgID or collectionID is empty and a *APIError on server errors.
+// It returns a *ValidationError if orgID or collectionID is empty or the value is
+// not valid HCL while Hcl is set, a *SecretError if a secret reference in the
+// value cannot be resolved, and a *APIError on server errors.
 func (s *CollectionItemService) Create(ctx context.Context, orgID, collectionID string, item *CollectionItem) (*CollectionItem, error) {
 	if err := validateID("organizationID", orgID); err != nil {
 		return nil, err
@@ -63,13 +65,24 @@ func (s *CollectionItemService) Create(ctx context.Context, orgID, collectionID
 	if err := validateID("collectionID", collectionID); err != nil {
 		return nil, err
 	}
+	item, err := s.client.resolveCollectionItem(ctx, item)
+	if err != nil {
+		return nil, err
+	}
+	if item.Hcl {
+		if err := validateHCLValue(item.Key, item.Value); err != nil {
+			return nil, err
+		}
+	}
 
 	path := s.client.apiPath("organization", orgID, "collection", collectionID, "item")
 	return s.create(ctx, path, item)
 }
 
 // Update modifies an existing collection item. The item's ID field must be set.
-// It returns a *ValidationError if orgID, collectionID, or the ID is empty and a *APIError on server errors.
+// It returns a *ValidationError if orgID, collectionID, or the ID is empty or the
+// value is not valid HCL while Hcl is set, a *SecretError if a secret reference
+// in the value cannot be resolved, and a *APIError on server errors.
 func (s *CollectionItemService) Update(ctx context.Context, orgID, collectionID string, item *CollectionItem) (*CollectionItem, error) {
 	if err := validateID("organizationID", orgID); err != nil {
 		return nil, err
@@ -80,6 +93,15 @@ func (s *CollectionItemService) Update(ctx context.Context, orgID, collectionID
 	if err := validateID("itemID", item.ID); err != nil {
 		return nil, err
 	}
+	item, err := s.client.resolveCollectionItem(ctx, item)
+	if err != nil {
+		return nil, err
+	}
+	if item.Hcl {
+		if err := validateHCLValue(item.Key, item.Value); err != nil {
+			return nil, err
+		}
+	}
 
 	path := s.client.apiPath("organization", orgID, "collection", collectionID, "item", item.ID)
 	return s.update(ctx, path, item)
@@ -609,14 +609,19 @@ func TestNewClient_FilterKeys(t *testing.T) {
 	skipFields := map[string]bool{
 		"TeamTokens": true,
 		"Operations": true,
+		"Registry":   true,
 	}
 
 	// Private fields on Client that aren't services.
 	privateFields := map[string]bool{
-		"baseURL":    true,
-		"token":      true,
-		"httpClient": true,
-		"userAgent":  true,
+		"baseURL":           true,
+		"uiURL":             true,
+		"stateCache":        true,
+		"token":             true,
+		"httpClient":        true,
+		"userAgent":         true,
+		"validateTemplates": true,
+		"secretResolver":    true,
 	}
 
 	client, err := NewClient(WithEndpoint("https://example.com"), WithToken("test"))
@@ -19,8 +19,11 @@
 //	}
 //
 // Additional options include [WithHTTPClient] to supply a custom http.Client,
-// [WithInsecureTLS] to skip certificate verification, and [WithUserAgent] to
-// set a custom User-Agent header.
+// [WithInsecureTLS] to skip certificate verification, [WithUserAgent] to
+// set a custom User-Agent header, [WithUIEndpoint] to point job links at
+// the Terrakube UI, [WithStateCache] to reuse downloaded workspace states,
+// [WithTemplateValidation] to check templates before they are saved, and
+// [WithSecretResolver] to expand secret references in variable values.
 //
 // # Resource Hierarchy
 //
@@ -96,7 +99,9 @@
 // Server errors are returned as [APIError], which includes the HTTP status
 // code, request method and path, the raw response body, and any structured
 // error details. Client-side validation failures (such as empty IDs) are
-// returned as [ValidationError].
+// returned as [ValidationError]. Job actions refused because of the job's
+// current status, such as [JobService.Approve] on a running job, are returned
+// as [JobStatusError].
 //
 // Use the helper functions [IsNotFound], [IsConflict], and [IsUnauthorized]
 // to check for common HTTP error conditions:
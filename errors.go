@@ -40,6 +40,50 @@ func (e *ValidationError) Error() string {
 	return fmt.Sprintf("validation error: %s %s", e.Field, e.Message)
 }
 
+// Sentinel errors wrapped by *JobStatusError.
+var (
+	// ErrJobNotAwaitingApproval indicates a job is not in the waitingApproval status.
+	ErrJobNotAwaitingApproval = errors.New("job is not awaiting approval")
+	// ErrJobTerminal indicates a job has already reached a terminal status.
+	ErrJobTerminal = errors.New("job is already in a terminal status")
+)
+
+// JobStatusError represents a job action refused because of the job's current status.
+// Use errors.Is with ErrJobNotAwaitingApproval or ErrJobTerminal to check the reason.
+type JobStatusError struct {
+	JobID  string
+	Status string
+	Action string
+	Err    error
+}
+
+// Error returns a string representation including the action, job ID, and current status.
+func (e *JobStatusError) Error() string {
+	return fmt.Sprintf("cannot %s job %s: %v (status %q)", e.Action, e.JobID, e.Err, e.Status)
+}
+
+// Unwrap returns the underlying sentinel error.
+func (e *JobStatusError) Unwrap() error {
+	return e.Err
+}
+
+// SecretError represents a secret reference in a variable value that could not be resolved.
+type SecretError struct {
+	Key string
+	Ref string
+	Err error
+}
+
+// Error returns a string representation including the variable key and the reference.
+func (e *SecretError) Error() string {
+	return fmt.Sprintf("resolving secret %s for %s: %v", e.Ref, e.Key, e.Err)
+}
+
+// Unwrap returns the resolver's error.
+func (e *SecretError) Unwrap() error {
+	return e.Err
+}
+
 // IsNotFound returns true if the error is a 404 API error.
 func IsNotFound(err error) bool {
 	var apiErr *APIError
@@ -56,6 +56,31 @@ func TestValidationError_Error(t *testing.T) {
 	}
 }
 
+func TestJobStatusError_Error(t *testing.T) {
+	t.Parallel()
+	err := &terrakube.JobStatusError{JobID: "job-1", Status: "running", Action: "approve", Err: terrakube.ErrJobNotAwaitingApproval}
+	want := `cannot approve job job-1: job is not awaiting approval (status "running")`
+	if got := err.Error(); got != want {
+		t.Errorf("JobStatusError.Error() = %q, want %q", got, want)
+	}
+	if !errors.Is(err, terrakube.ErrJobNotAwaitingApproval) {
+		t.Error("JobStatusError should unwrap to ErrJobNotAwaitingApproval")
+	}
+}
+
+func TestSecretError_Error(t *testing.T) {
+	t.Parallel()
+	cause := errors.New("environment variable DB_PASSWORD is not set")
+	err := &terrakube.SecretError{Key: "db_password", Ref: "env://DB_PASSWORD", Err: cause}
+	want := "resolving secret env://DB_PASSWORD for db_password: environment variable DB_PASSWORD is not set"
+	if got := err.Error(); got != want {
+		t.Errorf("SecretError.Error() = %q, want %q", got, want)
+	}
+	if !errors.Is(err, cause) {
+		t.Error("SecretError should unwrap to the resolver error")
+	}
+}
+
 func TestIsNotFound(t *testing.T) {
 	t.Parallel()
 
@@ -2,4 +2,15 @@ module github.com/terrakube-io/terrakube-go
 
 go 1.24
 
-require github.com/google/jsonapi v1.0.0
+require (
+	filippo.io/age v1.2.1
+	github.com/ProtonMail/go-crypto v1.1.6
+	github.com/google/jsonapi v1.0.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/cloudflare/circl v1.3.7 // indirect
+	golang.org/x/crypto v0.24.0 // indirect
+	golang.org/x/sys v0.21.0 // indirect
+)
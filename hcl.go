@@ -0,0 +1,493 @@
+package terrakube
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// hclIdentifier matches object keys that need no quoting.
+var hclIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// EncodeHCL encodes a Go value as an HCL expression suitable for the Value
+// of a variable with Hcl set. The value is first converted as by
+// json.Marshal, so structs follow their json tags; map keys are sorted.
+func EncodeHCL(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("encoding HCL value: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return "", fmt.Errorf("encoding HCL value: %w", err)
+	}
+
+	var b strings.Builder
+	writeHCL(&b, value, "")
+	return b.String(), nil
+}
+
+func writeHCL(b *strings.Builder, v interface{}, indent string) {
+	switch v := v.(type) {
+	case nil:
+		b.WriteString("null")
+	case bool:
+		b.WriteString(strconv.FormatBool(v))
+	case json.Number:
+		b.WriteString(v.String())
+	case string:
+		b.WriteString(quoteHCL(v))
+	case []interface{}:
+		if len(v) == 0 {
+			b.WriteString("[]")
+			return
+		}
+		inline := true
+		for _, e := range v {
+			switch e.(type) {
+			case []interface{}, map[string]interface{}:
+				inline = false
+			}
+		}
+		if inline {
+			b.WriteByte('[')
+			for i, e := range v {
+				if i > 0 {
+					b.WriteString(", ")
+				}
+				writeHCL(b, e, indent)
+			}
+			b.WriteByte(']')
+			return
+		}
+		b.WriteString("[\n")
+		for _, e := range v {
+			b.WriteString(indent + "  ")
+			writeHCL(b, e, indent+"  ")
+			b.WriteString(",\n")
+		}
+		b.WriteString(indent + "]")
+	case map[string]interface{}:
+		if len(v) == 0 {
+			b.WriteString("{}")
+			return
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		b.WriteString("{\n")
+		for _, k := range keys {
+			b.WriteString(indent + "  ")
+			if hclIdentifier.MatchString(k) {
+				b.WriteString(k)
+			} else {
+				b.WriteString(quoteHCL(k))
+			}
+			b.WriteString(" = ")
+			writeHCL(b, v[k], indent+"  ")
+			b.WriteByte('\n')
+		}
+		b.WriteString(indent + "}")
+	}
+}
+
+// quoteHCL returns s as a quoted HCL string, escaping template sequences.
+func quoteHCL(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&b, `\u%04x`, r)
+		case (r == '$' || r == '%') && strings.HasPrefix(s[i+1:], "{"):
+			// Doubling the marker escapes a template sequence.
+			b.WriteRune(r)
+			b.WriteRune(r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// DecodeHCL decodes an HCL value expression into v, which is filled as by
+// json.Unmarshal. Only literal values are supported: strings, heredocs,
+// numbers, booleans, null, and lists and objects of them, which is what
+// Terraform accepts for variable values.
+func DecodeHCL(s string, v interface{}) error {
+	value, err := parseHCL(s)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("decoding HCL value: %w", err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("decoding HCL value: %w", err)
+	}
+	return nil
+}
+
+// ValidateHCL reports whether s is an HCL value expression that DecodeHCL
+// accepts, returning an error with the line and column of the first problem.
+func ValidateHCL(s string) error {
+	_, err := parseHCL(s)
+	return err
+}
+
+// validateHCLValue checks the value of a variable with Hcl set.
+func validateHCLValue(key, value string) error {
+	if err := ValidateHCL(value); err != nil {
+		return &ValidationError{Field: "value", Message: fmt.Sprintf("of %s is not a valid HCL value: %v", key, err)}
+	}
+	return nil
+}
+
+// parseHCL parses a literal HCL expression into strings, json.Numbers,
+// bools, nil, []interface{}, and map[string]interface{}.
+func parseHCL(s string) (interface{}, error) {
+	p := &hclParser{src: s, line: 1, col: 1}
+	p.skipSpace()
+	if p.eof() {
+		return nil, p.errorf("expected a value")
+	}
+	v, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if !p.eof() {
+		return nil, p.errorf("unexpected %q after the value", p.rest()[0])
+	}
+	return v, nil
+}
+
+// hclParser parses the literal subset of HCL's native expression syntax.
+type hclParser struct {
+	src       string
+	pos       int
+	line, col int
+}
+
+func (p *hclParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("line %d, column %d: %s", p.line, p.col, fmt.Sprintf(format, args...))
+}
+
+func (p *hclParser) eof() bool { return p.pos >= len(p.src) }
+
+func (p *hclParser) rest() string { return p.src[p.pos:] }
+
+// advance moves past n bytes, tracking the line and column.
+func (p *hclParser) advance(n int) {
+	for _, r := range p.src[p.pos : p.pos+n] {
+		if r == '\n' {
+			p.line, p.col = p.line+1, 1
+		} else {
+			p.col++
+		}
+	}
+	p.pos += n
+}
+
+// skipSpace skips whitespace, including newlines, and comments.
+func (p *hclParser) skipSpace() {
+	for !p.eof() {
+		rest := p.rest()
+		switch {
+		case rest[0] == ' ' || rest[0] == '\t' || rest[0] == '\r' || rest[0] == '\n':
+			p.advance(1)
+		case rest[0] == '#' || strings.HasPrefix(rest, "//"):
+			end := strings.IndexByte(rest, '\n')
+			if end < 0 {
+				end = len(rest)
+			}
+			p.advance(end)
+		case strings.HasPrefix(rest, "/*"):
+			end := strings.Index(rest, "*/")
+			if end < 0 {
+				p.advance(len(rest))
+				return
+			}
+			p.advance(end + 2)
+		default:
+			return
+		}
+	}
+}
+
+func (p *hclParser) value() (interface{}, error) {
+	rest := p.rest()
+	switch c := rest[0]; {
+	case c == '"':
+		return p.quoted()
+	case strings.HasPrefix(rest, "<<"):
+		return p.heredoc()
+	case c == '[':
+		return p.tuple()
+	case c == '{':
+		return p.object()
+	case c == '-' || c >= '0' && c <= '9':
+		return p.number()
+	}
+
+	word := p.word()
+	switch word {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	case "":
+		return nil, p.errorf("unexpected %q", rest[0])
+	}
+	return nil, p.errorf("only literal values are allowed, found %q", word)
+}
+
+func (p *hclParser) word() string {
+	end := 0
+	for end < len(p.rest()) {
+		c := p.rest()[end]
+		if c != '_' && c != '-' && !(c >= 'a' && c <= 'z') && !(c >= 'A' && c <= 'Z') && !(end > 0 && c >= '0' && c <= '9') {
+			break
+		}
+		end++
+	}
+	word := p.rest()[:end]
+	p.advance(end)
+	return word
+}
+
+var hclNumber = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?([eE][+-]?[0-9]+)?`)
+
+func (p *hclParser) number() (interface{}, error) {
+	m := hclNumber.FindString(p.rest())
+	if m == "" {
+		return nil, p.errorf("invalid number")
+	}
+	p.advance(len(m))
+	return json.Number(m), nil
+}
+
+// quoted parses a double-quoted string, rejecting template sequences.
+func (p *hclParser) quoted() (interface{}, error) {
+	p.advance(1)
+	var b strings.Builder
+	for !p.eof() {
+		rest := p.rest()
+		switch {
+		case rest[0] == '"':
+			p.advance(1)
+			return b.String(), nil
+		case rest[0] == '\n':
+			return nil, p.errorf("unterminated string")
+		case rest[0] == '\\':
+			if err := p.escape(&b); err != nil {
+				return nil, err
+			}
+			continue
+		case strings.HasPrefix(rest, "$${") || strings.HasPrefix(rest, "%%{"):
+			b.WriteString(rest[1:3])
+			p.advance(3)
+			continue
+		case strings.HasPrefix(rest, "${") || strings.HasPrefix(rest, "%{"):
+			return nil, p.errorf("template sequences are not allowed in variable values")
+		}
+		r, size := utf8.DecodeRuneInString(rest)
+		b.WriteRune(r)
+		p.advance(size)
+	}
+	return nil, p.errorf("unterminated string")
+}
+
+func (p *hclParser) escape(b *strings.Builder) error {
+	rest := p.rest()
+	if len(rest) < 2 {
+		return p.errorf("unterminated string")
+	}
+	switch rest[1] {
+	case 'n':
+		b.WriteByte('\n')
+	case 'r':
+		b.WriteByte('\r')
+	case 't':
+		b.WriteByte('\t')
+	case '"', '\\':
+		b.WriteByte(rest[1])
+	case 'u', 'U':
+		size := 4
+		if rest[1] == 'U' {
+			size = 8
+		}
+		if len(rest) < 2+size {
+			return p.errorf("invalid escape sequence")
+		}
+		n, err := strconv.ParseUint(rest[2:2+size], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(n)) {
+			return p.errorf("invalid escape sequence")
+		}
+		b.WriteRune(rune(n))
+		p.advance(2 + size)
+		return nil
+	default:
+		return p.errorf("invalid escape sequence \\%c", rest[1])
+	}
+	p.advance(2)
+	return nil
+}
+
+// heredoc parses a <<MARKER or <<-MARKER string. The indented form removes
+// the leading whitespace common to all lines.
+func (p *hclParser) heredoc() (interface{}, error) {
+	p.advance(2)
+	indented := false
+	if strings.HasPrefix(p.rest(), "-") {
+		indented = true
+		p.advance(1)
+	}
+	marker := p.word()
+	if marker == "" {
+		return nil, p.errorf("expected a heredoc marker")
+	}
+	if !strings.HasPrefix(p.rest(), "\n") && !strings.HasPrefix(p.rest(), "\r\n") {
+		return nil, p.errorf("expected a newline after <<%s", marker)
+	}
+	p.advance(strings.IndexByte(p.rest(), '\n') + 1)
+
+	var lines []string
+	for !p.eof() {
+		end := strings.IndexByte(p.rest(), '\n')
+		if end < 0 {
+			end = len(p.rest())
+		}
+		line := strings.TrimSuffix(p.rest()[:end], "\r")
+		if strings.TrimSpace(line) == marker {
+			p.advance(len(strings.TrimRight(p.rest()[:end], "\r")))
+			if indented {
+				lines = dedent(lines)
+			}
+			var body string
+			if len(lines) > 0 {
+				body = strings.Join(lines, "\n") + "\n"
+			}
+			unescaped := strings.NewReplacer("$${", "", "%%{", "").Replace(body)
+			if strings.Contains(unescaped, "${") || strings.Contains(unescaped, "%{") {
+				return nil, p.errorf("template sequences are not allowed in variable values")
+			}
+			return strings.NewReplacer("$${", "${", "%%{", "%{").Replace(body), nil
+		}
+		lines = append(lines, line)
+		p.advance(end)
+		if !p.eof() {
+			p.advance(1)
+		}
+	}
+	return nil, p.errorf("heredoc %s is not terminated", marker)
+}
+
+func (p *hclParser) tuple() (interface{}, error) {
+	p.advance(1)
+	list := []interface{}{}
+	for {
+		p.skipSpace()
+		if p.eof() {
+			return nil, p.errorf("unterminated list")
+		}
+		if p.rest()[0] == ']' {
+			p.advance(1)
+			return list, nil
+		}
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, v)
+
+		p.skipSpace()
+		switch {
+		case p.eof():
+			return nil, p.errorf("unterminated list")
+		case p.rest()[0] == ',':
+			p.advance(1)
+		case p.rest()[0] != ']':
+			return nil, p.errorf("expected , or ] in list")
+		}
+	}
+}
+
+func (p *hclParser) object() (interface{}, error) {
+	p.advance(1)
+	obj := map[string]interface{}{}
+	for {
+		p.skipSpace()
+		if p.eof() {
+			return nil, p.errorf("unterminated object")
+		}
+		if p.rest()[0] == '}' {
+			p.advance(1)
+			return obj, nil
+		}
+
+		line, col := p.line, p.col
+		var key string
+		if p.rest()[0] == '"' {
+			k, err := p.quoted()
+			if err != nil {
+				return nil, err
+			}
+			key = k.(string)
+		} else if key = p.word(); key == "" {
+			return nil, p.errorf("expected an object key")
+		}
+		if _, dup := obj[key]; dup {
+			p.line, p.col = line, col
+			return nil, p.errorf("duplicate object key %q", key)
+		}
+
+		p.skipSpace()
+		if p.eof() || (p.rest()[0] != '=' && p.rest()[0] != ':') {
+			return nil, p.errorf("expected = after object key %q", key)
+		}
+		p.advance(1)
+		p.skipSpace()
+		if p.eof() {
+			return nil, p.errorf("expected a value for %q", key)
+		}
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		obj[key] = v
+
+		// Entries are separated by a comma or a newline.
+		valueLine := p.line
+		p.skipSpace()
+		switch {
+		case p.eof() || p.rest()[0] == '}':
+		case p.rest()[0] == ',':
+			p.advance(1)
+		case p.line == valueLine:
+			return nil, p.errorf("expected a newline or , after %q", key)
+		}
+	}
+}
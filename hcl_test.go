@@ -0,0 +1,179 @@
+package terrakube_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	terrakube "github.com/terrakube-io/terrakube-go"
+)
+
+func TestEncodeHCL(t *testing.T) {
+	t.Parallel()
+
+	type subnet struct {
+		CIDR    string `json:"cidr"`
+		Public  bool   `json:"public"`
+		Comment string `json:"comment,omitempty"`
+	}
+
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{"string", "eu-west-1", `"eu-west-1"`},
+		{"escapes", "a \"b\"\n${c} %{d}", `"a \"b\"\n$${c} %%{d}"`},
+		{"number", 3.5, `3.5`},
+		{"bool", true, `true`},
+		{"null", nil, `null`},
+		{"list", []string{"a", "b"}, `["a", "b"]`},
+		{"empty list", []int{}, `[]`},
+		{"map", map[string]interface{}{"b": 1, "a": "x", "with space": false}, "{\n  a = \"x\"\n  b = 1\n  \"with space\" = false\n}"},
+		{"nested", map[string]interface{}{
+			"subnets": []subnet{{CIDR: "10.0.0.0/24", Public: true}},
+			"tags":    map[string]string{},
+		}, "{\n  subnets = [\n    {\n      cidr = \"10.0.0.0/24\"\n      public = true\n    },\n  ]\n  tags = {}\n}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := terrakube.EncodeHCL(tt.value)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("EncodeHCL() =\n%s\nwant\n%s", got, tt.want)
+			}
+			if err := terrakube.ValidateHCL(got); err != nil {
+				t.Errorf("encoded value is not valid: %v", err)
+			}
+		})
+	}
+
+	if _, err := terrakube.EncodeHCL(make(chan int)); err == nil {
+		t.Error("expected error for an unsupported type")
+	}
+}
+
+func TestDecodeHCL(t *testing.T) {
+	t.Parallel()
+
+	src := `{
+  # comment
+  region = "eu-west-1"
+  "zone-count": 3,
+  ratio = -1.5e2
+  enabled = true // trailing
+  nothing = null
+  zones = [
+    "a",
+    "b", /* inline */
+  ]
+  script = <<-EOT
+    echo "$${HOME}"
+  EOT
+}`
+	var got map[string]interface{}
+	if err := terrakube.DecodeHCL(src, &got); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]interface{}{
+		"region":     "eu-west-1",
+		"zone-count": float64(3),
+		"ratio":      float64(-150),
+		"enabled":    true,
+		"nothing":    nil,
+		"zones":      []interface{}{"a", "b"},
+		"script":     "echo \"${HOME}\"\n",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DecodeHCL() = %#v, want %#v", got, want)
+	}
+
+	var list []int
+	if err := terrakube.DecodeHCL("[1, 2, 3]", &list); err != nil || !reflect.DeepEqual(list, []int{1, 2, 3}) {
+		t.Errorf("DecodeHCL(list) = %v, %v", list, err)
+	}
+}
+
+func TestDecodeHCL_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	in := map[string]interface{}{
+		"name":  "quote \" backslash \\ tab \t ${x}",
+		"ports": []interface{}{float64(80), float64(443)},
+		"nested": map[string]interface{}{
+			"list": []interface{}{map[string]interface{}{"a": true}},
+		},
+	}
+	encoded, err := terrakube.EncodeHCL(in)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var out map[string]interface{}
+	if err := terrakube.DecodeHCL(encoded, &out); err != nil {
+		t.Fatalf("decoding %s: %v", encoded, err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("round trip = %#v, want %#v", out, in)
+	}
+}
+
+func TestValidateHCL_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"", "line 1, column 1: expected a value"},
+		{`"unterminated`, "unterminated string"},
+		{`["a" "b"]`, "line 1, column 6: expected , or ]"},
+		{"{\n  a = 1\n  a = 2\n}", "line 3, column 3: duplicate object key"},
+		{"{ a = 1 b = 2 }", "expected a newline or ,"},
+		{"var.region", "only literal values are allowed"},
+		{`"${var.region}"`, "template sequences are not allowed"},
+		{`"bad \q escape"`, "invalid escape sequence"},
+		{"[1, 2", "unterminated list"},
+		{"true false", "unexpected"},
+		{"<<EOT\nbody\n", "heredoc EOT is not terminated"},
+	}
+	for _, tt := range tests {
+		err := terrakube.ValidateHCL(tt.src)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ValidateHCL(%q) = %v, want an error containing %q", tt.src, err, tt.want)
+		}
+	}
+}
+
+func TestHCLValueValidation(t *testing.T) {
+	t.Parallel()
+
+	c := newTestClientFromURL(t, "https://example.com")
+	ctx := context.Background()
+	bad := "{ region = var.region }"
+
+	var errs []error
+	_, err := c.Variables.Create(ctx, "org-1", "ws-1", &terrakube.Variable{Key: "cfg", Value: bad, Hcl: true})
+	errs = append(errs, err)
+	_, err = c.Variables.Update(ctx, "org-1", "ws-1", &terrakube.Variable{ID: "var-1", Key: "cfg", Value: bad, Hcl: true})
+	errs = append(errs, err)
+	_, err = c.OrganizationVariables.Create(ctx, "org-1", &terrakube.OrganizationVariable{Key: "cfg", Value: bad, Hcl: true})
+	errs = append(errs, err)
+	_, err = c.OrganizationVariables.Update(ctx, "org-1", &terrakube.OrganizationVariable{ID: "gv-1", Key: "cfg", Value: bad, Hcl: true})
+	errs = append(errs, err)
+	_, err = c.CollectionItems.Create(ctx, "org-1", "col-1", &terrakube.CollectionItem{Key: "cfg", Value: bad, Hcl: true})
+	errs = append(errs, err)
+	_, err = c.CollectionItems.Update(ctx, "org-1", "col-1", &terrakube.CollectionItem{ID: "item-1", Key: "cfg", Value: bad, Hcl: true})
+	errs = append(errs, err)
+
+	for i, err := range errs {
+		var verr *terrakube.ValidationError
+		if !errors.As(err, &verr) || verr.Field != "value" || !strings.Contains(verr.Message, "cfg") {
+			t.Errorf("call %d: expected a value *ValidationError for cfg, got %v", i, err)
+		}
+	}
+}
@@ -3,6 +3,8 @@ package terrakube_test
 import (
 	"encoding/json"
 	"errors"
+	"io"
+	"net/http"
 	"strings"
 	"testing"
 
@@ -77,3 +79,21 @@ func assertJSONBoolField(t *testing.T, body []byte, attrName string, expected bo
 		t.Errorf("attribute %q = %v, want %v", attrName, boolVal, expected)
 	}
 }
+
+// decodeAttributes reads a JSON:API request body and returns its data attributes.
+func decodeAttributes(t *testing.T, r *http.Request) map[string]interface{} {
+	t.Helper()
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		t.Fatalf("failed to read body: %v", err)
+	}
+	var payload struct {
+		Data struct {
+			Attributes map[string]interface{} `json:"attributes"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("failed to unmarshal body: %v", err)
+	}
+	return payload.Data.Attributes
+}
@@ -1,23 +1,42 @@
 package terrakube
 
-import "context"
+import (
+	"context"
+	"net/http"
+)
+
+// Job status values reported by the Terrakube API.
+const (
+	JobStatusPending         = "pending"
+	JobStatusWaitingApproval = "waitingApproval"
+	JobStatusApproved        = "approved"
+	JobStatusQueued          = "queued"
+	JobStatusRunning         = "running"
+	JobStatusCompleted       = "completed"
+	JobStatusNoChanges       = "noChanges"
+	JobStatusNotExecuted     = "notExecuted"
+	JobStatusRejected        = "rejected"
+	JobStatusCancelled       = "cancelled"
+	JobStatusFailed          = "failed"
+	JobStatusUnknown         = "unknown"
+)
 
 // Job represents a Terrakube job resource.
 type Job struct {
-	ID                string     `jsonapi:"primary,job"`
-	Command           string     `jsonapi:"attr,command"`
-	Output            string     `jsonapi:"attr,output"`
-	Status            string     `jsonapi:"attr,status"`
-	Workspace         *Workspace `jsonapi:"relation,workspace,omitempty"`
-	ApprovalTeam      *string `jsonapi:"attr,approvalTeam"`
-	Comments          *string `jsonapi:"attr,comments"`
-	CommitID          *string `jsonapi:"attr,commitId"`
-	OverrideBranch    *string `jsonapi:"attr,overrideBranch"`
-	PlanChanges       bool    `jsonapi:"attr,planChanges"`
-	Refresh           bool    `jsonapi:"attr,refresh"`
-	RefreshOnly       bool    `jsonapi:"attr,refreshOnly"`
+	ID             string     `jsonapi:"primary,job"`
+	Command        string     `jsonapi:"attr,command"`
+	Output         string     `jsonapi:"attr,output"`
+	Status         string     `jsonapi:"attr,status"`
+	Workspace      *Workspace `jsonapi:"relation,workspace,omitempty"`
+	ApprovalTeam   *string    `jsonapi:"attr,approvalTeam"`
+	Comments       *string    `jsonapi:"attr,comments"`
+	CommitID       *string    `jsonapi:"attr,commitId"`
+	OverrideBranch *string    `jsonapi:"attr,overrideBranch"`
+	PlanChanges    bool       `jsonapi:"attr,planChanges"`
+	Refresh        bool       `jsonapi:"attr,refresh"`
+	RefreshOnly    bool       `jsonapi:"attr,refreshOnly"`
 	// Tcl is the Terrakube Configuration Language content for this job.
-	Tcl *string `jsonapi:"attr,tcl"`
+	Tcl               *string `jsonapi:"attr,tcl"`
 	TemplateReference *string `jsonapi:"attr,templateReference"`
 	TerraformPlan     *string `jsonapi:"attr,terraformPlan"`
 	Via               *string `jsonapi:"attr,via"`
@@ -27,6 +46,22 @@ type Job struct {
 	UpdatedDate       *string `jsonapi:"attr,updatedDate"`
 }
 
+// IsTerminal reports whether the job has reached a final status and will not
+// change any further.
+func (j *Job) IsTerminal() bool {
+	return isTerminalStatus(j.Status)
+}
+
+// isTerminalStatus reports whether a job or step status is final.
+func isTerminalStatus(status string) bool {
+	switch status {
+	case JobStatusCompleted, JobStatusNoChanges, JobStatusNotExecuted,
+		JobStatusRejected, JobStatusCancelled, JobStatusFailed:
+		return true
+	}
+	return false
+}
+
 // JobService handles communication with the job related methods of the
 // Terrakube API.
 type JobService struct {
@@ -96,3 +131,99 @@ func (s *JobService) Delete(ctx context.Context, orgID, id string) error {
 	path := s.client.apiPath("organization", orgID, "job", id)
 	return s.del(ctx, path)
 }
+
+// Approve approves a job that is waiting for approval, allowing it to continue.
+// It returns a *ValidationError if orgID or id is empty, a *JobStatusError if the
+// job is not waiting for approval, and a *APIError on server errors.
+func (s *JobService) Approve(ctx context.Context, orgID, id string) (*Job, error) {
+	job, err := s.awaitingApproval(ctx, orgID, id, "approve")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.transition(ctx, orgID, job, JobStatusApproved, "")
+}
+
+// Reject rejects a job that is waiting for approval. The comment, if not empty,
+// is stored on the job's Comments attribute.
+// It returns a *ValidationError if orgID or id is empty, a *JobStatusError if the
+// job is not waiting for approval, and a *APIError on server errors.
+func (s *JobService) Reject(ctx context.Context, orgID, id, comment string) (*Job, error) {
+	job, err := s.awaitingApproval(ctx, orgID, id, "reject")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.transition(ctx, orgID, job, JobStatusRejected, comment)
+}
+
+// Cancel cancels a job that has not yet reached a terminal status.
+// It returns a *ValidationError if orgID or id is empty, a *JobStatusError if the
+// job is already terminal, and a *APIError on server errors.
+func (s *JobService) Cancel(ctx context.Context, orgID, id string) (*Job, error) {
+	job, err := s.Get(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.IsTerminal() {
+		return nil, &JobStatusError{JobID: job.ID, Status: job.Status, Action: "cancel", Err: ErrJobTerminal}
+	}
+
+	return s.transition(ctx, orgID, job, JobStatusCancelled, "")
+}
+
+// jobTransition is the partial job sent to change a job's status, so that
+// attributes changed on the server since the job was fetched, and those the
+// server manages, are left alone.
+type jobTransition struct {
+	ID       string  `jsonapi:"primary,job"`
+	Status   string  `jsonapi:"attr,status"`
+	Comments *string `jsonapi:"attr,comments,omitempty"`
+}
+
+// transition patches only the status, and the comment if one is given, of a
+// fetched job. It returns the updated job, or the fetched job with the new
+// status when the server responds without a body.
+func (s *JobService) transition(ctx context.Context, orgID string, job *Job, status, comment string) (*Job, error) {
+	patch := &jobTransition{ID: job.ID, Status: status}
+	if comment != "" {
+		patch.Comments = &comment
+	}
+
+	path := s.client.apiPath("organization", orgID, "job", job.ID)
+	req, err := s.client.request(ctx, http.MethodPatch, path, patch)
+	if err != nil {
+		return nil, err
+	}
+	updated := new(Job)
+	if _, err := s.client.do(ctx, req, updated); err != nil {
+		return nil, err
+	}
+
+	if updated.ID == "" {
+		updated = job
+		updated.Status = status
+		if patch.Comments != nil {
+			updated.Comments = patch.Comments
+		}
+	}
+	return updated, nil
+}
+
+// awaitingApproval fetches a job and checks that it is waiting for approval
+// before the given action is applied.
+func (s *JobService) awaitingApproval(ctx context.Context, orgID, id, action string) (*Job, error) {
+	job, err := s.Get(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case job.Status == JobStatusWaitingApproval:
+		return job, nil
+	case job.IsTerminal():
+		return nil, &JobStatusError{JobID: job.ID, Status: job.Status, Action: action, Err: ErrJobTerminal}
+	default:
+		return nil, &JobStatusError{JobID: job.ID, Status: job.Status, Action: action, Err: ErrJobNotAwaitingApproval}
+	}
+}
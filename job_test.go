@@ -2,7 +2,10 @@ package terrakube_test
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
+	"reflect"
 	"testing"
 
 	terrakube "github.com/terrakube-io/terrakube-go"
@@ -288,3 +291,189 @@ func TestJobService_AuthHeader(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
+
+// patchAttributes decodes the attributes of a PATCH body. It runs on the
+// server goroutine, so failures are reported with t.Errorf and a 400.
+func patchAttributes(t *testing.T, w http.ResponseWriter, r *http.Request) (map[string]interface{}, bool) {
+	t.Helper()
+	var payload struct {
+		Data struct {
+			Attributes map[string]interface{} `json:"attributes"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		t.Errorf("failed to decode body: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	return payload.Data.Attributes, true
+}
+
+func TestJobService_Approve(t *testing.T) {
+	t.Parallel()
+
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("GET /api/v1/organization/org-1/job/job-1", func(w http.ResponseWriter, _ *http.Request) {
+		job := newTestJob()
+		job.Status = terrakube.JobStatusWaitingApproval
+		testutil.WriteJSONAPI(t, w, http.StatusOK, job)
+	})
+	srv.HandleFunc("PATCH /api/v1/organization/org-1/job/job-1", func(w http.ResponseWriter, r *http.Request) {
+		attrs, ok := patchAttributes(t, w, r)
+		if !ok {
+			return
+		}
+		if want := map[string]interface{}{"status": "approved"}; !reflect.DeepEqual(attrs, want) {
+			t.Errorf("attributes = %v, want only %v", attrs, want)
+		}
+		job := newTestJob()
+		job.Status = terrakube.JobStatusApproved
+		testutil.WriteJSONAPI(t, w, http.StatusOK, job)
+	})
+
+	client := newTestClient(t, srv)
+
+	job, err := client.Jobs.Approve(context.Background(), "org-1", "job-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if job.Status != terrakube.JobStatusApproved {
+		t.Errorf("Status = %q, want %q", job.Status, terrakube.JobStatusApproved)
+	}
+}
+
+func TestJobService_Reject(t *testing.T) {
+	t.Parallel()
+
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("GET /api/v1/organization/org-1/job/job-1", func(w http.ResponseWriter, _ *http.Request) {
+		job := newTestJob()
+		job.Status = terrakube.JobStatusWaitingApproval
+		testutil.WriteJSONAPI(t, w, http.StatusOK, job)
+	})
+	srv.HandleFunc("PATCH /api/v1/organization/org-1/job/job-1", func(w http.ResponseWriter, r *http.Request) {
+		attrs, ok := patchAttributes(t, w, r)
+		if !ok {
+			return
+		}
+		if want := map[string]interface{}{"status": "rejected", "comments": "not today"}; !reflect.DeepEqual(attrs, want) {
+			t.Errorf("attributes = %v, want only %v", attrs, want)
+		}
+		job := newTestJob()
+		job.Status = terrakube.JobStatusRejected
+		testutil.WriteJSONAPI(t, w, http.StatusOK, job)
+	})
+
+	client := newTestClient(t, srv)
+
+	job, err := client.Jobs.Reject(context.Background(), "org-1", "job-1", "not today")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if job.Status != terrakube.JobStatusRejected {
+		t.Errorf("Status = %q, want %q", job.Status, terrakube.JobStatusRejected)
+	}
+}
+
+func TestJobService_Approve_WrongStatus(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		status string
+		want   error
+	}{
+		{"running", terrakube.JobStatusRunning, terrakube.ErrJobNotAwaitingApproval},
+		{"pending", terrakube.JobStatusPending, terrakube.ErrJobNotAwaitingApproval},
+		{"completed", terrakube.JobStatusCompleted, terrakube.ErrJobTerminal},
+		{"rejected", terrakube.JobStatusRejected, terrakube.ErrJobTerminal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := testutil.NewServer(t)
+			srv.HandleFunc("GET /api/v1/organization/org-1/job/job-1", func(w http.ResponseWriter, _ *http.Request) {
+				job := newTestJob()
+				job.Status = tt.status
+				testutil.WriteJSONAPI(t, w, http.StatusOK, job)
+			})
+			srv.HandleFunc("PATCH /api/v1/organization/org-1/job/job-1", func(_ http.ResponseWriter, _ *http.Request) {
+				t.Error("unexpected PATCH for job not awaiting approval")
+			})
+
+			client := newTestClient(t, srv)
+
+			_, err := client.Jobs.Approve(context.Background(), "org-1", "job-1")
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("error = %v, want %v", err, tt.want)
+			}
+			var statusErr *terrakube.JobStatusError
+			if !errors.As(err, &statusErr) {
+				t.Fatalf("expected *JobStatusError, got %T", err)
+			}
+			if statusErr.Status != tt.status {
+				t.Errorf("JobStatusError.Status = %q, want %q", statusErr.Status, tt.status)
+			}
+		})
+	}
+}
+
+func TestJobService_Cancel(t *testing.T) {
+	t.Parallel()
+
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("GET /api/v1/organization/org-1/job/job-1", func(w http.ResponseWriter, _ *http.Request) {
+		job := newTestJob()
+		job.Status = terrakube.JobStatusRunning
+		testutil.WriteJSONAPI(t, w, http.StatusOK, job)
+	})
+	srv.HandleFunc("PATCH /api/v1/organization/org-1/job/job-1", func(w http.ResponseWriter, r *http.Request) {
+		attrs, ok := patchAttributes(t, w, r)
+		if !ok {
+			return
+		}
+		if want := map[string]interface{}{"status": "cancelled"}; !reflect.DeepEqual(attrs, want) {
+			t.Errorf("attributes = %v, want only %v", attrs, want)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	client := newTestClient(t, srv)
+
+	job, err := client.Jobs.Cancel(context.Background(), "org-1", "job-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if job.Status != terrakube.JobStatusCancelled {
+		t.Errorf("Status = %q, want %q", job.Status, terrakube.JobStatusCancelled)
+	}
+}
+
+func TestJobService_Cancel_Terminal(t *testing.T) {
+	t.Parallel()
+
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("GET /api/v1/organization/org-1/job/job-1", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSONAPI(t, w, http.StatusOK, newTestJob())
+	})
+
+	client := newTestClient(t, srv)
+
+	_, err := client.Jobs.Cancel(context.Background(), "org-1", "job-1")
+	if !errors.Is(err, terrakube.ErrJobTerminal) {
+		t.Fatalf("error = %v, want %v", err, terrakube.ErrJobTerminal)
+	}
+}
+
+func TestJobService_Cancel_EmptyIDs(t *testing.T) {
+	t.Parallel()
+
+	client := newTestClientFromURL(t, "https://example.com")
+
+	_, err := client.Jobs.Cancel(context.Background(), "", "job-1")
+	assertValidationError(t, err, "organizationID")
+	_, err = client.Jobs.Approve(context.Background(), "org-1", "")
+	assertValidationError(t, err, "jobID")
+}
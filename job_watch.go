@@ -0,0 +1,308 @@
+package terrakube
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// JobEventType identifies a job lifecycle event emitted by JobService.Watch.
+type JobEventType string
+
+// Supported job event types.
+const (
+	JobEventCreated       JobEventType = "created"
+	JobEventStatusChanged JobEventType = "statusChanged"
+	JobEventStepStarted   JobEventType = "stepStarted"
+	JobEventStepFinished  JobEventType = "stepFinished"
+	JobEventCompleted     JobEventType = "completed"
+	// JobEventError is emitted once, with Err set, when the watcher stops on a
+	// non-transient error. The channel is closed right after it.
+	JobEventError JobEventType = "error"
+)
+
+const (
+	defaultWatchInterval   = 10 * time.Second
+	defaultWatchMaxBackoff = 5 * time.Minute
+)
+
+// JobEvent is a single job lifecycle event.
+type JobEvent struct {
+	Type JobEventType
+	Job  *Job
+	// Step is set for JobEventStepStarted and JobEventStepFinished.
+	Step *Step
+	// PreviousStatus is the job or step status before the change, empty when
+	// the watcher had not seen it before.
+	PreviousStatus string
+	Err            error
+}
+
+// WatchOptions configures JobService.Watch.
+type WatchOptions struct {
+	// Filter is an additional RSQL job filter combined with the updatedDate
+	// filter, for example "workspace.id==abc".
+	Filter string
+	// Since resumes watching from a previously seen updatedDate value, as
+	// reported on JobEvent.Job.UpdatedDate. It must be an RFC 3339 timestamp
+	// and defaults to the current time.
+	Since string
+	// Interval is the delay between polls. Defaults to 10 seconds.
+	Interval time.Duration
+	// MaxBackoff caps the delay between retries after transient errors.
+	// Defaults to 5 minutes.
+	MaxBackoff time.Duration
+	// Steps enables step started and finished events. Steps are only listed
+	// for jobs that changed since the previous poll.
+	Steps bool
+	// OnError, if set, is called with each transient error before retrying.
+	OnError func(error)
+}
+
+// jobWatchState is the last status the watcher saw for a job.
+type jobWatchState struct {
+	status  string
+	updated time.Time
+	steps   map[string]string
+}
+
+// watermark is the latest updatedDate the watcher has seen. raw is the
+// value as the server wrote it, which is sent back in the filter.
+type watermark struct {
+	raw string
+	at  time.Time
+}
+
+// jobTimeLayouts are the timestamp formats accepted from the server, which
+// may write fractional seconds and offsets with or without a colon.
+var jobTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999Z0700",
+}
+
+// parseJobTime parses a job timestamp such as createdDate or updatedDate.
+func parseJobTime(s string) (time.Time, error) {
+	var err error
+	for _, layout := range jobTimeLayouts {
+		var t time.Time
+		if t, err = time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// jobTime returns the parsed timestamp, or the zero time if it is missing
+// or not understood.
+func jobTime(s *string) time.Time {
+	if s == nil {
+		return time.Time{}
+	}
+	t, err := parseJobTime(*s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// Watch polls the organization's jobs and emits lifecycle events on the
+// returned channel until ctx is cancelled. Each poll only lists jobs updated
+// since the last seen updatedDate, and repeated sightings of an unchanged job
+// are dropped. Transient errors are retried with exponential backoff; any
+// other error is delivered as a JobEventError before the channel is closed.
+// It returns a *ValidationError if orgID is empty or opts.Since is not a
+// timestamp.
+func (s *JobService) Watch(ctx context.Context, orgID string, opts *WatchOptions) (<-chan JobEvent, error) {
+	if err := validateID("organizationID", orgID); err != nil {
+		return nil, err
+	}
+
+	o := WatchOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Interval <= 0 {
+		o.Interval = defaultWatchInterval
+	}
+	if o.MaxBackoff <= 0 {
+		o.MaxBackoff = defaultWatchMaxBackoff
+	}
+	since := watermark{raw: o.Since}
+	if since.raw == "" {
+		since.raw = time.Now().UTC().Format(time.RFC3339)
+	}
+	at, err := parseJobTime(since.raw)
+	if err != nil {
+		return nil, &ValidationError{Field: "since", Message: fmt.Sprintf("%q is not an RFC 3339 timestamp", since.raw)}
+	}
+	since.at = at
+
+	events := make(chan JobEvent)
+	go s.watch(ctx, orgID, o, since, events)
+	return events, nil
+}
+
+// watch runs the polling loop for Watch.
+func (s *JobService) watch(ctx context.Context, orgID string, o WatchOptions, since watermark, events chan<- JobEvent) {
+	defer close(events)
+
+	seen := map[string]*jobWatchState{}
+	delay := time.Duration(0)
+	backoff := o.Interval
+
+	for {
+		if delay > 0 {
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+
+		next, err := s.poll(ctx, orgID, o, since, seen, events)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			if !isTransient(err) {
+				select {
+				case events <- JobEvent{Type: JobEventError, Err: err}:
+				case <-ctx.Done():
+				}
+				return
+			}
+			if o.OnError != nil {
+				o.OnError(err)
+			}
+			delay = backoff
+			backoff = min(backoff*2, o.MaxBackoff)
+			continue
+		}
+
+		since = next
+		delay = o.Interval
+		backoff = o.Interval
+	}
+}
+
+// poll lists jobs updated since the watermark, emits events for changes, and
+// returns the new watermark.
+func (s *JobService) poll(ctx context.Context, orgID string, o WatchOptions, since watermark, seen map[string]*jobWatchState, events chan<- JobEvent) (watermark, error) {
+	filter := "updatedDate=ge=" + since.raw
+	if o.Filter != "" {
+		filter = o.Filter + ";" + filter
+	}
+
+	jobs, err := s.List(ctx, orgID, &ListOptions{Filter: filter})
+	if err != nil {
+		return since, err
+	}
+
+	next := since
+	for _, job := range jobs {
+		updated := jobTime(job.UpdatedDate)
+		if updated.After(next.at) {
+			next = watermark{raw: *job.UpdatedDate, at: updated}
+		}
+
+		state, known := seen[job.ID]
+		if known && state.status == job.Status && state.updated.Equal(updated) {
+			continue
+		}
+		if !known {
+			state = &jobWatchState{steps: map[string]string{}}
+		}
+
+		var batch []JobEvent
+		switch {
+		case !known && job.CreatedDate != nil && !jobTime(job.CreatedDate).Before(since.at):
+			batch = append(batch, JobEvent{Type: JobEventCreated, Job: job})
+		case state.status != job.Status:
+			batch = append(batch, JobEvent{Type: JobEventStatusChanged, Job: job, PreviousStatus: state.status})
+		}
+
+		steps := state.steps
+		if o.Steps {
+			var stepEvents []JobEvent
+			stepEvents, steps, err = s.stepEvents(ctx, orgID, job, state.steps)
+			if err != nil {
+				return since, err
+			}
+			batch = append(batch, stepEvents...)
+		}
+
+		if job.IsTerminal() && state.status != job.Status {
+			batch = append(batch, JobEvent{Type: JobEventCompleted, Job: job, PreviousStatus: state.status})
+		}
+
+		for _, ev := range batch {
+			select {
+			case events <- ev:
+			case <-ctx.Done():
+				return since, ctx.Err()
+			}
+		}
+		// The job is only recorded once its whole batch is sent, so a failed
+		// step listing is retried from the previous state.
+		state.status = job.Status
+		state.updated = updated
+		state.steps = steps
+		seen[job.ID] = state
+	}
+
+	// Forget finished jobs that can no longer appear in a poll result.
+	for id, state := range seen {
+		if state.updated.Before(next.at) && isTerminalStatus(state.status) {
+			delete(seen, id)
+		}
+	}
+
+	return next, nil
+}
+
+// stepEvents lists a job's steps and returns started and finished events for
+// steps whose status changed since the job was last seen, along with the new
+// step statuses. The seen map is not modified.
+func (s *JobService) stepEvents(ctx context.Context, orgID string, job *Job, seen map[string]string) ([]JobEvent, map[string]string, error) {
+	steps, err := s.client.Steps.List(ctx, orgID, job.ID, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	statuses := make(map[string]string, len(steps))
+	for id, status := range seen {
+		statuses[id] = status
+	}
+	var batch []JobEvent
+	for _, step := range steps {
+		prev := seen[step.ID]
+		if prev == step.Status {
+			continue
+		}
+		statuses[step.ID] = step.Status
+
+		switch {
+		case step.Status == JobStatusRunning:
+			batch = append(batch, JobEvent{Type: JobEventStepStarted, Job: job, Step: step, PreviousStatus: prev})
+		case isTerminalStatus(step.Status):
+			batch = append(batch, JobEvent{Type: JobEventStepFinished, Job: job, Step: step, PreviousStatus: prev})
+		}
+	}
+	return batch, statuses, nil
+}
+
+// isTransient reports whether a watch error is worth retrying.
+func isTransient(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= 500 ||
+			apiErr.StatusCode == http.StatusTooManyRequests ||
+			apiErr.StatusCode == http.StatusRequestTimeout
+	}
+	var ve *ValidationError
+	return !errors.As(err, &ve)
+}
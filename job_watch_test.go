@@ -0,0 +1,362 @@
+package terrakube_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	terrakube "github.com/terrakube-io/terrakube-go"
+	"github.com/terrakube-io/terrakube-go/testutil"
+)
+
+func strPtr(s string) *string { return &s }
+
+// collectEvents reads n events from ch, failing the test on timeout.
+func collectEvents(t *testing.T, ch <-chan terrakube.JobEvent, n int) []terrakube.JobEvent {
+	t.Helper()
+	var got []terrakube.JobEvent
+	timeout := time.After(5 * time.Second)
+	for len(got) < n {
+		select {
+		case ev, ok := <-ch:
+			if !ok {
+				t.Fatalf("channel closed after %d events, want %d", len(got), n)
+			}
+			got = append(got, ev)
+		case <-timeout:
+			t.Fatalf("timed out after %d events, want %d", len(got), n)
+		}
+	}
+	return got
+}
+
+func TestJobService_Watch(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var filters []string
+	var polls atomic.Int32
+
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("GET /api/v1/organization/org-1/job", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		filters = append(filters, r.URL.Query().Get("filter[job]"))
+		mu.Unlock()
+
+		job := &terrakube.Job{
+			ID:          "job-1",
+			Status:      terrakube.JobStatusRunning,
+			CreatedDate: strPtr("2024-01-01T00:00:05Z"),
+			UpdatedDate: strPtr("2024-01-01T00:00:05Z"),
+		}
+		switch n := polls.Add(1); {
+		case n == 2:
+			// Unchanged job returned again: must be deduplicated.
+		case n >= 3:
+			job.Status = terrakube.JobStatusCompleted
+			job.UpdatedDate = strPtr("2024-01-01T00:01:00Z")
+		}
+		testutil.WriteJSONAPIList(t, w, http.StatusOK, []*terrakube.Job{job})
+	})
+
+	client := newTestClient(t, srv)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := client.Jobs.Watch(ctx, "org-1", &terrakube.WatchOptions{
+		Filter:   "workspace.id==ws-1",
+		Since:    "2024-01-01T00:00:00Z",
+		Interval: 10 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := collectEvents(t, events, 3)
+	want := []struct {
+		typ      terrakube.JobEventType
+		status   string
+		previous string
+	}{
+		{terrakube.JobEventCreated, terrakube.JobStatusRunning, ""},
+		{terrakube.JobEventStatusChanged, terrakube.JobStatusCompleted, terrakube.JobStatusRunning},
+		{terrakube.JobEventCompleted, terrakube.JobStatusCompleted, terrakube.JobStatusRunning},
+	}
+	for i, w := range want {
+		if got[i].Type != w.typ || got[i].Job.Status != w.status || got[i].PreviousStatus != w.previous {
+			t.Errorf("event %d = {%s %s %q}, want {%s %s %q}", i, got[i].Type, got[i].Job.Status, got[i].PreviousStatus, w.typ, w.status, w.previous)
+		}
+	}
+
+	// Let one more poll run so the advanced watermark is observable.
+	deadline := time.Now().Add(5 * time.Second)
+	for polls.Load() < 4 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	for range events {
+		// Drain until the watcher closes the channel.
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(filters) < 4 {
+		t.Fatalf("got %d polls, want at least 4", len(filters))
+	}
+	if filters[0] != "workspace.id==ws-1;updatedDate=ge=2024-01-01T00:00:00Z" {
+		t.Errorf("first filter = %q", filters[0])
+	}
+	if filters[3] != "workspace.id==ws-1;updatedDate=ge=2024-01-01T00:01:00Z" {
+		t.Errorf("fourth filter = %q, want watermark advanced to latest updatedDate", filters[3])
+	}
+}
+
+func TestJobService_Watch_Steps(t *testing.T) {
+	t.Parallel()
+
+	var polls atomic.Int32
+
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("GET /api/v1/organization/org-1/job", func(w http.ResponseWriter, _ *http.Request) {
+		job := &terrakube.Job{ID: "job-1", Status: terrakube.JobStatusRunning, UpdatedDate: strPtr("2024-01-01T00:00:01Z")}
+		if polls.Add(1) >= 2 {
+			job.UpdatedDate = strPtr("2024-01-01T00:00:02Z")
+		}
+		testutil.WriteJSONAPIList(t, w, http.StatusOK, []*terrakube.Job{job})
+	})
+	srv.HandleFunc("GET /api/v1/organization/org-1/job/job-1/step", func(w http.ResponseWriter, _ *http.Request) {
+		status := terrakube.JobStatusRunning
+		if polls.Load() >= 2 {
+			status = terrakube.JobStatusCompleted
+		}
+		testutil.WriteJSONAPIList(t, w, http.StatusOK, []*terrakube.Step{{ID: "step-1", Name: "Plan", Status: status, StepNumber: 100}})
+	})
+
+	client := newTestClient(t, srv)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := client.Jobs.Watch(ctx, "org-1", &terrakube.WatchOptions{
+		Since:    "2024-01-01T00:00:00Z",
+		Interval: 10 * time.Millisecond,
+		Steps:    true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := collectEvents(t, events, 3)
+	if got[0].Type != terrakube.JobEventStatusChanged {
+		t.Errorf("event 0 = %s, want %s", got[0].Type, terrakube.JobEventStatusChanged)
+	}
+	if got[1].Type != terrakube.JobEventStepStarted || got[1].Step.ID != "step-1" {
+		t.Errorf("event 1 = %s, want %s for step-1", got[1].Type, terrakube.JobEventStepStarted)
+	}
+	if got[2].Type != terrakube.JobEventStepFinished || got[2].PreviousStatus != terrakube.JobStatusRunning {
+		t.Errorf("event 2 = %s (previous %q), want %s", got[2].Type, got[2].PreviousStatus, terrakube.JobEventStepFinished)
+	}
+}
+
+func TestJobService_Watch_TransientError(t *testing.T) {
+	t.Parallel()
+
+	var polls atomic.Int32
+	var errCount atomic.Int32
+
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("GET /api/v1/organization/org-1/job", func(w http.ResponseWriter, r *http.Request) {
+		if polls.Add(1) == 1 {
+			testutil.WriteError(t, w, http.StatusServiceUnavailable, "try later")
+			return
+		}
+		if got := r.URL.Query().Get("filter[job]"); got != "updatedDate=ge=2024-01-01T00:00:00Z" {
+			t.Errorf("filter after error = %q, want unchanged watermark", got)
+		}
+		testutil.WriteJSONAPIList(t, w, http.StatusOK, []*terrakube.Job{
+			{ID: "job-1", Status: terrakube.JobStatusPending, CreatedDate: strPtr("2024-01-01T00:00:01Z"), UpdatedDate: strPtr("2024-01-01T00:00:01Z")},
+		})
+	})
+
+	client := newTestClient(t, srv)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := client.Jobs.Watch(ctx, "org-1", &terrakube.WatchOptions{
+		Since:    "2024-01-01T00:00:00Z",
+		Interval: 10 * time.Millisecond,
+		OnError:  func(error) { errCount.Add(1) },
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := collectEvents(t, events, 1)
+	if got[0].Type != terrakube.JobEventCreated {
+		t.Errorf("event = %s, want %s", got[0].Type, terrakube.JobEventCreated)
+	}
+	if errCount.Load() != 1 {
+		t.Errorf("OnError called %d times, want 1", errCount.Load())
+	}
+}
+
+func TestJobService_Watch_StepsError(t *testing.T) {
+	t.Parallel()
+
+	var stepCalls atomic.Int32
+
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("GET /api/v1/organization/org-1/job", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSONAPIList(t, w, http.StatusOK, []*terrakube.Job{
+			{ID: "job-1", Status: terrakube.JobStatusRunning, CreatedDate: strPtr("2024-01-01T00:00:01Z"), UpdatedDate: strPtr("2024-01-01T00:00:01Z")},
+		})
+	})
+	srv.HandleFunc("GET /api/v1/organization/org-1/job/job-1/step", func(w http.ResponseWriter, _ *http.Request) {
+		if stepCalls.Add(1) == 1 {
+			testutil.WriteError(t, w, http.StatusServiceUnavailable, "try later")
+			return
+		}
+		testutil.WriteJSONAPIList(t, w, http.StatusOK, []*terrakube.Step{{ID: "step-1", Name: "Plan", Status: terrakube.JobStatusRunning, StepNumber: 100}})
+	})
+
+	client := newTestClient(t, srv)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := client.Jobs.Watch(ctx, "org-1", &terrakube.WatchOptions{
+		Since:    "2024-01-01T00:00:00Z",
+		Interval: 10 * time.Millisecond,
+		Steps:    true,
+		OnError:  func(error) {},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := collectEvents(t, events, 2)
+	if got[0].Type != terrakube.JobEventCreated {
+		t.Errorf("event 0 = %s (previous %q), want %s", got[0].Type, got[0].PreviousStatus, terrakube.JobEventCreated)
+	}
+	if got[1].Type != terrakube.JobEventStepStarted || got[1].Step.ID != "step-1" {
+		t.Errorf("event 1 = %s, want %s for step-1", got[1].Type, terrakube.JobEventStepStarted)
+	}
+}
+
+func TestJobService_Watch_FatalError(t *testing.T) {
+	t.Parallel()
+
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("GET /api/v1/organization/org-1/job", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteError(t, w, http.StatusUnauthorized, "bad token")
+	})
+
+	client := newTestClient(t, srv)
+
+	events, err := client.Jobs.Watch(context.Background(), "org-1", &terrakube.WatchOptions{Interval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := collectEvents(t, events, 1)
+	if got[0].Type != terrakube.JobEventError || !terrakube.IsUnauthorized(got[0].Err) {
+		t.Errorf("event = %s (%v), want %s with 401", got[0].Type, got[0].Err, terrakube.JobEventError)
+	}
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Error("expected channel to be closed after error event")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("channel not closed after error event")
+	}
+}
+
+func TestJobService_Watch_ServerTimestamps(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var filters []string
+
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("GET /api/v1/organization/org-1/job", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		filters = append(filters, r.URL.Query().Get("filter[job]"))
+		mu.Unlock()
+
+		// Half a second after Since, written with fractional seconds and an
+		// offset, so it sorts before Since as a string.
+		testutil.WriteJSONAPIList(t, w, http.StatusOK, []*terrakube.Job{{
+			ID:          "job-1",
+			Status:      terrakube.JobStatusRunning,
+			CreatedDate: strPtr("2024-01-01T02:00:05.5+02:00"),
+			UpdatedDate: strPtr("2024-01-01T02:00:05.5+02:00"),
+		}})
+	})
+
+	client := newTestClient(t, srv)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := client.Jobs.Watch(ctx, "org-1", &terrakube.WatchOptions{
+		Since:    "2024-01-01T00:00:05Z",
+		Interval: 10 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := collectEvents(t, events, 1)
+	if got[0].Type != terrakube.JobEventCreated {
+		t.Errorf("event = %s, want %s", got[0].Type, terrakube.JobEventCreated)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		n := len(filters)
+		mu.Unlock()
+		if n >= 2 {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	for range events {
+		// Drain until the watcher closes the channel.
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(filters) < 2 || filters[1] != "updatedDate=ge=2024-01-01T02:00:05.5+02:00" {
+		t.Errorf("filters = %q, want the watermark in the server's format", filters)
+	}
+}
+
+func TestJobService_Watch_InvalidSince(t *testing.T) {
+	t.Parallel()
+
+	client := newTestClientFromURL(t, "https://example.com")
+
+	_, err := client.Jobs.Watch(context.Background(), "org-1", &terrakube.WatchOptions{Since: "yesterday"})
+	var verr *terrakube.ValidationError
+	if !errors.As(err, &verr) || verr.Field != "since" {
+		t.Errorf("expected a since *ValidationError, got %v", err)
+	}
+}
+
+func TestJobService_Watch_EmptyOrgID(t *testing.T) {
+	t.Parallel()
+
+	client := newTestClientFromURL(t, "https://example.com")
+
+	_, err := client.Jobs.Watch(context.Background(), "", nil)
+	assertValidationError(t, err, "organizationID")
+}
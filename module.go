@@ -1,6 +1,9 @@
 package terrakube
 
-import "context"
+import (
+	"context"
+	"fmt"
+)
 
 // Module represents a Terrakube module resource.
 type Module struct {
@@ -91,3 +94,34 @@ func (s *ModuleService) Delete(ctx context.Context, orgID, id string) error {
 	path := s.client.apiPath("organization", orgID, "module", id)
 	return s.del(ctx, path)
 }
+
+// ResolveVersion returns the module's highest version that satisfies a
+// Terraform-style constraint such as "~> 1.2" or ">= 1.0, < 2.0". Versions
+// are normally plain semantic versions; ones that still carry the module's
+// TagPrefix are compared with the prefix removed.
+// It returns a *ValidationError if orgID or id is empty, an error wrapping
+// ErrNoMatchingVersion if no version matches, and a *APIError on server errors.
+func (s *ModuleService) ResolveVersion(ctx context.Context, orgID, id, constraint string) (*ModuleVersion, error) {
+	mod, err := s.Get(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := s.client.ModuleVersions.List(ctx, orgID, id, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var prefix string
+	if mod.TagPrefix != nil {
+		prefix = *mod.TagPrefix
+	}
+	names := make([]string, len(versions))
+	for i, v := range versions {
+		names[i] = v.Version
+	}
+	i, err := latestMatching(names, prefix, constraint)
+	if err != nil {
+		return nil, fmt.Errorf("module %s: %w", mod.Name, err)
+	}
+	return versions[i], nil
+}
@@ -0,0 +1,154 @@
+package terrakube
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// GitTag is a git tag and the commit it points to.
+type GitTag struct {
+	Name   string
+	Commit string
+}
+
+// ReadGitTags lists the tags of a local git checkout with the commits they
+// point to, peeling annotated tags. It requires the git executable.
+func ReadGitTags(ctx context.Context, dir string) ([]GitTag, error) {
+	cmd := exec.CommandContext(ctx, "git", "-C", dir, "for-each-ref", //nolint:gosec // dir is the caller's own checkout and only ever the -C argument of a fixed git command
+		"--format=%(refname:strip=2)%09%(objectname)%09%(*objectname)", "refs/tags")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("listing git tags in %s: %w: %s", dir, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var tags []GitTag
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 3 {
+			continue
+		}
+		commit := fields[1]
+		if fields[2] != "" {
+			commit = fields[2]
+		}
+		tags = append(tags, GitTag{Name: fields[0], Commit: commit})
+	}
+	return tags, nil
+}
+
+// ModuleSyncOptions configures SyncVersions. Tags takes precedence over Dir
+// when both are set.
+type ModuleSyncOptions struct {
+	// Dir is a local git checkout of the module repository.
+	Dir string
+	// Tags lists the repository tags directly.
+	Tags []GitTag
+}
+
+// ModuleSyncResult reports the outcome of SyncVersions.
+type ModuleSyncResult struct {
+	// Created lists the versions created for tags without a ModuleVersion.
+	Created []*ModuleVersion
+	// Stale lists existing versions that no longer match a tag. They are
+	// not deleted.
+	Stale []*ModuleVersion
+	// Mismatched lists existing versions whose commit differs from their tag's.
+	Mismatched []*ModuleVersion
+	// Skipped lists tags that lack the module's TagPrefix or are not
+	// semantic versions after removing it.
+	Skipped []GitTag
+}
+
+// SyncVersions creates a ModuleVersion, with Commit set, for every
+// repository tag that has the module's TagPrefix and is a semantic version,
+// and reports existing versions that have no matching tag. The version name
+// is the semantic version alone, without the prefix or a leading "v", so tag
+// "vpc/v1.2.0" becomes version "1.2.0". Existing versions that still carry
+// the prefix are matched to their tags as well.
+// It returns a *ValidationError if orgID or id is empty, no tag source is
+// given, or two tags name the same version, such as "v1.2.0" and "1.2.0",
+// and a *APIError on server errors. Nothing is created in that case.
+func (s *ModuleService) SyncVersions(ctx context.Context, orgID, id string, opts ModuleSyncOptions) (*ModuleSyncResult, error) {
+	tags := opts.Tags
+	if tags == nil {
+		if err := validateID("dir", opts.Dir); err != nil {
+			return nil, err
+		}
+	}
+
+	mod, err := s.Get(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if tags == nil {
+		if tags, err = ReadGitTags(ctx, opts.Dir); err != nil {
+			return nil, err
+		}
+	}
+
+	var prefix string
+	if mod.TagPrefix != nil {
+		prefix = *mod.TagPrefix
+	}
+
+	result := &ModuleSyncResult{}
+	wanted := map[string]GitTag{}
+	for _, tag := range tags {
+		if !strings.HasPrefix(tag.Name, prefix) {
+			result.Skipped = append(result.Skipped, tag)
+			continue
+		}
+		if _, err := ParseVersion(strings.TrimPrefix(tag.Name, prefix)); err != nil {
+			result.Skipped = append(result.Skipped, tag)
+			continue
+		}
+		name := moduleVersionName(tag.Name, prefix)
+		if other, ok := wanted[name]; ok {
+			return nil, &ValidationError{Field: "tags", Message: fmt.Sprintf("%s and %s both name version %s", other.Name, tag.Name, name)}
+		}
+		wanted[name] = tag
+	}
+
+	existing, err := s.client.ModuleVersions.List(ctx, orgID, id, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range existing {
+		name := moduleVersionName(v.Version, prefix)
+		tag, ok := wanted[name]
+		switch {
+		case !ok:
+			result.Stale = append(result.Stale, v)
+		case v.Commit != nil && *v.Commit != "" && *v.Commit != tag.Commit:
+			result.Mismatched = append(result.Mismatched, v)
+		}
+		delete(wanted, name)
+	}
+
+	names := make([]string, 0, len(wanted))
+	for name := range wanted {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		commit := wanted[name].Commit
+		created, err := s.client.ModuleVersions.Create(ctx, orgID, id, &ModuleVersion{Version: name, Commit: &commit})
+		if err != nil {
+			return result, fmt.Errorf("creating version %s: %w", name, err)
+		}
+		result.Created = append(result.Created, created)
+	}
+	return result, nil
+}
+
+// moduleVersionName returns the semantic version a tag or module version
+// name stands for, without the tag prefix or a leading "v".
+func moduleVersionName(name, prefix string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(name, prefix), "v")
+}
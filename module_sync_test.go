@@ -0,0 +1,145 @@
+package terrakube_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	terrakube "github.com/terrakube-io/terrakube-go"
+	"github.com/terrakube-io/terrakube-go/testutil"
+)
+
+func TestReadGitTags(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := t.TempDir()
+	git := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "-q")
+	if err := os.WriteFile(filepath.Join(dir, "main.tf"), []byte("# v1"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	git("add", ".")
+	git("commit", "-q", "-m", "first")
+	first := git("rev-parse", "HEAD")
+	git("tag", "v1.0.0")
+	git("commit", "-q", "--allow-empty", "-m", "second")
+	second := git("rev-parse", "HEAD")
+	git("tag", "-a", "v1.1.0", "-m", "annotated")
+
+	tags, err := terrakube.ReadGitTags(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+	want := []terrakube.GitTag{{Name: "v1.0.0", Commit: first}, {Name: "v1.1.0", Commit: second}}
+	if len(tags) != len(want) || tags[0] != want[0] || tags[1] != want[1] {
+		t.Errorf("ReadGitTags = %+v, want %+v", tags, want)
+	}
+}
+
+func TestModuleService_SyncVersions(t *testing.T) {
+	t.Parallel()
+
+	prefix := "vpc/"
+	oldCommit := "aaa"
+	var created []map[string]interface{}
+
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("GET /api/v1/organization/org-1/module/mod-1", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSONAPI(t, w, http.StatusOK, &terrakube.Module{ID: "mod-1", Name: "vpc", TagPrefix: &prefix})
+	})
+	srv.HandleFunc("GET /api/v1/organization/org-1/module/mod-1/version", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSONAPIList(t, w, http.StatusOK, []*terrakube.ModuleVersion{
+			{ID: "v-1", Version: "1.0.0", Commit: &oldCommit},
+			{ID: "v-2", Version: "0.9.0"},
+			// Created with the prefix by an older sync.
+			{ID: "v-3", Version: "vpc/1.1.0", Commit: &oldCommit},
+		})
+	})
+	srv.HandleFunc("POST /api/v1/organization/org-1/module/mod-1/version", func(w http.ResponseWriter, r *http.Request) {
+		attrs := decodeAttributes(t, r)
+		created = append(created, attrs)
+		testutil.WriteJSONAPI(t, w, http.StatusCreated, &terrakube.ModuleVersion{ID: "v-new", Version: attrs["version"].(string)})
+	})
+
+	client := newTestClient(t, srv)
+
+	result, err := client.Modules.SyncVersions(context.Background(), "org-1", "mod-1", terrakube.ModuleSyncOptions{
+		Tags: []terrakube.GitTag{
+			{Name: "vpc/1.0.0", Commit: "aaa"},
+			{Name: "vpc/1.1.0", Commit: "bbb"},
+			{Name: "vpc/v1.2.0", Commit: "ccc"},
+			{Name: "vpc/latest", Commit: "ccc"},
+			{Name: "db/1.0.0", Commit: "ddd"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(created) != 1 || created[0]["version"] != "1.2.0" || created[0]["commit"] != "ccc" {
+		t.Errorf("created = %v, want 1.2.0 at ccc", created)
+	}
+	if len(result.Created) != 1 {
+		t.Errorf("Created = %d, want 1", len(result.Created))
+	}
+	if len(result.Stale) != 1 || result.Stale[0].ID != "v-2" {
+		t.Errorf("Stale = %+v, want v-2", result.Stale)
+	}
+	if len(result.Mismatched) != 1 || result.Mismatched[0].ID != "v-3" {
+		t.Errorf("Mismatched = %+v, want v-3", result.Mismatched)
+	}
+	if len(result.Skipped) != 2 {
+		t.Errorf("Skipped = %+v, want vpc/latest and db/1.0.0", result.Skipped)
+	}
+}
+
+func TestModuleService_SyncVersions_NoSource(t *testing.T) {
+	t.Parallel()
+
+	client := newTestClientFromURL(t, "https://example.com")
+
+	_, err := client.Modules.SyncVersions(context.Background(), "org-1", "mod-1", terrakube.ModuleSyncOptions{})
+	assertValidationError(t, err, "dir")
+}
+
+func TestModuleService_SyncVersions_DuplicateVersion(t *testing.T) {
+	t.Parallel()
+
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("GET /api/v1/organization/org-1/module/mod-1", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSONAPI(t, w, http.StatusOK, &terrakube.Module{ID: "mod-1", Name: "vpc"})
+	})
+	srv.HandleFunc("POST /api/v1/organization/org-1/module/mod-1/version", func(w http.ResponseWriter, _ *http.Request) {
+		t.Error("unexpected version created")
+		w.WriteHeader(http.StatusBadRequest)
+	})
+
+	client := newTestClient(t, srv)
+
+	_, err := client.Modules.SyncVersions(context.Background(), "org-1", "mod-1", terrakube.ModuleSyncOptions{
+		Tags: []terrakube.GitTag{{Name: "v1.2.0", Commit: "aaa"}, {Name: "1.2.0", Commit: "bbb"}},
+	})
+	var ve *terrakube.ValidationError
+	if !errors.As(err, &ve) || ve.Field != "tags" || !strings.Contains(ve.Message, "v1.2.0 and 1.2.0") {
+		t.Errorf("expected a tags *ValidationError naming both tags, got %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package terrakube_test
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"testing"
 
@@ -318,3 +319,38 @@ func TestModuleService_AuthHeader(t *testing.T) {
 	client := newTestClient(t, srv)
 	_, _ = client.Modules.Get(context.Background(), "org-1", "mod-1")
 }
+
+func TestModuleService_ResolveVersion(t *testing.T) {
+	t.Parallel()
+
+	prefix := "vpc/"
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("GET /api/v1/organization/org-1/module/mod-1", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSONAPI(t, w, http.StatusOK, &terrakube.Module{ID: "mod-1", Name: "vpc", TagPrefix: &prefix})
+	})
+	srv.HandleFunc("GET /api/v1/organization/org-1/module/mod-1/version", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSONAPIList(t, w, http.StatusOK, []*terrakube.ModuleVersion{
+			{ID: "v-1", Version: "vpc/1.2.0"},
+			{ID: "v-2", Version: "vpc/v1.10.0"},
+			{ID: "v-3", Version: "vpc/2.0.0"},
+			{ID: "v-4", Version: "db/1.99.0"},
+			{ID: "v-5", Version: "vpc/1.11.0-rc.1"},
+			{ID: "v-6", Version: "1.10.1"},
+		})
+	})
+
+	client := newTestClient(t, srv)
+
+	v, err := client.Modules.ResolveVersion(context.Background(), "org-1", "mod-1", "~> 1.2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v.ID != "v-6" {
+		t.Errorf("resolved %s (%s), want v-6", v.ID, v.Version)
+	}
+
+	_, err = client.Modules.ResolveVersion(context.Background(), "org-1", "mod-1", ">= 3.0")
+	if !errors.Is(err, terrakube.ErrNoMatchingVersion) {
+		t.Errorf("error = %v, want %v", err, terrakube.ErrNoMatchingVersion)
+	}
+}
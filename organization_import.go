@@ -0,0 +1,413 @@
+package terrakube
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// OrganizationImportResult reports the outcome of ImportOrganization.
+type OrganizationImportResult struct {
+	Organization *Organization
+	// MissingSecrets describes the secrets the manifest had no value for,
+	// such as "vcs github: clientSecret or privateKey". Sensitive variables
+	// and SSH keys without a value are not created, and modules and
+	// workspaces that use such an SSH key are created without it and listed
+	// here too.
+	MissingSecrets []string
+}
+
+// manifestIDs maps the names in a manifest to the IDs of the resources
+// created for them.
+type manifestIDs struct {
+	tags, vcs, ssh, templates, collections map[string]string
+}
+
+// lookup returns the ID for a manifest reference: the ID created for a
+// name, or the ID itself for a reference with the manifestIDPrefix. Names
+// of resources that were not created map to "".
+func lookup(ids map[string]string, ref string) string {
+	if id, ok := strings.CutPrefix(ref, manifestIDPrefix); ok {
+		return id
+	}
+	return ids[ref]
+}
+
+// validateManifestReferences checks that every name the manifest refers to
+// is defined in it, so that a typo fails before anything is created.
+func validateManifestReferences(m *OrganizationManifest) error {
+	defined := map[string]map[string]bool{"tag": {}, "vcs": {}, "ssh key": {}, "template": {}, "collection": {}}
+	for _, name := range m.Tags {
+		defined["tag"][name] = true
+	}
+	for _, v := range m.VCS {
+		defined["vcs"][v.Name] = true
+	}
+	for _, k := range m.SSH {
+		defined["ssh key"][k.Name] = true
+	}
+	for _, t := range m.Templates {
+		defined["template"][t.Name] = true
+	}
+	for _, col := range m.Collections {
+		defined["collection"][col.Name] = true
+	}
+
+	var unknown []string
+	check := func(kind, ref, owner string) {
+		if ref == "" || strings.HasPrefix(ref, manifestIDPrefix) || defined[kind][ref] {
+			return
+		}
+		unknown = append(unknown, fmt.Sprintf("%s %q in %s", kind, ref, owner))
+	}
+	for _, mm := range m.Modules {
+		owner := "module " + mm.Name
+		check("vcs", mm.VCS, owner)
+		check("ssh key", mm.SSH, owner)
+	}
+	for _, mw := range m.Workspaces {
+		owner := "workspace " + mw.Name
+		check("template", mw.Template, owner)
+		check("vcs", mw.VCS, owner)
+		check("ssh key", mw.ModuleSSHKey, owner)
+		for _, name := range mw.Tags {
+			check("tag", name, owner)
+		}
+		for _, s := range mw.Schedules {
+			check("template", s.Template, owner)
+		}
+		for _, mh := range mw.Webhooks {
+			check("template", mh.Template, owner)
+			for _, ev := range mh.Events {
+				check("template", ev.Template, owner)
+			}
+		}
+		for _, name := range mw.Collections {
+			check("collection", name, owner)
+		}
+	}
+
+	if len(unknown) > 0 {
+		return &ValidationError{Field: "manifest", Message: "refers to undefined " + strings.Join(unknown, ", ")}
+	}
+	return nil
+}
+
+// ImportOrganization creates a new organization from a manifest written by
+// ExportOrganization. Redacted secrets can be filled in before importing;
+// with WithSecretResolver, secret references in variable values and in VCS
+// and SSH secrets are resolved. If creating fails part way, the result
+// holds the new organization along with the error, so the caller can clean
+// up.
+// It returns a *ValidationError if the manifest is nil, its version is not
+// supported, the organization has no name, or it refers to a name it does
+// not define, a *SecretError if a secret reference cannot be resolved, and a
+// *APIError on server errors.
+func (c *Client) ImportOrganization(ctx context.Context, m *OrganizationManifest) (*OrganizationImportResult, error) {
+	if m == nil {
+		return nil, &ValidationError{Field: "manifest", Message: "must not be nil"}
+	}
+	if err := validateManifestVersion(m.Version); err != nil {
+		return nil, err
+	}
+	if err := validateID("organization name", m.Organization.Name); err != nil {
+		return nil, err
+	}
+	if err := validateManifestReferences(m); err != nil {
+		return nil, err
+	}
+
+	org, err := c.Organizations.Create(ctx, &Organization{
+		Name:          m.Organization.Name,
+		Description:   optionalString(m.Organization.Description),
+		ExecutionMode: m.Organization.ExecutionMode,
+		Icon:          optionalString(m.Organization.Icon),
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := &OrganizationImportResult{Organization: org}
+	ids := &manifestIDs{
+		tags: map[string]string{}, vcs: map[string]string{}, ssh: map[string]string{},
+		templates: map[string]string{}, collections: map[string]string{},
+	}
+
+	if err := c.importSettings(ctx, org.ID, m, ids, result); err != nil {
+		return result, err
+	}
+	if err := c.importRegistry(ctx, org.ID, m, ids, result); err != nil {
+		return result, err
+	}
+	for _, mw := range m.Workspaces {
+		if err := c.importWorkspace(ctx, org.ID, mw, ids, result); err != nil {
+			return result, fmt.Errorf("importing workspace %s: %w", mw.Name, err)
+		}
+	}
+	return result, nil
+}
+
+// importSettings creates the organization-level resources that others
+// refer to: tags, teams, VCS connections, SSH keys, agents, templates,
+// collections, and organization variables.
+func (c *Client) importSettings(ctx context.Context, orgID string, m *OrganizationManifest, ids *manifestIDs, result *OrganizationImportResult) error {
+	for _, name := range m.Tags {
+		t, err := c.Tags.Create(ctx, orgID, &Tag{Name: name})
+		if err != nil {
+			return fmt.Errorf("creating tag %s: %w", name, err)
+		}
+		ids.tags[name] = t.ID
+	}
+
+	for _, t := range m.Teams {
+		_, err := c.Teams.Create(ctx, orgID, &Team{
+			Name: t.Name, ManageState: t.ManageState, ManageWorkspace: t.ManageWorkspace, ManageModule: t.ManageModule,
+			ManageProvider: t.ManageProvider, ManageVcs: t.ManageVcs, ManageTemplate: t.ManageTemplate,
+			ManageJob: t.ManageJob, ManageCollection: t.ManageCollection,
+		})
+		if err != nil {
+			return fmt.Errorf("creating team %s: %w", t.Name, err)
+		}
+	}
+
+	for _, v := range m.VCS {
+		secret, _, err := c.resolveSecret(ctx, "vcs "+v.Name+" clientSecret", v.ClientSecret)
+		if err != nil {
+			return err
+		}
+		key, _, err := c.resolveSecret(ctx, "vcs "+v.Name+" privateKey", v.PrivateKey)
+		if err != nil {
+			return err
+		}
+		if secret == "" && key == "" {
+			result.MissingSecrets = append(result.MissingSecrets, "vcs "+v.Name+": clientSecret or privateKey")
+		}
+		created, err := c.VCS.Create(ctx, orgID, &VCS{
+			Name: v.Name, Description: v.Description, VcsType: v.VcsType, ConnectionType: v.ConnectionType,
+			ClientID: v.ClientID, ClientSecret: secret, PrivateKey: key, Endpoint: v.Endpoint, APIURL: v.APIURL,
+		})
+		if err != nil {
+			return fmt.Errorf("creating vcs %s: %w", v.Name, err)
+		}
+		ids.vcs[v.Name] = created.ID
+	}
+
+	for _, k := range m.SSH {
+		key, _, err := c.resolveSecret(ctx, "ssh "+k.Name+" privateKey", k.PrivateKey)
+		if err != nil {
+			return err
+		}
+		if key == "" {
+			result.MissingSecrets = append(result.MissingSecrets, "ssh "+k.Name+": privateKey")
+			ids.ssh[k.Name] = ""
+			continue
+		}
+		created, err := c.SSH.Create(ctx, orgID, &SSH{Name: k.Name, Description: optionalString(k.Description), SSHType: k.SSHType, PrivateKey: key})
+		if err != nil {
+			return fmt.Errorf("creating ssh key %s: %w", k.Name, err)
+		}
+		ids.ssh[k.Name] = created.ID
+	}
+
+	for _, a := range m.Agents {
+		if _, err := c.Agents.Create(ctx, orgID, &Agent{Name: a.Name, Description: a.Description, URL: a.URL}); err != nil {
+			return fmt.Errorf("creating agent %s: %w", a.Name, err)
+		}
+	}
+
+	for _, t := range m.Templates {
+		created, err := c.Templates.Create(ctx, orgID, &Template{
+			Name: t.Name, Description: optionalString(t.Description), Version: optionalString(t.Version), Content: t.Content,
+		})
+		if err != nil {
+			return fmt.Errorf("creating template %s: %w", t.Name, err)
+		}
+		ids.templates[t.Name] = created.ID
+	}
+
+	for _, mc := range m.Collections {
+		col, err := c.Collections.Create(ctx, orgID, &Collection{Name: mc.Name, Description: optionalString(mc.Description), Priority: mc.Priority})
+		if err != nil {
+			return fmt.Errorf("creating collection %s: %w", mc.Name, err)
+		}
+		ids.collections[mc.Name] = col.ID
+		for _, item := range mc.Items {
+			if missingSecret(item) {
+				result.MissingSecrets = append(result.MissingSecrets, "collection "+mc.Name+": "+item.Key)
+				continue
+			}
+			_, err := c.CollectionItems.Create(ctx, orgID, col.ID, &CollectionItem{
+				Key: item.Key, Value: item.Value, Description: optionalString(item.Description),
+				Category: item.Category, Sensitive: item.Sensitive, Hcl: item.Hcl,
+			})
+			if err != nil {
+				return fmt.Errorf("creating item %s in collection %s: %w", item.Key, mc.Name, err)
+			}
+		}
+	}
+
+	for _, v := range m.Variables {
+		if missingSecret(v) {
+			result.MissingSecrets = append(result.MissingSecrets, "organization variable "+v.Key)
+			continue
+		}
+		sensitive := v.Sensitive
+		_, err := c.OrganizationVariables.Create(ctx, orgID, &OrganizationVariable{
+			Key: v.Key, Value: v.Value, Description: v.Description, Category: v.Category, Sensitive: &sensitive, Hcl: v.Hcl,
+		})
+		if err != nil {
+			return fmt.Errorf("creating organization variable %s: %w", v.Key, err)
+		}
+	}
+	return nil
+}
+
+// importRegistry creates the modules and providers with their versions.
+func (c *Client) importRegistry(ctx context.Context, orgID string, m *OrganizationManifest, ids *manifestIDs, result *OrganizationImportResult) error {
+	for _, mm := range m.Modules {
+		mod := &Module{
+			Name: mm.Name, Description: mm.Description, Provider: mm.Provider, Source: mm.Source,
+			Folder: optionalString(mm.Folder), TagPrefix: optionalString(mm.TagPrefix),
+		}
+		if id := lookup(ids.vcs, mm.VCS); id != "" {
+			mod.Vcs = &VCS{ID: id}
+		}
+		if id := lookup(ids.ssh, mm.SSH); id != "" {
+			mod.SSH = &SSH{ID: id}
+		} else if mm.SSH != "" {
+			result.MissingSecrets = append(result.MissingSecrets, "module "+mm.Name+": ssh key "+mm.SSH)
+		}
+		created, err := c.Modules.Create(ctx, orgID, mod)
+		if err != nil {
+			return fmt.Errorf("creating module %s: %w", mm.Name, err)
+		}
+		for _, v := range mm.Versions {
+			if _, err := c.ModuleVersions.Create(ctx, orgID, created.ID, &ModuleVersion{Version: v.Version, Commit: optionalString(v.Commit)}); err != nil {
+				return fmt.Errorf("creating module %s version %s: %w", mm.Name, v.Version, err)
+			}
+		}
+	}
+
+	for _, mp := range m.Providers {
+		p, err := c.Providers.Create(ctx, orgID, &Provider{Name: mp.Name, Description: optionalString(mp.Description)})
+		if err != nil {
+			return fmt.Errorf("creating provider %s: %w", mp.Name, err)
+		}
+		for _, mv := range mp.Versions {
+			v, err := c.ProviderVersions.Create(ctx, orgID, p.ID, &ProviderVersion{VersionNumber: mv.Version, Protocols: optionalString(mv.Protocols)})
+			if err != nil {
+				return fmt.Errorf("creating provider %s version %s: %w", mp.Name, mv.Version, err)
+			}
+			for _, mi := range mv.Implementations {
+				_, err := c.Implementations.Create(ctx, orgID, p.ID, v.ID, &Implementation{
+					Os: mi.Os, Arch: mi.Arch, Filename: mi.Filename,
+					DownloadURL: optionalString(mi.DownloadURL), ShasumsURL: optionalString(mi.ShasumsURL),
+					ShasumsSignatureURL: optionalString(mi.ShasumsSignatureURL), Shasum: optionalString(mi.Shasum),
+					KeyID: optionalString(mi.KeyID), ASCIIArmor: optionalString(mi.ASCIIArmor),
+					TrustSignature: optionalString(mi.TrustSignature), Source: optionalString(mi.Source), SourceURL: optionalString(mi.SourceURL),
+				})
+				if err != nil {
+					return fmt.Errorf("creating provider %s %s implementation %s_%s: %w", mp.Name, mv.Version, mi.Os, mi.Arch, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// importWorkspace creates a workspace and its children.
+func (c *Client) importWorkspace(ctx context.Context, orgID string, mw *ManifestWorkspace, ids *manifestIDs, result *OrganizationImportResult) error {
+	ws := &Workspace{
+		Name: mw.Name, Description: optionalString(mw.Description), Source: mw.Source, Branch: mw.Branch, Folder: mw.Folder,
+		TemplateID: lookup(ids.templates, mw.Template), IaCType: mw.IaCType, IaCVersion: mw.IaCVersion,
+		ExecutionMode: mw.ExecutionMode, AllowRemoteApply: mw.AllowRemoteApply,
+		ModuleSSHKey: optionalString(lookup(ids.ssh, mw.ModuleSSHKey)),
+	}
+	if id := lookup(ids.vcs, mw.VCS); id != "" {
+		ws.Vcs = &VCS{ID: id}
+	}
+	if mw.ModuleSSHKey != "" && ws.ModuleSSHKey == nil {
+		result.MissingSecrets = append(result.MissingSecrets, "workspace "+mw.Name+": ssh key "+mw.ModuleSSHKey)
+	}
+	created, err := c.Workspaces.Create(ctx, orgID, ws)
+	if err != nil {
+		return err
+	}
+	id := created.ID
+
+	for _, v := range mw.Variables {
+		if missingSecret(v) {
+			result.MissingSecrets = append(result.MissingSecrets, "workspace "+mw.Name+": "+v.Key)
+			continue
+		}
+		_, err := c.Variables.Create(ctx, orgID, id, &Variable{
+			Key: v.Key, Value: v.Value, Description: v.Description, Category: v.Category, Sensitive: v.Sensitive, Hcl: v.Hcl,
+		})
+		if err != nil {
+			return fmt.Errorf("creating variable %s: %w", v.Key, err)
+		}
+	}
+
+	for _, name := range mw.Tags {
+		if _, err := c.WorkspaceTags.Create(ctx, orgID, id, &WorkspaceTag{TagID: lookup(ids.tags, name)}); err != nil {
+			return fmt.Errorf("creating workspace tag %s: %w", name, err)
+		}
+	}
+
+	for _, s := range mw.Schedules {
+		if _, err := c.WorkspaceSchedules.Create(ctx, id, &WorkspaceSchedule{Schedule: s.Cron, TemplateID: lookup(ids.templates, s.Template)}); err != nil {
+			return fmt.Errorf("creating schedule %s: %w", s.Cron, err)
+		}
+	}
+
+	for _, a := range mw.Access {
+		_, err := c.WorkspaceAccess.Create(ctx, orgID, id, &WorkspaceAccess{
+			Name: a.Team, ManageState: a.ManageState, ManageWorkspace: a.ManageWorkspace, ManageJob: a.ManageJob,
+		})
+		if err != nil {
+			return fmt.Errorf("creating access for %s: %w", a.Team, err)
+		}
+	}
+
+	for _, mh := range mw.Webhooks {
+		wh, err := c.Webhooks.Create(ctx, orgID, id, &Webhook{
+			Path: mh.Path, Branch: mh.Branch, TemplateID: lookup(ids.templates, mh.Template), Event: mh.Event,
+		})
+		if err != nil {
+			return fmt.Errorf("creating webhook: %w", err)
+		}
+		for _, ev := range mh.Events {
+			_, err := c.WebhookEvents.Create(ctx, orgID, id, wh.ID, &WebhookEvent{
+				Event: ev.Event, Branch: ev.Branch, Path: ev.Path, Priority: ev.Priority,
+				TemplateID: lookup(ids.templates, ev.Template), Webhook: &Webhook{ID: wh.ID},
+			})
+			if err != nil {
+				return fmt.Errorf("creating webhook event: %w", err)
+			}
+		}
+	}
+
+	for _, name := range mw.Collections {
+		colID := lookup(ids.collections, name)
+		_, err := c.CollectionReferences.Create(ctx, orgID, colID, &CollectionReference{
+			Workspace: &Workspace{ID: id}, Collection: &Collection{ID: colID},
+		})
+		if err != nil {
+			return fmt.Errorf("attaching collection %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// missingSecret reports whether v is sensitive but has no value to send.
+func missingSecret(v *ManifestVariable) bool {
+	return v.Sensitive && v.Value == ""
+}
+
+// optionalString returns nil for an empty string, so unset manifest fields
+// are sent as null.
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,654 @@
+package terrakube
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// OrganizationManifestVersion is the manifest format written by
+// ExportOrganization and read by ImportOrganization.
+const OrganizationManifestVersion = 1
+
+// manifestIDPrefix marks a manifest reference that holds an ID, not a name.
+const manifestIDPrefix = "id:"
+
+// OrganizationManifest describes an organization and everything in it.
+// Resources refer to each other by name, not ID. A reference that cannot be
+// named, such as one to a resource outside the organization, is written as
+// "id:" followed by the ID and is used as is on import. Secrets are redacted:
+// sensitive variable values, VCS client secrets and private keys, and SSH
+// private keys are left empty. Before importing, they can be filled in, for
+// example with secret references for WithSecretResolver.
+type OrganizationManifest struct {
+	Version      int                   `json:"version" yaml:"version"`
+	Organization ManifestOrganization  `json:"organization" yaml:"organization"`
+	Tags         []string              `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Teams        []*ManifestTeam       `json:"teams,omitempty" yaml:"teams,omitempty"`
+	VCS          []*ManifestVCS        `json:"vcs,omitempty" yaml:"vcs,omitempty"`
+	SSH          []*ManifestSSH        `json:"ssh,omitempty" yaml:"ssh,omitempty"`
+	Agents       []*ManifestAgent      `json:"agents,omitempty" yaml:"agents,omitempty"`
+	Templates    []*ManifestTemplate   `json:"templates,omitempty" yaml:"templates,omitempty"`
+	Collections  []*ManifestCollection `json:"collections,omitempty" yaml:"collections,omitempty"`
+	Variables    []*ManifestVariable   `json:"variables,omitempty" yaml:"variables,omitempty"`
+	Modules      []*ManifestModule     `json:"modules,omitempty" yaml:"modules,omitempty"`
+	Providers    []*ManifestProvider   `json:"providers,omitempty" yaml:"providers,omitempty"`
+	Workspaces   []*ManifestWorkspace  `json:"workspaces,omitempty" yaml:"workspaces,omitempty"`
+}
+
+// ManifestOrganization holds the organization's own settings.
+type ManifestOrganization struct {
+	Name          string `json:"name" yaml:"name"`
+	Description   string `json:"description,omitempty" yaml:"description,omitempty"`
+	ExecutionMode string `json:"executionMode,omitempty" yaml:"executionMode,omitempty"`
+	Icon          string `json:"icon,omitempty" yaml:"icon,omitempty"`
+}
+
+// ManifestTeam is a team and its organization permissions.
+type ManifestTeam struct {
+	Name             string `json:"name" yaml:"name"`
+	ManageState      bool   `json:"manageState,omitempty" yaml:"manageState,omitempty"`
+	ManageWorkspace  bool   `json:"manageWorkspace,omitempty" yaml:"manageWorkspace,omitempty"`
+	ManageModule     bool   `json:"manageModule,omitempty" yaml:"manageModule,omitempty"`
+	ManageProvider   bool   `json:"manageProvider,omitempty" yaml:"manageProvider,omitempty"`
+	ManageVcs        bool   `json:"manageVcs,omitempty" yaml:"manageVcs,omitempty"`
+	ManageTemplate   bool   `json:"manageTemplate,omitempty" yaml:"manageTemplate,omitempty"`
+	ManageJob        bool   `json:"manageJob,omitempty" yaml:"manageJob,omitempty"`
+	ManageCollection bool   `json:"manageCollection,omitempty" yaml:"manageCollection,omitempty"`
+}
+
+// ManifestVCS is a VCS connection. ClientSecret and PrivateKey are redacted
+// on export.
+type ManifestVCS struct {
+	Name           string `json:"name" yaml:"name"`
+	Description    string `json:"description,omitempty" yaml:"description,omitempty"`
+	VcsType        string `json:"vcsType" yaml:"vcsType"`
+	ConnectionType string `json:"connectionType,omitempty" yaml:"connectionType,omitempty"`
+	ClientID       string `json:"clientId,omitempty" yaml:"clientId,omitempty"`
+	ClientSecret   string `json:"clientSecret,omitempty" yaml:"clientSecret,omitempty"`
+	PrivateKey     string `json:"privateKey,omitempty" yaml:"privateKey,omitempty"`
+	Endpoint       string `json:"endpoint,omitempty" yaml:"endpoint,omitempty"`
+	APIURL         string `json:"apiUrl,omitempty" yaml:"apiUrl,omitempty"`
+}
+
+// ManifestSSH is an SSH key. PrivateKey is redacted on export.
+type ManifestSSH struct {
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	SSHType     string `json:"sshType,omitempty" yaml:"sshType,omitempty"`
+	PrivateKey  string `json:"privateKey,omitempty" yaml:"privateKey,omitempty"`
+}
+
+// ManifestAgent is an execution agent.
+type ManifestAgent struct {
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	URL         string `json:"url" yaml:"url"`
+}
+
+// ManifestTemplate is a template with its TCL content.
+type ManifestTemplate struct {
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	Version     string `json:"version,omitempty" yaml:"version,omitempty"`
+	Content     string `json:"tcl" yaml:"tcl"`
+}
+
+// ManifestCollection is a collection and its items.
+type ManifestCollection struct {
+	Name        string              `json:"name" yaml:"name"`
+	Description string              `json:"description,omitempty" yaml:"description,omitempty"`
+	Priority    int32               `json:"priority" yaml:"priority"`
+	Items       []*ManifestVariable `json:"items,omitempty" yaml:"items,omitempty"`
+}
+
+// ManifestVariable is an organization variable, collection item, or
+// workspace variable. The value of a sensitive variable is redacted on
+// export.
+type ManifestVariable struct {
+	Key         string `json:"key" yaml:"key"`
+	Value       string `json:"value,omitempty" yaml:"value,omitempty"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	Category    string `json:"category" yaml:"category"`
+	Sensitive   bool   `json:"sensitive,omitempty" yaml:"sensitive,omitempty"`
+	Hcl         bool   `json:"hcl,omitempty" yaml:"hcl,omitempty"`
+}
+
+// ManifestModule is a registry module and its versions.
+type ManifestModule struct {
+	Name        string                   `json:"name" yaml:"name"`
+	Description string                   `json:"description,omitempty" yaml:"description,omitempty"`
+	Provider    string                   `json:"provider" yaml:"provider"`
+	Source      string                   `json:"source" yaml:"source"`
+	Folder      string                   `json:"folder,omitempty" yaml:"folder,omitempty"`
+	TagPrefix   string                   `json:"tagPrefix,omitempty" yaml:"tagPrefix,omitempty"`
+	VCS         string                   `json:"vcs,omitempty" yaml:"vcs,omitempty"`
+	SSH         string                   `json:"ssh,omitempty" yaml:"ssh,omitempty"`
+	Versions    []*ManifestModuleVersion `json:"versions,omitempty" yaml:"versions,omitempty"`
+}
+
+// ManifestModuleVersion is a module version.
+type ManifestModuleVersion struct {
+	Version string `json:"version" yaml:"version"`
+	Commit  string `json:"commit,omitempty" yaml:"commit,omitempty"`
+}
+
+// ManifestProvider is a registry provider and its versions.
+type ManifestProvider struct {
+	Name        string                     `json:"name" yaml:"name"`
+	Description string                     `json:"description,omitempty" yaml:"description,omitempty"`
+	Versions    []*ManifestProviderVersion `json:"versions,omitempty" yaml:"versions,omitempty"`
+}
+
+// ManifestProviderVersion is a provider version and its platform
+// implementations.
+type ManifestProviderVersion struct {
+	Version         string                    `json:"version" yaml:"version"`
+	Protocols       string                    `json:"protocols,omitempty" yaml:"protocols,omitempty"`
+	Implementations []*ManifestImplementation `json:"implementations,omitempty" yaml:"implementations,omitempty"`
+}
+
+// ManifestImplementation is a provider build for one platform.
+type ManifestImplementation struct {
+	Os                  string `json:"os" yaml:"os"`
+	Arch                string `json:"arch" yaml:"arch"`
+	Filename            string `json:"filename" yaml:"filename"`
+	DownloadURL         string `json:"downloadUrl,omitempty" yaml:"downloadUrl,omitempty"`
+	ShasumsURL          string `json:"shasumsUrl,omitempty" yaml:"shasumsUrl,omitempty"`
+	ShasumsSignatureURL string `json:"shasumsSignatureUrl,omitempty" yaml:"shasumsSignatureUrl,omitempty"`
+	Shasum              string `json:"shasum,omitempty" yaml:"shasum,omitempty"`
+	KeyID               string `json:"keyId,omitempty" yaml:"keyId,omitempty"`
+	ASCIIArmor          string `json:"asciiArmor,omitempty" yaml:"asciiArmor,omitempty"`
+	TrustSignature      string `json:"trustSignature,omitempty" yaml:"trustSignature,omitempty"`
+	Source              string `json:"source,omitempty" yaml:"source,omitempty"`
+	SourceURL           string `json:"sourceUrl,omitempty" yaml:"sourceUrl,omitempty"`
+}
+
+// ManifestWorkspace is a workspace and its children. Template, VCS, and
+// ModuleSSHKey name a template, VCS connection, and SSH key in the manifest.
+type ManifestWorkspace struct {
+	Name             string                       `json:"name" yaml:"name"`
+	Description      string                       `json:"description,omitempty" yaml:"description,omitempty"`
+	Source           string                       `json:"source" yaml:"source"`
+	Branch           string                       `json:"branch" yaml:"branch"`
+	Folder           string                       `json:"folder,omitempty" yaml:"folder,omitempty"`
+	Template         string                       `json:"template,omitempty" yaml:"template,omitempty"`
+	IaCType          string                       `json:"iacType,omitempty" yaml:"iacType,omitempty"`
+	IaCVersion       string                       `json:"iacVersion,omitempty" yaml:"iacVersion,omitempty"`
+	ExecutionMode    string                       `json:"executionMode,omitempty" yaml:"executionMode,omitempty"`
+	AllowRemoteApply bool                         `json:"allowRemoteApply,omitempty" yaml:"allowRemoteApply,omitempty"`
+	VCS              string                       `json:"vcs,omitempty" yaml:"vcs,omitempty"`
+	ModuleSSHKey     string                       `json:"moduleSshKey,omitempty" yaml:"moduleSshKey,omitempty"`
+	Variables        []*ManifestVariable          `json:"variables,omitempty" yaml:"variables,omitempty"`
+	Tags             []string                     `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Schedules        []*ManifestWorkspaceSchedule `json:"schedules,omitempty" yaml:"schedules,omitempty"`
+	Access           []*ManifestWorkspaceAccess   `json:"access,omitempty" yaml:"access,omitempty"`
+	Webhooks         []*ManifestWebhook           `json:"webhooks,omitempty" yaml:"webhooks,omitempty"`
+	Collections      []string                     `json:"collections,omitempty" yaml:"collections,omitempty"`
+}
+
+// ManifestWorkspaceSchedule is a scheduled run of a template.
+type ManifestWorkspaceSchedule struct {
+	Cron     string `json:"cron" yaml:"cron"`
+	Template string `json:"template" yaml:"template"`
+}
+
+// ManifestWorkspaceAccess grants a team access to a workspace.
+type ManifestWorkspaceAccess struct {
+	Team            string `json:"team" yaml:"team"`
+	ManageState     bool   `json:"manageState,omitempty" yaml:"manageState,omitempty"`
+	ManageWorkspace bool   `json:"manageWorkspace,omitempty" yaml:"manageWorkspace,omitempty"`
+	ManageJob       bool   `json:"manageJob,omitempty" yaml:"manageJob,omitempty"`
+}
+
+// ManifestWebhook is a workspace webhook and its events.
+type ManifestWebhook struct {
+	Path     string                  `json:"path,omitempty" yaml:"path,omitempty"`
+	Branch   string                  `json:"branch,omitempty" yaml:"branch,omitempty"`
+	Template string                  `json:"template,omitempty" yaml:"template,omitempty"`
+	Event    string                  `json:"event,omitempty" yaml:"event,omitempty"`
+	Events   []*ManifestWebhookEvent `json:"events,omitempty" yaml:"events,omitempty"`
+}
+
+// ManifestWebhookEvent is an event that triggers a webhook.
+type ManifestWebhookEvent struct {
+	Event    string `json:"event" yaml:"event"`
+	Branch   string `json:"branch,omitempty" yaml:"branch,omitempty"`
+	Path     string `json:"path,omitempty" yaml:"path,omitempty"`
+	Priority int32  `json:"priority,omitempty" yaml:"priority,omitempty"`
+	Template string `json:"template,omitempty" yaml:"template,omitempty"`
+}
+
+// YAML encodes the manifest as YAML.
+func (m *OrganizationManifest) YAML() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(m); err != nil {
+		return nil, fmt.Errorf("encoding manifest: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("encoding manifest: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// JSON encodes the manifest as indented JSON.
+func (m *OrganizationManifest) JSON() ([]byte, error) {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encoding manifest: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// ParseOrganizationManifest decodes a YAML or JSON manifest. It returns a
+// *ValidationError if the manifest's version is not supported.
+func ParseOrganizationManifest(data []byte) (*OrganizationManifest, error) {
+	var m OrganizationManifest
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&m); err != nil {
+		return nil, fmt.Errorf("decoding manifest: %w", err)
+	}
+	if err := validateManifestVersion(m.Version); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func validateManifestVersion(version int) error {
+	if version != OrganizationManifestVersion {
+		return &ValidationError{Field: "version", Message: fmt.Sprintf("%d is not supported, expected %d", version, OrganizationManifestVersion)}
+	}
+	return nil
+}
+
+// ExportOrganization reads an organization and everything in it into a
+// manifest that ImportOrganization can recreate: tags, teams, VCS
+// connections, SSH keys, agents, templates, collections with their items,
+// organization variables, modules with their versions, providers with their
+// versions and implementations, and workspaces with their variables, tags,
+// schedules, access grants, webhooks, and collections. Deleted workspaces
+// are skipped. Lists are sorted by name, or by version or another stable key
+// where there is no name, so exports can be diffed.
+// It returns a *ValidationError if orgID is empty and a *APIError on server errors.
+func (c *Client) ExportOrganization(ctx context.Context, orgID string) (*OrganizationManifest, error) {
+	if err := validateID("organization ID", orgID); err != nil {
+		return nil, err
+	}
+
+	org, err := c.Organizations.Get(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	m := &OrganizationManifest{
+		Version: OrganizationManifestVersion,
+		Organization: ManifestOrganization{
+			Name: org.Name, Description: stringValue(org.Description), ExecutionMode: org.ExecutionMode, Icon: stringValue(org.Icon),
+		},
+	}
+
+	tagNames := map[string]string{}
+	tags, err := c.Tags.List(ctx, orgID, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tags {
+		tagNames[t.ID] = t.Name
+		m.Tags = append(m.Tags, t.Name)
+	}
+	sort.Strings(m.Tags)
+
+	teams, err := c.Teams.List(ctx, orgID, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range teams {
+		m.Teams = append(m.Teams, &ManifestTeam{
+			Name: t.Name, ManageState: t.ManageState, ManageWorkspace: t.ManageWorkspace, ManageModule: t.ManageModule,
+			ManageProvider: t.ManageProvider, ManageVcs: t.ManageVcs, ManageTemplate: t.ManageTemplate,
+			ManageJob: t.ManageJob, ManageCollection: t.ManageCollection,
+		})
+	}
+	sort.Slice(m.Teams, func(i, j int) bool { return m.Teams[i].Name < m.Teams[j].Name })
+
+	vcsNames := map[string]string{}
+	vcsList, err := c.VCS.List(ctx, orgID, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range vcsList {
+		vcsNames[v.ID] = v.Name
+		m.VCS = append(m.VCS, &ManifestVCS{
+			Name: v.Name, Description: v.Description, VcsType: v.VcsType, ConnectionType: v.ConnectionType,
+			ClientID: v.ClientID, Endpoint: v.Endpoint, APIURL: v.APIURL,
+		})
+	}
+	sort.Slice(m.VCS, func(i, j int) bool { return m.VCS[i].Name < m.VCS[j].Name })
+
+	sshNames := map[string]string{}
+	sshList, err := c.SSH.List(ctx, orgID, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range sshList {
+		sshNames[k.ID] = k.Name
+		m.SSH = append(m.SSH, &ManifestSSH{Name: k.Name, Description: stringValue(k.Description), SSHType: k.SSHType})
+	}
+	sort.Slice(m.SSH, func(i, j int) bool { return m.SSH[i].Name < m.SSH[j].Name })
+
+	agents, err := c.Agents.List(ctx, orgID, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range agents {
+		m.Agents = append(m.Agents, &ManifestAgent{Name: a.Name, Description: a.Description, URL: a.URL})
+	}
+	sort.Slice(m.Agents, func(i, j int) bool { return m.Agents[i].Name < m.Agents[j].Name })
+
+	templateNames := map[string]string{}
+	templates, err := c.Templates.List(ctx, orgID, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range templates {
+		templateNames[t.ID] = t.Name
+		m.Templates = append(m.Templates, &ManifestTemplate{
+			Name: t.Name, Description: stringValue(t.Description), Version: stringValue(t.Version), Content: t.Content,
+		})
+	}
+	sort.Slice(m.Templates, func(i, j int) bool { return m.Templates[i].Name < m.Templates[j].Name })
+
+	// workspaceCollections maps workspace IDs to the names of their collections.
+	workspaceCollections := map[string][]string{}
+	collections, err := c.Collections.List(ctx, orgID, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, col := range collections {
+		mc := &ManifestCollection{Name: col.Name, Description: stringValue(col.Description), Priority: col.Priority}
+		items, err := c.CollectionItems.List(ctx, orgID, col.ID, nil)
+		if err != nil {
+			return nil, fmt.Errorf("listing items of collection %s: %w", col.Name, err)
+		}
+		for _, item := range items {
+			mc.Items = append(mc.Items, manifestVariable(item.Key, item.Value, stringValue(item.Description), item.Category, item.Sensitive, item.Hcl))
+		}
+		sortManifestVariables(mc.Items)
+		m.Collections = append(m.Collections, mc)
+
+		refs, err := c.CollectionReferences.List(ctx, orgID, col.ID, nil)
+		if err != nil {
+			return nil, fmt.Errorf("listing references of collection %s: %w", col.Name, err)
+		}
+		for _, ref := range refs {
+			if ref.Workspace != nil {
+				workspaceCollections[ref.Workspace.ID] = append(workspaceCollections[ref.Workspace.ID], col.Name)
+			}
+		}
+	}
+	sort.Slice(m.Collections, func(i, j int) bool { return m.Collections[i].Name < m.Collections[j].Name })
+
+	globals, err := c.OrganizationVariables.List(ctx, orgID, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range globals {
+		sensitive := v.Sensitive != nil && *v.Sensitive
+		m.Variables = append(m.Variables, manifestVariable(v.Key, v.Value, v.Description, v.Category, sensitive, v.Hcl))
+	}
+	sortManifestVariables(m.Variables)
+
+	modules, err := c.Modules.List(ctx, orgID, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, mod := range modules {
+		mm := &ManifestModule{
+			Name: mod.Name, Description: mod.Description, Provider: mod.Provider, Source: mod.Source,
+			Folder: stringValue(mod.Folder), TagPrefix: stringValue(mod.TagPrefix),
+		}
+		if mod.Vcs != nil {
+			mm.VCS = nameOrID(vcsNames, mod.Vcs.ID)
+		}
+		if mod.SSH != nil {
+			mm.SSH = nameOrID(sshNames, mod.SSH.ID)
+		}
+		versions, err := c.ModuleVersions.List(ctx, orgID, mod.ID, nil)
+		if err != nil {
+			return nil, fmt.Errorf("listing versions of module %s: %w", mod.Name, err)
+		}
+		for _, v := range versions {
+			mm.Versions = append(mm.Versions, &ManifestModuleVersion{Version: v.Version, Commit: stringValue(v.Commit)})
+		}
+		sortByVersion(mm.Versions, func(v *ManifestModuleVersion) string { return v.Version })
+		m.Modules = append(m.Modules, mm)
+	}
+	sort.Slice(m.Modules, func(i, j int) bool {
+		if m.Modules[i].Name != m.Modules[j].Name {
+			return m.Modules[i].Name < m.Modules[j].Name
+		}
+		return m.Modules[i].Provider < m.Modules[j].Provider
+	})
+
+	providers, err := c.Providers.List(ctx, orgID, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range providers {
+		mp, err := c.exportProvider(ctx, orgID, p)
+		if err != nil {
+			return nil, err
+		}
+		m.Providers = append(m.Providers, mp)
+	}
+	sort.Slice(m.Providers, func(i, j int) bool { return m.Providers[i].Name < m.Providers[j].Name })
+
+	workspaces, err := c.Workspaces.List(ctx, orgID, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, ws := range workspaces {
+		if ws.Deleted {
+			continue
+		}
+		mw := &ManifestWorkspace{
+			Name: ws.Name, Description: stringValue(ws.Description), Source: ws.Source, Branch: ws.Branch, Folder: ws.Folder,
+			Template: nameOrID(templateNames, ws.TemplateID), IaCType: ws.IaCType, IaCVersion: ws.IaCVersion,
+			ExecutionMode: ws.ExecutionMode, AllowRemoteApply: ws.AllowRemoteApply,
+			ModuleSSHKey: nameOrID(sshNames, stringValue(ws.ModuleSSHKey)),
+			Collections:  workspaceCollections[ws.ID],
+		}
+		if ws.Vcs != nil {
+			mw.VCS = nameOrID(vcsNames, ws.Vcs.ID)
+		}
+		sort.Strings(mw.Collections)
+		if err := c.exportWorkspaceChildren(ctx, orgID, ws.ID, mw, tagNames, templateNames); err != nil {
+			return nil, fmt.Errorf("exporting workspace %s: %w", ws.Name, err)
+		}
+		m.Workspaces = append(m.Workspaces, mw)
+	}
+	sort.Slice(m.Workspaces, func(i, j int) bool { return m.Workspaces[i].Name < m.Workspaces[j].Name })
+
+	return m, nil
+}
+
+// exportProvider reads a provider with its versions and implementations.
+func (c *Client) exportProvider(ctx context.Context, orgID string, p *Provider) (*ManifestProvider, error) {
+	mp := &ManifestProvider{Name: p.Name, Description: stringValue(p.Description)}
+	versions, err := c.ProviderVersions.List(ctx, orgID, p.ID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("listing versions of provider %s: %w", p.Name, err)
+	}
+	for _, v := range versions {
+		mv := &ManifestProviderVersion{Version: v.VersionNumber, Protocols: stringValue(v.Protocols)}
+		impls, err := c.Implementations.List(ctx, orgID, p.ID, v.ID, nil)
+		if err != nil {
+			return nil, fmt.Errorf("listing implementations of provider %s %s: %w", p.Name, v.VersionNumber, err)
+		}
+		for _, impl := range impls {
+			mv.Implementations = append(mv.Implementations, &ManifestImplementation{
+				Os: impl.Os, Arch: impl.Arch, Filename: impl.Filename,
+				DownloadURL: stringValue(impl.DownloadURL), ShasumsURL: stringValue(impl.ShasumsURL),
+				ShasumsSignatureURL: stringValue(impl.ShasumsSignatureURL), Shasum: stringValue(impl.Shasum),
+				KeyID: stringValue(impl.KeyID), ASCIIArmor: stringValue(impl.ASCIIArmor),
+				TrustSignature: stringValue(impl.TrustSignature), Source: stringValue(impl.Source), SourceURL: stringValue(impl.SourceURL),
+			})
+		}
+		sort.Slice(mv.Implementations, func(i, j int) bool {
+			a, b := mv.Implementations[i], mv.Implementations[j]
+			if a.Os != b.Os {
+				return a.Os < b.Os
+			}
+			return a.Arch < b.Arch
+		})
+		mp.Versions = append(mp.Versions, mv)
+	}
+	sortByVersion(mp.Versions, func(v *ManifestProviderVersion) string { return v.Version })
+	return mp, nil
+}
+
+// exportWorkspaceChildren reads a workspace's variables, tags, schedules,
+// access grants, and webhooks into mw.
+func (c *Client) exportWorkspaceChildren(ctx context.Context, orgID, workspaceID string, mw *ManifestWorkspace, tagNames, templateNames map[string]string) error {
+	vars, err := c.Variables.List(ctx, orgID, workspaceID, nil)
+	if err != nil {
+		return err
+	}
+	for _, v := range vars {
+		mw.Variables = append(mw.Variables, manifestVariable(v.Key, v.Value, v.Description, v.Category, v.Sensitive, v.Hcl))
+	}
+	sortManifestVariables(mw.Variables)
+
+	tags, err := c.WorkspaceTags.List(ctx, orgID, workspaceID, nil)
+	if err != nil {
+		return err
+	}
+	for _, t := range tags {
+		mw.Tags = append(mw.Tags, nameOrID(tagNames, t.TagID))
+	}
+	sort.Strings(mw.Tags)
+
+	schedules, err := c.WorkspaceSchedules.List(ctx, workspaceID, nil)
+	if err != nil {
+		return err
+	}
+	for _, s := range schedules {
+		mw.Schedules = append(mw.Schedules, &ManifestWorkspaceSchedule{Cron: s.Schedule, Template: nameOrID(templateNames, s.TemplateID)})
+	}
+	sort.Slice(mw.Schedules, func(i, j int) bool {
+		a, b := mw.Schedules[i], mw.Schedules[j]
+		if a.Cron != b.Cron {
+			return a.Cron < b.Cron
+		}
+		return a.Template < b.Template
+	})
+
+	access, err := c.WorkspaceAccess.List(ctx, orgID, workspaceID, nil)
+	if err != nil {
+		return err
+	}
+	for _, a := range access {
+		mw.Access = append(mw.Access, &ManifestWorkspaceAccess{
+			Team: a.Name, ManageState: a.ManageState, ManageWorkspace: a.ManageWorkspace, ManageJob: a.ManageJob,
+		})
+	}
+	sort.Slice(mw.Access, func(i, j int) bool { return mw.Access[i].Team < mw.Access[j].Team })
+
+	webhooks, err := c.Webhooks.List(ctx, orgID, workspaceID, nil)
+	if err != nil {
+		return err
+	}
+	for _, wh := range webhooks {
+		mh := &ManifestWebhook{Path: wh.Path, Branch: wh.Branch, Template: nameOrID(templateNames, wh.TemplateID), Event: wh.Event}
+		events, err := c.WebhookEvents.List(ctx, orgID, workspaceID, wh.ID, nil)
+		if err != nil {
+			return err
+		}
+		for _, ev := range events {
+			mh.Events = append(mh.Events, &ManifestWebhookEvent{
+				Event: ev.Event, Branch: ev.Branch, Path: ev.Path, Priority: ev.Priority, Template: nameOrID(templateNames, ev.TemplateID),
+			})
+		}
+		sort.Slice(mh.Events, func(i, j int) bool {
+			a, b := mh.Events[i], mh.Events[j]
+			if a.Priority != b.Priority {
+				return a.Priority < b.Priority
+			}
+			return lessStrings([]string{a.Event, a.Branch, a.Path, a.Template}, []string{b.Event, b.Branch, b.Path, b.Template})
+		})
+		mw.Webhooks = append(mw.Webhooks, mh)
+	}
+	sort.Slice(mw.Webhooks, func(i, j int) bool {
+		a, b := mw.Webhooks[i], mw.Webhooks[j]
+		return lessStrings([]string{a.Path, a.Branch, a.Event, a.Template}, []string{b.Path, b.Branch, b.Event, b.Template})
+	})
+	return nil
+}
+
+// manifestVariable builds a ManifestVariable, redacting sensitive values.
+func manifestVariable(key, value, description, category string, sensitive, hcl bool) *ManifestVariable {
+	if sensitive {
+		value = ""
+	}
+	return &ManifestVariable{Key: key, Value: value, Description: description, Category: category, Sensitive: sensitive, Hcl: hcl}
+}
+
+func sortManifestVariables(vars []*ManifestVariable) {
+	sort.Slice(vars, func(i, j int) bool {
+		if vars[i].Category != vars[j].Category {
+			return vars[i].Category < vars[j].Category
+		}
+		return vars[i].Key < vars[j].Key
+	})
+}
+
+// sortByVersion sorts items in ascending semantic version order, as
+// SortVersions does.
+func sortByVersion[T any](items []T, version func(T) string) {
+	versions := make([]string, len(items))
+	for i, item := range items {
+		versions[i] = version(item)
+	}
+	SortVersions(versions)
+	rank := make(map[string]int, len(versions))
+	for i, v := range versions {
+		rank[v] = i
+	}
+	sort.SliceStable(items, func(i, j int) bool { return rank[version(items[i])] < rank[version(items[j])] })
+}
+
+// lessStrings compares two equal-length keys field by field.
+func lessStrings(a, b []string) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
+
+// nameOrID returns the name for id. An id that is not in names, such as a
+// reference to a resource outside the organization, is returned with the
+// manifestIDPrefix so that ImportOrganization uses it as an ID.
+func nameOrID(names map[string]string, id string) string {
+	if id == "" {
+		return ""
+	}
+	if name, ok := names[id]; ok {
+		return name
+	}
+	return manifestIDPrefix + id
+}
+
+func stringValue(p *string) string {
+	if p == nil {
+		return ""
+	}
+	return *p
+}
@@ -0,0 +1,423 @@
+package terrakube_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	terrakube "github.com/terrakube-io/terrakube-go"
+	"github.com/terrakube-io/terrakube-go/testutil"
+)
+
+// writeRelated writes a JSON:API list of one resource with to-one
+// relationships, which WriteJSONAPIList does not support.
+func writeRelated(t *testing.T, w http.ResponseWriter, typ, id string, attrs map[string]interface{}, rels map[string][2]string) {
+	t.Helper()
+	relationships := map[string]interface{}{}
+	for name, rel := range rels {
+		relationships[name] = map[string]interface{}{"data": map[string]string{"type": rel[0], "id": rel[1]}}
+	}
+	testutil.WriteJSON(t, w, http.StatusOK, map[string]interface{}{
+		"data": []map[string]interface{}{{"type": typ, "id": id, "attributes": attrs, "relationships": relationships}},
+	})
+}
+
+func newOrganizationExportServer(t *testing.T) *terrakube.Client {
+	t.Helper()
+	srv := testutil.NewServer(t)
+	org := "/api/v1/organization/org-1"
+	list := func(path string, entities interface{}) {
+		srv.HandleFunc("GET "+path, func(w http.ResponseWriter, _ *http.Request) {
+			testutil.WriteJSONAPIList(t, w, http.StatusOK, entities)
+		})
+	}
+	sensitive := true
+
+	srv.HandleFunc("GET "+org, func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSONAPI(t, w, http.StatusOK, &terrakube.Organization{ID: "org-1", Name: "acme", ExecutionMode: "remote"})
+	})
+	list(org+"/tag", []*terrakube.Tag{{ID: "tag-2", Name: "prod"}, {ID: "tag-1", Name: "aws"}})
+	list(org+"/team", []*terrakube.Team{{ID: "team-1", Name: "devs", ManageWorkspace: true}})
+	list(org+"/vcs", []*terrakube.VCS{{ID: "vcs-1", Name: "github", VcsType: "GITHUB", ClientID: "cid", ClientSecret: "csecret"}})
+	list(org+"/ssh", []*terrakube.SSH{{ID: "ssh-1", Name: "deploy", SSHType: "rsa", PrivateKey: "-----BEGIN KEY-----"}})
+	list(org+"/agent", []*terrakube.Agent{{ID: "agent-1", Name: "private", URL: "http://agent:8090"}})
+	list(org+"/template", []*terrakube.Template{{ID: "tpl-1", Name: "Plan and apply", Content: "flow: []\n"}})
+	list(org+"/collection", []*terrakube.Collection{{ID: "col-1", Name: "aws", Priority: 5}})
+	list(org+"/collection/col-1/item", []*terrakube.CollectionItem{
+		{ID: "item-1", Key: "AWS_REGION", Value: "eu-west-1", Category: terrakube.CategoryEnv},
+		{ID: "item-2", Key: "AWS_SECRET_ACCESS_KEY", Value: "leaked", Category: terrakube.CategoryEnv, Sensitive: true},
+	})
+	srv.HandleFunc("GET "+org+"/collection/col-1/reference", func(w http.ResponseWriter, _ *http.Request) {
+		writeRelated(t, w, "reference", "ref-1", nil, map[string][2]string{"workspace": {"workspace", "ws-1"}})
+	})
+	list(org+"/globalvar", []*terrakube.OrganizationVariable{
+		{ID: "gv-1", Key: "owner", Value: "platform", Category: terrakube.CategoryTerraform},
+		{ID: "gv-2", Key: "token", Value: "leaked", Category: terrakube.CategoryEnv, Sensitive: &sensitive},
+	})
+	srv.HandleFunc("GET "+org+"/module", func(w http.ResponseWriter, _ *http.Request) {
+		writeRelated(t, w, "module", "mod-1", map[string]interface{}{
+			"name": "vpc", "provider": "aws", "source": "https://github.com/acme/vpc.git",
+		}, map[string][2]string{"vcs": {"vcs", "vcs-1"}})
+	})
+	list(org+"/module/mod-1/version", []*terrakube.ModuleVersion{{ID: "mv-1", Version: "1.10.0"}, {ID: "mv-2", Version: "1.9.0"}})
+	list(org+"/provider", []*terrakube.Provider{{ID: "prov-1", Name: "random"}})
+	list(org+"/provider/prov-1/version", []*terrakube.ProviderVersion{{ID: "pv-1", VersionNumber: "3.6.0"}})
+	list(org+"/provider/prov-1/version/pv-1/implementation", []*terrakube.Implementation{{ID: "impl-1", Os: "linux", Arch: "amd64", Filename: "random.zip"}})
+	list(org+"/workspace", []*terrakube.Workspace{
+		{ID: "ws-1", Name: "app", Source: "https://github.com/acme/app.git", Branch: "main", TemplateID: "tpl-1"},
+		{ID: "ws-2", Name: "old", Deleted: true},
+	})
+	list(org+"/workspace/ws-1/variable", []*terrakube.Variable{
+		{ID: "var-1", Key: "size", Value: "small", Category: terrakube.CategoryTerraform},
+		{ID: "var-2", Key: "db_password", Value: "leaked", Category: terrakube.CategoryTerraform, Sensitive: true},
+	})
+	list(org+"/workspace/ws-1/workspaceTag", []*terrakube.WorkspaceTag{{ID: "wt-1", TagID: "tag-2"}})
+	list("/api/v1/workspace/ws-1/schedule", []*terrakube.WorkspaceSchedule{
+		{ID: "sch-1", Schedule: "0 2 * * *", TemplateID: "tpl-1"},
+		{ID: "sch-2", Schedule: "0 1 * * *", TemplateID: "tpl-external"},
+	})
+	list(org+"/workspace/ws-1/access", []*terrakube.WorkspaceAccess{{ID: "acc-1", Name: "devs", ManageJob: true}})
+	list(org+"/workspace/ws-1/webhook", []*terrakube.Webhook{{ID: "wh-1", Branch: "main", TemplateID: "tpl-1"}})
+	list(org+"/workspace/ws-1/webhook/wh-1/events", []*terrakube.WebhookEvent{{ID: "ev-1", Event: "PUSH", Branch: "main", TemplateID: "tpl-1"}})
+
+	return newTestClient(t, srv)
+}
+
+func TestClient_ExportOrganization(t *testing.T) {
+	t.Parallel()
+
+	c := newOrganizationExportServer(t)
+	m, err := c.ExportOrganization(context.Background(), "org-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if m.Version != terrakube.OrganizationManifestVersion || m.Organization.Name != "acme" {
+		t.Errorf("manifest header = %d %q", m.Version, m.Organization.Name)
+	}
+	if !reflect.DeepEqual(m.Tags, []string{"aws", "prod"}) {
+		t.Errorf("Tags = %v, want sorted names", m.Tags)
+	}
+	if m.VCS[0].ClientID != "cid" || m.VCS[0].ClientSecret != "" || m.SSH[0].PrivateKey != "" {
+		t.Errorf("VCS and SSH secrets were not redacted: %+v %+v", m.VCS[0], m.SSH[0])
+	}
+	if items := m.Collections[0].Items; items[1].Key != "AWS_SECRET_ACCESS_KEY" || items[1].Value != "" || !items[1].Sensitive {
+		t.Errorf("collection items = %+v %+v, want the secret redacted", items[0], items[1])
+	}
+	if m.Variables[0].Key != "token" || m.Variables[0].Value != "" || m.Variables[1].Value != "platform" {
+		t.Errorf("organization variables = %+v %+v", m.Variables[0], m.Variables[1])
+	}
+	if m.Modules[0].VCS != "github" || m.Modules[0].Versions[0].Version != "1.9.0" || m.Modules[0].Versions[1].Version != "1.10.0" {
+		t.Errorf("module = %+v", m.Modules[0])
+	}
+	if impl := m.Providers[0].Versions[0].Implementations[0]; impl.Os != "linux" || impl.Arch != "amd64" {
+		t.Errorf("implementation = %+v", impl)
+	}
+
+	if len(m.Workspaces) != 1 {
+		t.Fatalf("got %d workspaces, want the deleted one skipped", len(m.Workspaces))
+	}
+	ws := m.Workspaces[0]
+	if ws.Template != "Plan and apply" || !reflect.DeepEqual(ws.Tags, []string{"prod"}) || !reflect.DeepEqual(ws.Collections, []string{"aws"}) {
+		t.Errorf("workspace references = template %q tags %v collections %v", ws.Template, ws.Tags, ws.Collections)
+	}
+	if ws.Variables[0].Key != "db_password" || ws.Variables[0].Value != "" || ws.Variables[1].Value != "small" {
+		t.Errorf("workspace variables = %+v %+v", ws.Variables[0], ws.Variables[1])
+	}
+	if ws.Schedules[0].Template != "id:tpl-external" || ws.Schedules[1].Template != "Plan and apply" {
+		t.Errorf("schedules = %+v %+v, want sorted by cron with the unknown template kept as an ID", ws.Schedules[0], ws.Schedules[1])
+	}
+	if ws.Access[0].Team != "devs" || ws.Webhooks[0].Events[0].Template != "Plan and apply" {
+		t.Errorf("workspace children = %+v %+v", ws.Access[0], ws.Webhooks[0].Events[0])
+	}
+
+	for name, encode := range map[string]func() ([]byte, error){"yaml": m.YAML, "json": m.JSON} {
+		data, err := encode()
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if strings.Contains(string(data), "leaked") || strings.Contains(string(data), "csecret") {
+			t.Errorf("%s manifest contains a secret:\n%s", name, data)
+		}
+		parsed, err := terrakube.ParseOrganizationManifest(data)
+		if err != nil {
+			t.Fatalf("%s: parsing: %v", name, err)
+		}
+		if !reflect.DeepEqual(parsed, m) {
+			t.Errorf("%s round trip changed the manifest:\n%s", name, data)
+		}
+	}
+}
+
+func TestParseOrganizationManifest_Errors(t *testing.T) {
+	t.Parallel()
+
+	_, err := terrakube.ParseOrganizationManifest([]byte("version: 2\norganization:\n  name: acme\n"))
+	var verr *terrakube.ValidationError
+	if !errors.As(err, &verr) || verr.Field != "version" {
+		t.Errorf("expected a version *ValidationError, got %v", err)
+	}
+
+	if _, err := terrakube.ParseOrganizationManifest([]byte("version: 1\nworkspace: []\n")); err == nil {
+		t.Error("expected error for an unknown field")
+	}
+}
+
+// importCall is a create request received by the import test server.
+type importCall struct {
+	path  string
+	id    string
+	attrs map[string]interface{}
+	rels  map[string]string
+}
+
+// newImportServer answers every POST by echoing the resource with a new ID.
+func newImportServer(t *testing.T) (*testutil.Server, *[]importCall) {
+	t.Helper()
+	srv := testutil.NewServer(t)
+	var mu sync.Mutex
+	var calls []importCall
+
+	srv.HandleFunc("POST /api/v1/", func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var payload struct {
+			Data struct {
+				Type          string                 `json:"type"`
+				Attributes    map[string]interface{} `json:"attributes"`
+				Relationships map[string]struct {
+					Data struct {
+						ID string `json:"id"`
+					} `json:"data"`
+				} `json:"relationships"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Fatalf("decoding %s: %v", r.URL.Path, err)
+		}
+
+		mu.Lock()
+		call := importCall{
+			path:  strings.TrimPrefix(r.URL.Path, "/api/v1/"),
+			id:    fmt.Sprintf("%s-%d", payload.Data.Type, len(calls)+1),
+			attrs: payload.Data.Attributes,
+			rels:  map[string]string{},
+		}
+		for name, rel := range payload.Data.Relationships {
+			call.rels[name] = rel.Data.ID
+		}
+		calls = append(calls, call)
+		mu.Unlock()
+
+		testutil.WriteJSON(t, w, http.StatusCreated, map[string]interface{}{
+			"data": map[string]interface{}{"type": payload.Data.Type, "id": call.id, "attributes": payload.Data.Attributes},
+		})
+	})
+	return srv, &calls
+}
+
+func TestClient_ImportOrganization(t *testing.T) {
+	t.Parallel()
+
+	m, err := terrakube.ParseOrganizationManifest([]byte(`
+version: 1
+organization:
+  name: acme-copy
+tags: [prod]
+teams:
+  - name: devs
+    manageWorkspace: true
+vcs:
+  - name: github
+    vcsType: GITHUB
+    clientId: cid
+    clientSecret: env://GITHUB_SECRET
+ssh:
+  - name: deploy
+    sshType: rsa
+templates:
+  - name: Plan and apply
+    tcl: "flow: []"
+collections:
+  - name: aws
+    priority: 5
+    items:
+      - {key: AWS_REGION, value: eu-west-1, category: ENV}
+      - {key: AWS_SECRET_ACCESS_KEY, category: ENV, sensitive: true}
+variables:
+  - {key: owner, value: platform, category: TERRAFORM}
+modules:
+  - name: vpc
+    provider: aws
+    source: https://github.com/acme/vpc.git
+    vcs: github
+    ssh: deploy
+    versions: [{version: 1.0.0}]
+providers:
+  - name: random
+    versions:
+      - version: 3.6.0
+        implementations: [{os: linux, arch: amd64, filename: random.zip}]
+workspaces:
+  - name: app
+    source: https://github.com/acme/app.git
+    branch: main
+    template: Plan and apply
+    vcs: github
+    variables:
+      - {key: size, value: small, category: TERRAFORM}
+      - {key: db_password, category: TERRAFORM, sensitive: true}
+    tags: [prod]
+    schedules: [{cron: "0 2 * * *", template: Plan and apply}]
+    access: [{team: devs, manageJob: true}]
+    webhooks:
+      - branch: main
+        template: Plan and apply
+        events: [{event: PUSH, branch: main, template: Plan and apply}]
+    collections: [aws]
+`))
+	if err != nil {
+		t.Fatalf("parsing manifest: %v", err)
+	}
+
+	srv, calls := newImportServer(t)
+	resolver := terrakube.SecretResolverFunc(func(_ context.Context, ref string) (string, bool, error) {
+		if ref == "env://GITHUB_SECRET" {
+			return "resolved-secret", true, nil
+		}
+		return "", false, nil
+	})
+	c, err := terrakube.NewClient(terrakube.WithEndpoint(srv.URL), terrakube.WithToken("test-token"), terrakube.WithSecretResolver(resolver))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := c.ImportOrganization(context.Background(), m)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Organization.ID != "organization-1" || result.Organization.Name != "acme-copy" {
+		t.Errorf("Organization = %+v", result.Organization)
+	}
+	wantMissing := []string{
+		"collection aws: AWS_SECRET_ACCESS_KEY", "module vpc: ssh key deploy", "ssh deploy: privateKey", "workspace app: db_password",
+	}
+	missing := append([]string(nil), result.MissingSecrets...)
+	sort.Strings(missing)
+	if !reflect.DeepEqual(missing, wantMissing) {
+		t.Errorf("MissingSecrets = %v, want %v", missing, wantMissing)
+	}
+
+	byPath := map[string][]importCall{}
+	ids := map[string]string{}
+	for _, call := range *calls {
+		byPath[call.path] = append(byPath[call.path], call)
+		if name, ok := call.attrs["name"].(string); ok {
+			ids[name] = call.id
+		}
+	}
+	org := "organization/organization-1/"
+	ws := org + "workspace/" + ids["app"] + "/"
+
+	if vcs := byPath[org+"vcs"]; len(vcs) != 1 || vcs[0].attrs["clientSecret"] != "resolved-secret" {
+		t.Errorf("vcs create = %+v, want the resolved secret", vcs)
+	}
+	if len(byPath[org+"ssh"]) != 0 {
+		t.Error("ssh key without a private key should not be created")
+	}
+	if mod := byPath[org+"module"]; len(mod) != 1 || mod[0].rels["vcs"] != ids["github"] || mod[0].rels["ssh"] != "" {
+		t.Errorf("module create = %+v, want the new vcs and no ssh key", mod)
+	}
+	if len(byPath[org+"module/"+ids["vpc"]+"/version"]) != 1 {
+		t.Error("module version was not created")
+	}
+	pv := byPath[org+"provider/"+ids["random"]+"/version"]
+	if len(pv) != 1 || len(byPath[org+"provider/"+ids["random"]+"/version/"+pv[0].id+"/implementation"]) != 1 {
+		t.Error("provider version and implementation were not created")
+	}
+	if items := byPath[org+"collection/"+ids["aws"]+"/item"]; len(items) != 1 || items[0].attrs["key"] != "AWS_REGION" {
+		t.Errorf("collection items = %+v", items)
+	}
+
+	wsCreate := byPath[org+"workspace"]
+	if len(wsCreate) != 1 || wsCreate[0].attrs["defaultTemplate"] != ids["Plan and apply"] || wsCreate[0].rels["vcs"] != ids["github"] {
+		t.Errorf("workspace create = %+v, want the new template and vcs IDs", wsCreate)
+	}
+	if vars := byPath[ws+"variable"]; len(vars) != 1 || vars[0].attrs["key"] != "size" {
+		t.Errorf("workspace variables = %+v", vars)
+	}
+	if tags := byPath[ws+"workspaceTag"]; len(tags) != 1 || tags[0].attrs["tagId"] != byPath[org+"tag"][0].id {
+		t.Errorf("workspace tags = %+v", tags)
+	}
+	if sch := byPath["workspace/"+ids["app"]+"/schedule"]; len(sch) != 1 || sch[0].attrs["templateReference"] != ids["Plan and apply"] {
+		t.Errorf("schedules = %+v", sch)
+	}
+	if acc := byPath[ws+"access"]; len(acc) != 1 || acc[0].attrs["name"] != "devs" {
+		t.Errorf("access = %+v", acc)
+	}
+	hooks := byPath[ws+"webhook"]
+	if len(hooks) != 1 || len(byPath[ws+"webhook/"+hooks[0].id+"/event"]) != 1 {
+		t.Errorf("webhooks = %+v", hooks)
+	}
+	if refs := byPath[org+"collection/"+ids["aws"]+"/reference"]; len(refs) != 1 || refs[0].rels["workspace"] != ids["app"] {
+		t.Errorf("collection references = %+v", refs)
+	}
+}
+
+func TestClient_ImportOrganization_Validation(t *testing.T) {
+	t.Parallel()
+
+	c := newTestClientFromURL(t, "https://example.com")
+	ctx := context.Background()
+
+	var verr *terrakube.ValidationError
+	if _, err := c.ImportOrganization(ctx, nil); !errors.As(err, &verr) || verr.Field != "manifest" {
+		t.Errorf("expected a manifest *ValidationError, got %v", err)
+	}
+	if _, err := c.ImportOrganization(ctx, &terrakube.OrganizationManifest{Version: 7}); !errors.As(err, &verr) || verr.Field != "version" {
+		t.Errorf("expected a version *ValidationError, got %v", err)
+	}
+	_, err := c.ImportOrganization(ctx, &terrakube.OrganizationManifest{Version: terrakube.OrganizationManifestVersion})
+	assertValidationError(t, err, "organization name")
+}
+
+func TestClient_ImportOrganization_References(t *testing.T) {
+	t.Parallel()
+
+	m := &terrakube.OrganizationManifest{
+		Version:      terrakube.OrganizationManifestVersion,
+		Organization: terrakube.ManifestOrganization{Name: "acme"},
+		Templates:    []*terrakube.ManifestTemplate{{Name: "Plan and apply"}},
+		Workspaces: []*terrakube.ManifestWorkspace{{
+			Name:      "app",
+			Template:  "Plan and aply",
+			Tags:      []string{"prod"},
+			Schedules: []*terrakube.ManifestWorkspaceSchedule{{Cron: "0 2 * * *", Template: "id:tpl-external"}},
+		}},
+	}
+
+	c := newTestClientFromURL(t, "https://example.com")
+	_, err := c.ImportOrganization(context.Background(), m)
+	var verr *terrakube.ValidationError
+	if !errors.As(err, &verr) || verr.Field != "manifest" {
+		t.Fatalf("expected a manifest *ValidationError, got %v", err)
+	}
+	for _, want := range []string{`template "Plan and aply" in workspace app`, `tag "prod" in workspace app`} {
+		if !strings.Contains(verr.Message, want) {
+			t.Errorf("message %q does not mention %s", verr.Message, want)
+		}
+	}
+	if strings.Contains(verr.Message, "tpl-external") {
+		t.Errorf("message %q reports an explicit ID reference", verr.Message)
+	}
+}
@@ -48,18 +48,31 @@ func (s *OrganizationVariableService) Get(ctx context.Context, orgID, id string)
 }
 
 // Create creates a new global variable in the organization.
-// It returns a *ValidationError if orgID is empty and a *APIError on server errors.
+// It returns a *ValidationError if orgID is empty or the value is not valid HCL
+// while Hcl is set, a *SecretError if a secret reference in the value cannot be
+// resolved, and a *APIError on server errors.
 func (s *OrganizationVariableService) Create(ctx context.Context, orgID string, variable *OrganizationVariable) (*OrganizationVariable, error) {
 	if err := validateID("organization ID", orgID); err != nil {
 		return nil, err
 	}
+	variable, err := s.client.resolveOrganizationVariable(ctx, variable)
+	if err != nil {
+		return nil, err
+	}
+	if variable.Hcl {
+		if err := validateHCLValue(variable.Key, variable.Value); err != nil {
+			return nil, err
+		}
+	}
 
 	path := s.client.apiPath("organization", orgID, "globalvar")
 	return s.create(ctx, path, variable)
 }
 
 // Update modifies an existing organization variable. The variable's ID field must be set.
-// It returns a *ValidationError if orgID or the ID is empty and a *APIError on server errors.
+// It returns a *ValidationError if orgID or the ID is empty or the value is not
+// valid HCL while Hcl is set, a *SecretError if a secret reference in the value
+// cannot be resolved, and a *APIError on server errors.
 func (s *OrganizationVariableService) Update(ctx context.Context, orgID string, variable *OrganizationVariable) (*OrganizationVariable, error) {
 	if err := validateID("organization ID", orgID); err != nil {
 		return nil, err
@@ -67,6 +80,15 @@ func (s *OrganizationVariableService) Update(ctx context.Context, orgID string,
 	if err := validateID("globalvar ID", variable.ID); err != nil {
 		return nil, err
 	}
+	variable, err := s.client.resolveOrganizationVariable(ctx, variable)
+	if err != nil {
+		return nil, err
+	}
+	if variable.Hcl {
+		if err := validateHCLValue(variable.Key, variable.Value); err != nil {
+			return nil, err
+		}
+	}
 
 	path := s.client.apiPath("organization", orgID, "globalvar", variable.ID)
 	return s.update(ctx, path, variable)
@@ -0,0 +1,221 @@
+package terrakube
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrNoPlan indicates a job has no stored Terraform plan.
+var ErrNoPlan = errors.New("job has no terraform plan")
+
+// PlanAction is the normalized action Terraform plans for a resource.
+type PlanAction string
+
+// Supported plan actions. PlanActionReplace covers both delete-then-create and
+// create-then-delete replacements.
+const (
+	PlanActionNoOp    PlanAction = "no-op"
+	PlanActionCreate  PlanAction = "create"
+	PlanActionRead    PlanAction = "read"
+	PlanActionUpdate  PlanAction = "update"
+	PlanActionDelete  PlanAction = "delete"
+	PlanActionReplace PlanAction = "replace"
+)
+
+// Plan is a Terraform plan in the `terraform show -json` format.
+type Plan struct {
+	FormatVersion    string                 `json:"format_version"`
+	TerraformVersion string                 `json:"terraform_version"`
+	ResourceChanges  []*ResourceChange      `json:"resource_changes"`
+	ResourceDrift    []*ResourceChange      `json:"resource_drift,omitempty"`
+	OutputChanges    map[string]*PlanChange `json:"output_changes,omitempty"`
+	Errored          bool                   `json:"errored"`
+}
+
+// ResourceChange describes the planned change for a single resource instance.
+type ResourceChange struct {
+	Address       string      `json:"address"`
+	ModuleAddress string      `json:"module_address,omitempty"`
+	Mode          string      `json:"mode"`
+	Type          string      `json:"type"`
+	Name          string      `json:"name"`
+	Index         interface{} `json:"index,omitempty"`
+	ProviderName  string      `json:"provider_name"`
+	ActionReason  string      `json:"action_reason,omitempty"`
+	Change        *PlanChange `json:"change"`
+}
+
+// PlanChange holds the before and after values of a planned change.
+// Sensitive and unknown markers mirror the value structure, with true leaves
+// marking sensitive or unknown attributes.
+type PlanChange struct {
+	Actions         []string    `json:"actions"`
+	Before          interface{} `json:"before"`
+	After           interface{} `json:"after"`
+	AfterUnknown    interface{} `json:"after_unknown,omitempty"`
+	BeforeSensitive interface{} `json:"before_sensitive,omitempty"`
+	AfterSensitive  interface{} `json:"after_sensitive,omitempty"`
+}
+
+// PlanSummary counts planned resource changes. As in Terraform's own summary
+// line, replacements are included in both Add and Destroy; Replace reports how
+// many of them there are.
+type PlanSummary struct {
+	Add       int
+	Change    int
+	Destroy   int
+	Replace   int
+	Resources []ResourceSummary
+}
+
+// ResourceSummary describes the planned action for one resource address.
+type ResourceSummary struct {
+	Address       string
+	ModuleAddress string
+	Action        PlanAction
+	// Sensitive reports whether any before or after attribute is sensitive.
+	Sensitive bool
+	// Unknown reports whether any after attribute is only known after apply.
+	Unknown bool
+}
+
+// ParsePlan decodes a Terraform plan in the `terraform show -json` format.
+func ParsePlan(data []byte) (*Plan, error) {
+	plan := &Plan{}
+	if err := json.Unmarshal(data, plan); err != nil {
+		return nil, fmt.Errorf("decoding terraform plan: %w", err)
+	}
+	if plan.FormatVersion == "" {
+		return nil, fmt.Errorf("decoding terraform plan: missing format_version")
+	}
+	return plan, nil
+}
+
+// Action returns the normalized action for the resource change.
+func (rc *ResourceChange) Action() PlanAction {
+	if rc.Change == nil {
+		return PlanActionNoOp
+	}
+	return actionFromList(rc.Change.Actions)
+}
+
+// Action returns the normalized action for the change.
+func (c *PlanChange) Action() PlanAction {
+	return actionFromList(c.Actions)
+}
+
+// actionFromList maps Terraform's action list to a single PlanAction.
+func actionFromList(actions []string) PlanAction {
+	switch len(actions) {
+	case 1:
+		switch PlanAction(actions[0]) {
+		case PlanActionCreate, PlanActionRead, PlanActionUpdate, PlanActionDelete:
+			return PlanAction(actions[0])
+		}
+	case 2:
+		if (actions[0] == "delete" && actions[1] == "create") || (actions[0] == "create" && actions[1] == "delete") {
+			return PlanActionReplace
+		}
+	}
+	return PlanActionNoOp
+}
+
+// HasChanges reports whether the plan changes any resource or output.
+func (p *Plan) HasChanges() bool {
+	for _, rc := range p.ResourceChanges {
+		if a := rc.Action(); a != PlanActionNoOp && a != PlanActionRead {
+			return true
+		}
+	}
+	for _, oc := range p.OutputChanges {
+		if oc.Action() != PlanActionNoOp {
+			return true
+		}
+	}
+	return false
+}
+
+// Summary counts the plan's resource changes and lists the action planned for
+// each changed address, in plan order. No-op changes are omitted.
+func (p *Plan) Summary() PlanSummary {
+	var s PlanSummary
+	for _, rc := range p.ResourceChanges {
+		action := rc.Action()
+		switch action {
+		case PlanActionNoOp:
+			continue
+		case PlanActionCreate:
+			s.Add++
+		case PlanActionUpdate:
+			s.Change++
+		case PlanActionDelete:
+			s.Destroy++
+		case PlanActionReplace:
+			s.Add++
+			s.Destroy++
+			s.Replace++
+		}
+
+		rs := ResourceSummary{
+			Address:       rc.Address,
+			ModuleAddress: rc.ModuleAddress,
+			Action:        action,
+		}
+		if rc.Change != nil {
+			rs.Sensitive = containsTrue(rc.Change.BeforeSensitive) || containsTrue(rc.Change.AfterSensitive)
+			rs.Unknown = containsTrue(rc.Change.AfterUnknown)
+		}
+		s.Resources = append(s.Resources, rs)
+	}
+	return s
+}
+
+// containsTrue reports whether a sensitivity or unknown marker value holds a
+// true leaf anywhere in its structure.
+func containsTrue(v interface{}) bool {
+	switch t := v.(type) {
+	case bool:
+		return t
+	case map[string]interface{}:
+		for _, e := range t {
+			if containsTrue(e) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, e := range t {
+			if containsTrue(e) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Plan downloads and parses the Terraform plan stored for a job. The plan
+// referenced by the job's TerraformPlan attribute must be in the
+// `terraform show -json` format.
+// It returns a *ValidationError if orgID or id is empty, ErrNoPlan if the job
+// has no stored plan, and a *APIError on server errors.
+func (s *JobService) Plan(ctx context.Context, orgID, id string) (*Plan, error) {
+	job, err := s.Get(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.planFor(ctx, job)
+}
+
+// planFor downloads and parses the plan referenced by a fetched job.
+func (s *JobService) planFor(ctx context.Context, job *Job) (*Plan, error) {
+	if job.TerraformPlan == nil || *job.TerraformPlan == "" {
+		return nil, fmt.Errorf("job %s: %w", job.ID, ErrNoPlan)
+	}
+
+	data, err := s.client.download(ctx, *job.TerraformPlan)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePlan(data)
+}
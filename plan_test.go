@@ -0,0 +1,248 @@
+package terrakube_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	terrakube "github.com/terrakube-io/terrakube-go"
+	"github.com/terrakube-io/terrakube-go/testutil"
+)
+
+const testPlanJSON = `{
+  "format_version": "1.2",
+  "terraform_version": "1.7.5",
+  "resource_changes": [
+    {
+      "address": "aws_s3_bucket.logs",
+      "mode": "managed",
+      "type": "aws_s3_bucket",
+      "name": "logs",
+      "provider_name": "registry.terraform.io/hashicorp/aws",
+      "change": {
+        "actions": ["create"],
+        "before": null,
+        "after": {"bucket": "logs"},
+        "after_unknown": {"arn": true, "id": true},
+        "before_sensitive": false,
+        "after_sensitive": {}
+      }
+    },
+    {
+      "address": "module.db.aws_db_instance.main",
+      "module_address": "module.db",
+      "mode": "managed",
+      "type": "aws_db_instance",
+      "name": "main",
+      "provider_name": "registry.terraform.io/hashicorp/aws",
+      "change": {
+        "actions": ["update"],
+        "before": {"password": "old"},
+        "after": {"password": "new"},
+        "after_unknown": {},
+        "before_sensitive": {"password": true},
+        "after_sensitive": {"password": true}
+      }
+    },
+    {
+      "address": "aws_instance.web[0]",
+      "mode": "managed",
+      "type": "aws_instance",
+      "name": "web",
+      "index": 0,
+      "provider_name": "registry.terraform.io/hashicorp/aws",
+      "action_reason": "replace_because_cannot_update",
+      "change": {
+        "actions": ["delete", "create"],
+        "before": {"ami": "ami-1"},
+        "after": {"ami": "ami-2"},
+        "after_unknown": {"tags": [false, true]},
+        "before_sensitive": {},
+        "after_sensitive": {}
+      }
+    },
+    {
+      "address": "aws_iam_role.old",
+      "mode": "managed",
+      "type": "aws_iam_role",
+      "name": "old",
+      "provider_name": "registry.terraform.io/hashicorp/aws",
+      "change": {"actions": ["delete"], "before": {"name": "old"}, "after": null}
+    },
+    {
+      "address": "aws_vpc.main",
+      "mode": "managed",
+      "type": "aws_vpc",
+      "name": "main",
+      "provider_name": "registry.terraform.io/hashicorp/aws",
+      "change": {"actions": ["no-op"], "before": {}, "after": {}}
+    }
+  ],
+  "output_changes": {
+    "bucket": {"actions": ["create"], "before": null, "after": "logs"}
+  }
+}`
+
+func TestParsePlan_Summary(t *testing.T) {
+	t.Parallel()
+
+	plan, err := terrakube.ParsePlan([]byte(testPlanJSON))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if plan.TerraformVersion != "1.7.5" {
+		t.Errorf("TerraformVersion = %q, want %q", plan.TerraformVersion, "1.7.5")
+	}
+	if !plan.HasChanges() {
+		t.Error("HasChanges() = false, want true")
+	}
+
+	s := plan.Summary()
+	if s.Add != 2 || s.Change != 1 || s.Destroy != 2 || s.Replace != 1 {
+		t.Errorf("Summary = %d add, %d change, %d destroy, %d replace; want 2, 1, 2, 1", s.Add, s.Change, s.Destroy, s.Replace)
+	}
+
+	want := []terrakube.ResourceSummary{
+		{Address: "aws_s3_bucket.logs", Action: terrakube.PlanActionCreate, Unknown: true},
+		{Address: "module.db.aws_db_instance.main", ModuleAddress: "module.db", Action: terrakube.PlanActionUpdate, Sensitive: true},
+		{Address: "aws_instance.web[0]", Action: terrakube.PlanActionReplace, Unknown: true},
+		{Address: "aws_iam_role.old", Action: terrakube.PlanActionDelete},
+	}
+	if len(s.Resources) != len(want) {
+		t.Fatalf("got %d resources, want %d", len(s.Resources), len(want))
+	}
+	for i, w := range want {
+		if s.Resources[i] != w {
+			t.Errorf("Resources[%d] = %+v, want %+v", i, s.Resources[i], w)
+		}
+	}
+}
+
+func TestParsePlan_Invalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"not json", "terraform plan binary"},
+		{"missing format version", `{"resource_changes": []}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := terrakube.ParsePlan([]byte(tt.data)); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestPlan_NoChanges(t *testing.T) {
+	t.Parallel()
+
+	plan, err := terrakube.ParsePlan([]byte(`{"format_version":"1.2","resource_changes":[{"address":"a.b","change":{"actions":["no-op"]}}]}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if plan.HasChanges() {
+		t.Error("HasChanges() = true, want false")
+	}
+	if s := plan.Summary(); len(s.Resources) != 0 {
+		t.Errorf("got %d resources, want 0", len(s.Resources))
+	}
+}
+
+func TestJobService_Plan(t *testing.T) {
+	t.Parallel()
+
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("GET /api/v1/organization/org-1/job/job-1", func(w http.ResponseWriter, _ *http.Request) {
+		job := newTestJob()
+		ref := "/tfoutput/v1/organization/org-1/job/job-1/plan.json"
+		job.TerraformPlan = &ref
+		testutil.WriteJSONAPI(t, w, http.StatusOK, job)
+	})
+	srv.HandleFunc("GET /tfoutput/v1/organization/org-1/job/job-1/plan.json", func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "Bearer test-token" {
+			t.Errorf("Authorization = %q, want %q", auth, "Bearer test-token")
+		}
+		_, _ = w.Write([]byte(testPlanJSON))
+	})
+
+	client := newTestClient(t, srv)
+
+	plan, err := client.Jobs.Plan(context.Background(), "org-1", "job-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(plan.ResourceChanges) != 5 {
+		t.Errorf("got %d resource changes, want 5", len(plan.ResourceChanges))
+	}
+}
+
+func TestJobService_Plan_ExternalURL(t *testing.T) {
+	t.Parallel()
+
+	storage := testutil.NewServer(t)
+	storage.HandleFunc("GET /bucket/plan.json", func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			t.Errorf("Authorization = %q, want no token sent to external host", auth)
+		}
+		_, _ = w.Write([]byte(testPlanJSON))
+	})
+
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("GET /api/v1/organization/org-1/job/job-1", func(w http.ResponseWriter, _ *http.Request) {
+		job := newTestJob()
+		ref := storage.URL + "/bucket/plan.json"
+		job.TerraformPlan = &ref
+		testutil.WriteJSONAPI(t, w, http.StatusOK, job)
+	})
+
+	client := newTestClient(t, srv)
+
+	if _, err := client.Jobs.Plan(context.Background(), "org-1", "job-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestJobService_Plan_NoPlan(t *testing.T) {
+	t.Parallel()
+
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("GET /api/v1/organization/org-1/job/job-1", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSONAPI(t, w, http.StatusOK, newTestJob())
+	})
+
+	client := newTestClient(t, srv)
+
+	_, err := client.Jobs.Plan(context.Background(), "org-1", "job-1")
+	if !errors.Is(err, terrakube.ErrNoPlan) {
+		t.Fatalf("error = %v, want %v", err, terrakube.ErrNoPlan)
+	}
+}
+
+func TestJobService_Plan_DownloadError(t *testing.T) {
+	t.Parallel()
+
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("GET /api/v1/organization/org-1/job/job-1", func(w http.ResponseWriter, _ *http.Request) {
+		job := newTestJob()
+		ref := "/tfoutput/missing.json"
+		job.TerraformPlan = &ref
+		testutil.WriteJSONAPI(t, w, http.StatusOK, job)
+	})
+	srv.HandleFunc("GET /tfoutput/missing.json", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	client := newTestClient(t, srv)
+
+	_, err := client.Jobs.Plan(context.Background(), "org-1", "job-1")
+	if !terrakube.IsNotFound(err) {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+}
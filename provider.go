@@ -1,6 +1,9 @@
 package terrakube
 
-import "context"
+import (
+	"context"
+	"fmt"
+)
 
 // Provider represents a Terrakube provider resource within an organization.
 type Provider struct {
@@ -82,3 +85,24 @@ func (s *ProviderService) Delete(ctx context.Context, orgID, id string) error {
 	path := s.client.apiPath("organization", orgID, "provider", id)
 	return s.del(ctx, path)
 }
+
+// ResolveVersion returns the provider's highest version that satisfies a
+// Terraform-style constraint such as "~> 1.2" or ">= 1.0, < 2.0".
+// It returns a *ValidationError if orgID or id is empty, an error wrapping
+// ErrNoMatchingVersion if no version matches, and a *APIError on server errors.
+func (s *ProviderService) ResolveVersion(ctx context.Context, orgID, id, constraint string) (*ProviderVersion, error) {
+	versions, err := s.client.ProviderVersions.List(ctx, orgID, id, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(versions))
+	for i, v := range versions {
+		names[i] = v.VersionNumber
+	}
+	i, err := latestMatching(names, "", constraint)
+	if err != nil {
+		return nil, fmt.Errorf("provider %s: %w", id, err)
+	}
+	return versions[i], nil
+}
@@ -0,0 +1,290 @@
+package terrakube
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// providerArchivePattern matches goreleaser provider archive names such as
+// "terraform-provider-demo_1.2.0_linux_amd64.zip".
+var providerArchivePattern = regexp.MustCompile(`^terraform-provider-(.+)_([^_]+)_([^_]+)_([^_]+)\.zip$`)
+
+// ProviderReleaseOptions configures PublishProviderRelease.
+type ProviderReleaseOptions struct {
+	// Dir is the goreleaser dist directory holding the archives, the
+	// SHA256SUMS file and its .sig, and optionally the registry manifest.
+	// The manifest is any file ending in manifest.json, such as goreleaser's
+	// terraform-provider-<name>_<version>_manifest.json.
+	Dir string
+	// BaseURL is where the release files are hosted. Download URLs are built
+	// by appending each file name.
+	BaseURL string
+	// KeyID and ASCIIArmor identify the public key that signed SHA256SUMS.
+	KeyID      string
+	ASCIIArmor string
+	// Description is set when the provider is created.
+	Description *string
+}
+
+// ProviderRelease is the result of PublishProviderRelease.
+type ProviderRelease struct {
+	Provider        *Provider
+	Version         *ProviderVersion
+	Implementations []*Implementation
+	// Removed lists the implementations of the version that were deleted
+	// because the release no longer has an archive for their platform.
+	Removed []*Implementation
+}
+
+// distRelease is a provider release read from a goreleaser dist directory.
+type distRelease struct {
+	name          string
+	version       string
+	protocols     []string
+	shasumsFile   string
+	signatureFile string
+	archives      []distArchive
+}
+
+type distArchive struct {
+	filename string
+	os       string
+	arch     string
+	shasum   string
+}
+
+// PublishProviderRelease registers a provider release built by goreleaser.
+// It reads the dist directory, checks every archive against SHA256SUMS and
+// the SHA256SUMS signature against the given key, and then creates or
+// updates the Provider, its ProviderVersion, and one Implementation per
+// os/arch. Running it again for the same release updates the existing
+// entries instead of creating duplicates, and implementations for platforms
+// the release no longer has are deleted.
+// It returns a *ValidationError if orgID or a required option is empty or
+// the dist directory is incomplete or has more than one SHA256SUMS file, an
+// error wrapping ErrChecksumMismatch or ErrInvalidSignature if verification
+// fails, and a *APIError on server errors.
+func (c *Client) PublishProviderRelease(ctx context.Context, orgID string, opts ProviderReleaseOptions) (*ProviderRelease, error) {
+	if err := validateID("organization ID", orgID); err != nil {
+		return nil, err
+	}
+	for _, opt := range [][2]string{{"dir", opts.Dir}, {"baseURL", opts.BaseURL}, {"keyID", opts.KeyID}, {"asciiArmor", opts.ASCIIArmor}} {
+		if err := validateID(opt[0], opt[1]); err != nil {
+			return nil, err
+		}
+	}
+
+	rel, err := readDistRelease(opts.Dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := rel.verify(opts); err != nil {
+		return nil, err
+	}
+
+	provider, err := c.ensureProvider(ctx, orgID, rel.name, opts.Description)
+	if err != nil {
+		return nil, err
+	}
+	version, err := c.ensureProviderVersion(ctx, orgID, provider.ID, rel)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := c.Implementations.List(ctx, orgID, provider.ID, version.ID, nil)
+	if err != nil {
+		return nil, err
+	}
+	byPlatform := map[string]*Implementation{}
+	for _, impl := range existing {
+		byPlatform[impl.Os+"_"+impl.Arch] = impl
+	}
+
+	result := &ProviderRelease{Provider: provider, Version: version}
+	base := strings.TrimSuffix(opts.BaseURL, "/") + "/"
+	for _, a := range rel.archives {
+		impl, ok := byPlatform[a.os+"_"+a.arch]
+		delete(byPlatform, a.os+"_"+a.arch)
+		if !ok {
+			impl = &Implementation{Os: a.os, Arch: a.arch}
+		}
+		impl.Filename = a.filename
+		impl.DownloadURL = stringPtr(base + a.filename)
+		impl.ShasumsURL = stringPtr(base + rel.shasumsFile)
+		impl.ShasumsSignatureURL = stringPtr(base + rel.signatureFile)
+		impl.Shasum = stringPtr(a.shasum)
+		impl.KeyID = stringPtr(opts.KeyID)
+		impl.ASCIIArmor = stringPtr(opts.ASCIIArmor)
+
+		if ok {
+			impl, err = c.Implementations.Update(ctx, orgID, provider.ID, version.ID, impl)
+		} else {
+			impl, err = c.Implementations.Create(ctx, orgID, provider.ID, version.ID, impl)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("publishing %s_%s: %w", a.os, a.arch, err)
+		}
+		result.Implementations = append(result.Implementations, impl)
+	}
+
+	platforms := make([]string, 0, len(byPlatform))
+	for platform := range byPlatform {
+		platforms = append(platforms, platform)
+	}
+	sort.Strings(platforms)
+	for _, platform := range platforms {
+		impl := byPlatform[platform]
+		if err := c.Implementations.Delete(ctx, orgID, provider.ID, version.ID, impl.ID); err != nil {
+			return nil, fmt.Errorf("removing %s: %w", platform, err)
+		}
+		result.Removed = append(result.Removed, impl)
+	}
+	return result, nil
+}
+
+func (c *Client) ensureProvider(ctx context.Context, orgID, name string, description *string) (*Provider, error) {
+	providers, err := c.Providers.List(ctx, orgID, &ListOptions{Filter: rsqlEqual("name", name)})
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range providers {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+	return c.Providers.Create(ctx, orgID, &Provider{Name: name, Description: description})
+}
+
+func (c *Client) ensureProviderVersion(ctx context.Context, orgID, providerID string, rel *distRelease) (*ProviderVersion, error) {
+	protocols := strings.Join(rel.protocols, ",")
+
+	versions, err := c.ProviderVersions.List(ctx, orgID, providerID, &ListOptions{Filter: rsqlEqual("versionNumber", rel.version)})
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range versions {
+		if v.VersionNumber != rel.version {
+			continue
+		}
+		if v.Protocols != nil && *v.Protocols == protocols {
+			return v, nil
+		}
+		v.Protocols = &protocols
+		return c.ProviderVersions.Update(ctx, orgID, providerID, v)
+	}
+	return c.ProviderVersions.Create(ctx, orgID, providerID, &ProviderVersion{VersionNumber: rel.version, Protocols: &protocols})
+}
+
+// readDistRelease collects the provider release files in a dist directory.
+func readDistRelease(dir string) (*distRelease, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading dist directory: %w", err)
+	}
+
+	rel := &distRelease{}
+	for _, e := range entries {
+		name := e.Name()
+		switch {
+		case e.IsDir():
+		case strings.HasSuffix(name, "SHA256SUMS"):
+			if rel.shasumsFile != "" {
+				return nil, &ValidationError{Field: "dir", Message: fmt.Sprintf("more than one SHA256SUMS file found in %s: %s and %s", dir, rel.shasumsFile, name)}
+			}
+			rel.shasumsFile = name
+		case strings.HasSuffix(name, "manifest.json"):
+			data, err := os.ReadFile(filepath.Join(dir, name))
+			if err != nil {
+				return nil, err
+			}
+			var manifest struct {
+				Metadata struct {
+					ProtocolVersions []string `json:"protocol_versions"`
+				} `json:"metadata"`
+			}
+			if err := json.Unmarshal(data, &manifest); err != nil {
+				return nil, fmt.Errorf("decoding %s: %w", name, err)
+			}
+			rel.protocols = manifest.Metadata.ProtocolVersions
+		}
+	}
+	if rel.shasumsFile == "" {
+		return nil, &ValidationError{Field: "dir", Message: "no SHA256SUMS file found in " + dir}
+	}
+	rel.signatureFile = rel.shasumsFile + ".sig"
+	if len(rel.protocols) == 0 {
+		// Providers built with the plugin SDK default to protocol 5.
+		rel.protocols = []string{"5.0"}
+	}
+
+	shasums, err := os.ReadFile(filepath.Join(dir, rel.shasumsFile))
+	if err != nil {
+		return nil, err
+	}
+	sums, err := ParseShasums(shasums)
+	if err != nil {
+		return nil, err
+	}
+	for filename, sum := range sums {
+		m := providerArchivePattern.FindStringSubmatch(filename)
+		if m == nil {
+			continue
+		}
+		if rel.name == "" {
+			rel.name, rel.version = m[1], m[2]
+		}
+		if m[1] != rel.name || m[2] != rel.version {
+			return nil, &ValidationError{Field: "dir", Message: fmt.Sprintf("%s does not belong to %s %s", filename, rel.name, rel.version)}
+		}
+		rel.archives = append(rel.archives, distArchive{filename: filename, os: m[3], arch: m[4], shasum: sum})
+	}
+	if len(rel.archives) == 0 {
+		return nil, &ValidationError{Field: "dir", Message: "SHA256SUMS lists no provider archives"}
+	}
+	sort.Slice(rel.archives, func(i, j int) bool { return rel.archives[i].filename < rel.archives[j].filename })
+	return rel, nil
+}
+
+// verify checks the SHA256SUMS signature and every archive's checksum.
+func (rel *distRelease) verify(opts ProviderReleaseOptions) error {
+	shasums, err := os.ReadFile(filepath.Join(opts.Dir, rel.shasumsFile))
+	if err != nil {
+		return err
+	}
+	signature, err := os.ReadFile(filepath.Join(opts.Dir, rel.signatureFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return &ValidationError{Field: "dir", Message: rel.signatureFile + " not found"}
+	}
+	if err != nil {
+		return err
+	}
+	keys := []ProviderSigningKey{{KeyID: opts.KeyID, ASCIIArmor: opts.ASCIIArmor}}
+	if err := verifySignature(keys, shasums, signature); err != nil {
+		return err
+	}
+
+	for _, a := range rel.archives {
+		data, err := os.ReadFile(filepath.Join(opts.Dir, a.filename))
+		if err != nil {
+			return fmt.Errorf("reading archive: %w", err)
+		}
+		sum := sha256.Sum256(data)
+		if got := hex.EncodeToString(sum[:]); !strings.EqualFold(got, a.shasum) {
+			return fmt.Errorf("%w: %s has checksum %s, SHA256SUMS lists %s", ErrChecksumMismatch, a.filename, got, a.shasum)
+		}
+	}
+	return nil
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
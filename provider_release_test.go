@@ -0,0 +1,267 @@
+package terrakube_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	terrakube "github.com/terrakube-io/terrakube-go"
+	"github.com/terrakube-io/terrakube-go/testutil"
+)
+
+// writeDist writes a goreleaser-style dist directory for demo 1.2.0 and
+// returns its path.
+func writeDist(t *testing.T, signer *testSigner) string {
+	t.Helper()
+	dir := t.TempDir()
+	write := func(name string, data []byte) {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o600); err != nil {
+			t.Fatalf("writing %s: %v", name, err)
+		}
+	}
+
+	var sums string
+	for _, platform := range []string{"linux_amd64", "darwin_arm64"} {
+		name := "terraform-provider-demo_1.2.0_" + platform + ".zip"
+		data := []byte("zip for " + platform)
+		write(name, data)
+		sums += sha256Hex(data) + "  " + name + "\n"
+	}
+	manifest := []byte(`{"version": 1, "metadata": {"protocol_versions": ["6.0"]}}`)
+	write("terraform-provider-demo_1.2.0_manifest.json", manifest)
+	sums += sha256Hex(manifest) + "  terraform-provider-demo_1.2.0_manifest.json\n"
+
+	write("terraform-provider-demo_1.2.0_SHA256SUMS", []byte(sums))
+	write("terraform-provider-demo_1.2.0_SHA256SUMS.sig", signer.sign(t, []byte(sums)))
+	write("metadata.json", []byte(`{}`))
+	return dir
+}
+
+// fakeProviderRegistry stores providers, versions, and implementations in memory.
+type fakeProviderRegistry struct {
+	mu       sync.Mutex
+	provider *terrakube.Provider
+	version  *terrakube.ProviderVersion
+	impls    map[string]*terrakube.Implementation
+	creates  int
+	updates  int
+	deletes  int
+}
+
+func newFakeProviderRegistry(t *testing.T) (*fakeProviderRegistry, *testutil.Server) {
+	t.Helper()
+	f := &fakeProviderRegistry{impls: map[string]*terrakube.Implementation{}}
+	srv := testutil.NewServer(t)
+	base := "/api/v1/organization/org-1/provider"
+
+	srv.HandleFunc("GET "+base, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("filter[provider]"); got != `name=="demo"` {
+			t.Errorf("provider filter = %q", got)
+		}
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		list := []*terrakube.Provider{}
+		if f.provider != nil {
+			list = append(list, f.provider)
+		}
+		testutil.WriteJSONAPIList(t, w, http.StatusOK, list)
+	})
+	srv.HandleFunc("POST "+base, func(w http.ResponseWriter, r *http.Request) {
+		attrs := decodeAttributes(t, r)
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.creates++
+		f.provider = &terrakube.Provider{ID: "prov-1", Name: attrs["name"].(string)}
+		testutil.WriteJSONAPI(t, w, http.StatusCreated, f.provider)
+	})
+	srv.HandleFunc("GET "+base+"/prov-1/version", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("filter[version]"); got != `versionNumber=="1.2.0"` {
+			t.Errorf("version filter = %q", got)
+		}
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		list := []*terrakube.ProviderVersion{}
+		if f.version != nil {
+			list = append(list, f.version)
+		}
+		testutil.WriteJSONAPIList(t, w, http.StatusOK, list)
+	})
+	srv.HandleFunc("POST "+base+"/prov-1/version", func(w http.ResponseWriter, r *http.Request) {
+		attrs := decodeAttributes(t, r)
+		protocols := attrs["protocols"].(string)
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.creates++
+		f.version = &terrakube.ProviderVersion{ID: "ver-1", VersionNumber: attrs["versionNumber"].(string), Protocols: &protocols}
+		testutil.WriteJSONAPI(t, w, http.StatusCreated, f.version)
+	})
+	srv.HandleFunc("GET "+base+"/prov-1/version/ver-1/implementation", func(w http.ResponseWriter, _ *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		list := []*terrakube.Implementation{}
+		for _, impl := range f.impls {
+			list = append(list, impl)
+		}
+		testutil.WriteJSONAPIList(t, w, http.StatusOK, list)
+	})
+	store := func(w http.ResponseWriter, r *http.Request, id string, status int) {
+		attrs := decodeAttributes(t, r)
+		str := func(k string) *string { s, _ := attrs[k].(string); return &s }
+		impl := &terrakube.Implementation{
+			ID:                  id,
+			Os:                  attrs["os"].(string),
+			Arch:                attrs["arch"].(string),
+			Filename:            attrs["filename"].(string),
+			DownloadURL:         str("downloadUrl"),
+			ShasumsURL:          str("shasumsUrl"),
+			ShasumsSignatureURL: str("shasumsSignatureUrl"),
+			Shasum:              str("shasum"),
+			KeyID:               str("keyId"),
+			ASCIIArmor:          str("asciiArmor"),
+		}
+		f.mu.Lock()
+		f.impls[id] = impl
+		f.mu.Unlock()
+		testutil.WriteJSONAPI(t, w, status, impl)
+	}
+	srv.HandleFunc("POST "+base+"/prov-1/version/ver-1/implementation", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		f.creates++
+		id := "impl-" + string(rune('0'+len(f.impls)))
+		f.mu.Unlock()
+		store(w, r, id, http.StatusCreated)
+	})
+	srv.HandleFunc("PATCH "+base+"/prov-1/version/ver-1/implementation/{id}", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		f.updates++
+		f.mu.Unlock()
+		store(w, r, r.PathValue("id"), http.StatusOK)
+	})
+	srv.HandleFunc("DELETE "+base+"/prov-1/version/ver-1/implementation/{id}", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		f.deletes++
+		delete(f.impls, r.PathValue("id"))
+		f.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	})
+	return f, srv
+}
+
+func TestClient_PublishProviderRelease(t *testing.T) {
+	t.Parallel()
+
+	signer := newTestSigner(t)
+	dir := writeDist(t, signer)
+	f, srv := newFakeProviderRegistry(t)
+	client := newTestClient(t, srv)
+
+	opts := terrakube.ProviderReleaseOptions{
+		Dir:        dir,
+		BaseURL:    "https://releases.example.com/demo/v1.2.0/",
+		KeyID:      signer.entity.PrimaryKey.KeyIdString(),
+		ASCIIArmor: signer.armor,
+	}
+
+	rel, err := client.PublishProviderRelease(context.Background(), "org-1", opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rel.Provider.Name != "demo" || rel.Version.VersionNumber != "1.2.0" || *rel.Version.Protocols != "6.0" {
+		t.Errorf("release = %+v / %+v", rel.Provider, rel.Version)
+	}
+	if len(rel.Implementations) != 2 {
+		t.Fatalf("got %d implementations, want 2", len(rel.Implementations))
+	}
+
+	impl := rel.Implementations[1]
+	if impl.Os != "linux" || impl.Arch != "amd64" {
+		t.Fatalf("implementation = %s_%s, want linux_amd64", impl.Os, impl.Arch)
+	}
+	if *impl.DownloadURL != "https://releases.example.com/demo/v1.2.0/terraform-provider-demo_1.2.0_linux_amd64.zip" {
+		t.Errorf("DownloadURL = %q", *impl.DownloadURL)
+	}
+	if *impl.ShasumsSignatureURL != "https://releases.example.com/demo/v1.2.0/terraform-provider-demo_1.2.0_SHA256SUMS.sig" {
+		t.Errorf("ShasumsSignatureURL = %q", *impl.ShasumsSignatureURL)
+	}
+	if *impl.Shasum != sha256Hex([]byte("zip for linux_amd64")) || *impl.ASCIIArmor != signer.armor {
+		t.Errorf("Shasum = %q or ASCIIArmor not set", *impl.Shasum)
+	}
+	if f.creates != 4 || f.updates != 0 {
+		t.Errorf("creates, updates = %d, %d; want 4, 0", f.creates, f.updates)
+	}
+
+	// A platform dropped from the release is removed on republish.
+	f.mu.Lock()
+	f.impls["impl-old"] = &terrakube.Implementation{ID: "impl-old", Os: "windows", Arch: "386"}
+	f.mu.Unlock()
+
+	rel, err = client.PublishProviderRelease(context.Background(), "org-1", opts)
+	if err != nil {
+		t.Fatalf("republish: unexpected error: %v", err)
+	}
+	if f.creates != 4 || f.updates != 2 || f.deletes != 1 || len(f.impls) != 2 {
+		t.Errorf("after republish creates, updates, deletes, impls = %d, %d, %d, %d; want 4, 2, 1, 2", f.creates, f.updates, f.deletes, len(f.impls))
+	}
+	if len(rel.Removed) != 1 || rel.Removed[0].ID != "impl-old" {
+		t.Errorf("Removed = %+v, want impl-old", rel.Removed)
+	}
+}
+
+func TestClient_PublishProviderRelease_Verification(t *testing.T) {
+	t.Parallel()
+
+	signer := newTestSigner(t)
+	client := newTestClientFromURL(t, "https://example.com")
+
+	t.Run("tampered archive", func(t *testing.T) {
+		t.Parallel()
+		dir := writeDist(t, signer)
+		if err := os.WriteFile(filepath.Join(dir, "terraform-provider-demo_1.2.0_linux_amd64.zip"), []byte("evil"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		_, err := client.PublishProviderRelease(context.Background(), "org-1", terrakube.ProviderReleaseOptions{
+			Dir: dir, BaseURL: "https://example.com", KeyID: "k", ASCIIArmor: signer.armor,
+		})
+		if !errors.Is(err, terrakube.ErrChecksumMismatch) {
+			t.Errorf("error = %v, want %v", err, terrakube.ErrChecksumMismatch)
+		}
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		t.Parallel()
+		dir := writeDist(t, signer)
+		_, err := client.PublishProviderRelease(context.Background(), "org-1", terrakube.ProviderReleaseOptions{
+			Dir: dir, BaseURL: "https://example.com", KeyID: "k", ASCIIArmor: newTestSigner(t).armor,
+		})
+		if !errors.Is(err, terrakube.ErrInvalidSignature) {
+			t.Errorf("error = %v, want %v", err, terrakube.ErrInvalidSignature)
+		}
+	})
+
+	t.Run("two SHA256SUMS files", func(t *testing.T) {
+		t.Parallel()
+		dir := writeDist(t, signer)
+		if err := os.WriteFile(filepath.Join(dir, "terraform-provider-demo_1.1.0_SHA256SUMS"), []byte("old"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		_, err := client.PublishProviderRelease(context.Background(), "org-1", terrakube.ProviderReleaseOptions{
+			Dir: dir, BaseURL: "https://example.com", KeyID: "k", ASCIIArmor: signer.armor,
+		})
+		var ve *terrakube.ValidationError
+		if !errors.As(err, &ve) || ve.Field != "dir" {
+			t.Errorf("expected a dir *ValidationError, got %v", err)
+		}
+	})
+
+	t.Run("missing options", func(t *testing.T) {
+		t.Parallel()
+		_, err := client.PublishProviderRelease(context.Background(), "org-1", terrakube.ProviderReleaseOptions{Dir: t.TempDir()})
+		assertValidationError(t, err, "baseURL")
+		_, err = client.PublishProviderRelease(context.Background(), "", terrakube.ProviderReleaseOptions{})
+		assertValidationError(t, err, "organization ID")
+	})
+}
@@ -321,3 +321,35 @@ func TestProviderService_AuthHeader(t *testing.T) {
 	client := newTestClient(t, srv)
 	_, _ = client.Providers.Get(context.Background(), "org-1", "prov-1")
 }
+
+func TestProviderService_ResolveVersion(t *testing.T) {
+	t.Parallel()
+
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("GET /api/v1/organization/org-1/provider/prov-1/version", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSONAPIList(t, w, http.StatusOK, []*terrakube.ProviderVersion{
+			{ID: "ver-1", VersionNumber: "1.0.0"},
+			{ID: "ver-2", VersionNumber: "1.9.0"},
+			{ID: "ver-3", VersionNumber: "2.0.0"},
+		})
+	})
+
+	client := newTestClient(t, srv)
+
+	v, err := client.Providers.ResolveVersion(context.Background(), "org-1", "prov-1", ">= 1.0, < 2.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v.ID != "ver-2" {
+		t.Errorf("resolved %s (%s), want ver-2", v.ID, v.VersionNumber)
+	}
+}
+
+func TestProviderService_ResolveVersion_EmptyIDs(t *testing.T) {
+	t.Parallel()
+
+	client := newTestClientFromURL(t, "https://example.com")
+
+	_, err := client.Providers.ResolveVersion(context.Background(), "org-1", "", "~> 1.0")
+	assertValidationError(t, err, "provider ID")
+}
@@ -0,0 +1,373 @@
+package terrakube
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// discoveryPath is where a Terraform host publishes its service discovery document.
+const discoveryPath = "/.well-known/terraform.json"
+
+// Service identifiers used in the discovery document.
+const (
+	ServiceModulesV1   = "modules.v1"
+	ServiceProvidersV1 = "providers.v1"
+)
+
+// RegistryService implements the Terraform registry protocols that Terrakube
+// serves alongside its API, as used by terraform init.
+type RegistryService struct {
+	client *Client
+
+	mu       sync.Mutex
+	services map[string]string
+}
+
+// Discover returns the host's service discovery document, mapping service
+// identifiers such as "modules.v1" to base URLs. The result is cached, and
+// each call returns its own copy.
+// It returns a *APIError on server errors.
+func (s *RegistryService) Discover(ctx context.Context) (map[string]string, error) {
+	services, err := s.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return maps.Clone(services), nil
+}
+
+// discover returns the cached discovery document, downloading it on first
+// use. The lock is not held during the download, so a slow or cancelled
+// request does not block other callers; concurrent first calls may each
+// download the document.
+func (s *RegistryService) discover(ctx context.Context) (map[string]string, error) {
+	s.mu.Lock()
+	cached := s.services
+	s.mu.Unlock()
+	if cached != nil {
+		return cached, nil
+	}
+
+	data, err := s.client.download(ctx, discoveryPath)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("decoding service discovery document: %w", err)
+	}
+
+	services := map[string]string{}
+	for id, v := range raw {
+		// login.v1 and similar services are objects rather than URLs.
+		if u, ok := v.(string); ok {
+			services[id] = u
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.services == nil {
+		s.services = services
+	}
+	return s.services, nil
+}
+
+// serviceURL resolves a path under a discovered service's base URL.
+func (s *RegistryService) serviceURL(ctx context.Context, service string, segments ...string) (string, error) {
+	services, err := s.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	base, ok := services[service]
+	if !ok {
+		return "", fmt.Errorf("registry does not support %s", service)
+	}
+	if !strings.HasSuffix(base, "/") {
+		base += "/"
+	}
+	rel := make([]string, len(segments))
+	for i, seg := range segments {
+		rel[i] = url.PathEscape(seg)
+	}
+	return base + strings.Join(rel, "/"), nil
+}
+
+// moduleAddress splits a module address of the form "namespace/name/provider".
+func moduleAddress(addr string) ([]string, error) {
+	parts := strings.Split(addr, "/")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return nil, &ValidationError{Field: "module", Message: fmt.Sprintf("%q is not of the form namespace/name/provider", addr)}
+	}
+	return parts, nil
+}
+
+// ModuleVersions lists the available versions of a module, addressed as
+// "namespace/name/provider" where the namespace is the organization name.
+// It returns a *ValidationError if the address is malformed and a *APIError on server errors.
+func (s *RegistryService) ModuleVersions(ctx context.Context, module string) ([]string, error) {
+	parts, err := moduleAddress(module)
+	if err != nil {
+		return nil, err
+	}
+	ref, err := s.serviceURL(ctx, ServiceModulesV1, append(parts, "versions")...)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := s.client.download(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Modules []struct {
+			Versions []struct {
+				Version string `json:"version"`
+			} `json:"versions"`
+		} `json:"modules"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("decoding module versions: %w", err)
+	}
+
+	var versions []string
+	for _, m := range resp.Modules {
+		for _, v := range m.Versions {
+			versions = append(versions, v.Version)
+		}
+	}
+	return versions, nil
+}
+
+// ResolveModuleVersion returns the highest available module version that
+// satisfies a Terraform-style constraint such as "~> 1.2".
+// It returns a *ValidationError if the address is malformed, an error wrapping
+// ErrNoMatchingVersion if no version matches, and a *APIError on server errors.
+func (s *RegistryService) ResolveModuleVersion(ctx context.Context, module, constraint string) (string, error) {
+	versions, err := s.ModuleVersions(ctx, module)
+	if err != nil {
+		return "", err
+	}
+	v, err := LatestMatching(versions, constraint)
+	if err != nil {
+		return "", fmt.Errorf("module %s: %w", module, err)
+	}
+	return v, nil
+}
+
+// ModuleDownloadURL returns the source address of a module version, taken
+// from the X-Terraform-Get header. Relative addresses are resolved against
+// the download endpoint.
+// It returns a *ValidationError if the address or version is empty or
+// malformed and a *APIError on server errors.
+func (s *RegistryService) ModuleDownloadURL(ctx context.Context, module, version string) (string, error) {
+	parts, err := moduleAddress(module)
+	if err != nil {
+		return "", err
+	}
+	if err := validateID("version", version); err != nil {
+		return "", err
+	}
+	ref, err := s.serviceURL(ctx, ServiceModulesV1, append(parts, version, "download")...)
+	if err != nil {
+		return "", err
+	}
+
+	endpoint, err := s.client.baseURL.Parse(ref)
+	if err != nil {
+		return "", fmt.Errorf("invalid download endpoint %q: %w", ref, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close() //nolint:errcheck // response body close errors are inconsequential
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return "", &APIError{StatusCode: resp.StatusCode, Method: req.Method, Path: req.URL.Path, Body: body}
+	}
+
+	src := resp.Header.Get("X-Terraform-Get")
+	if src == "" {
+		return "", fmt.Errorf("module %s %s: response has no X-Terraform-Get header", module, version)
+	}
+	if strings.Contains(src, "::") {
+		// Forced go-getter sources (git::, s3::, ...) are returned as-is.
+		return src, nil
+	}
+	u, err := endpoint.Parse(src)
+	if err != nil {
+		return "", fmt.Errorf("invalid module source %q: %w", src, err)
+	}
+	return u.String(), nil
+}
+
+// DownloadModule downloads a module version's archive and extracts it into
+// dir, which is created if needed. Zip and gzipped tar archives served over
+// HTTP(S) are supported; forced go-getter sources such as "git::" are not.
+// It returns a *ValidationError if the address or version is empty or
+// malformed and a *APIError on server errors.
+func (s *RegistryService) DownloadModule(ctx context.Context, module, version, dir string) error {
+	src, err := s.ModuleDownloadURL(ctx, module, version)
+	if err != nil {
+		return err
+	}
+	if strings.Contains(src, "::") {
+		return fmt.Errorf("module %s %s: unsupported source %q", module, version, src)
+	}
+
+	// A "//subdir" suffix selects a directory within the archive.
+	src, subdir := splitSubdir(src)
+	data, err := s.client.download(ctx, src)
+	if err != nil {
+		return err
+	}
+	return extractArchive(data, dir, subdir)
+}
+
+// splitSubdir splits a go-getter style "//subdir" suffix from a source URL.
+func splitSubdir(src string) (string, string) {
+	u, err := url.Parse(src)
+	if err != nil {
+		return src, ""
+	}
+	i := strings.Index(u.Path, "//")
+	if i < 0 {
+		return src, ""
+	}
+	subdir := strings.Trim(u.Path[i+2:], "/")
+	u.Path = u.Path[:i]
+	return u.String(), subdir
+}
+
+// extractArchive unpacks a zip or gzipped tar archive into dir, keeping only
+// entries under subdir when it is not empty.
+func extractArchive(data []byte, dir, subdir string) error {
+	switch {
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")):
+		return extractZip(data, dir, subdir)
+	case bytes.HasPrefix(data, []byte{0x1f, 0x8b}):
+		return extractTarGz(data, dir, subdir)
+	}
+	return fmt.Errorf("unsupported module archive format")
+}
+
+func extractZip(data []byte, dir, subdir string) error {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return fmt.Errorf("reading zip archive: %w", err)
+	}
+	for _, f := range zr.File {
+		target, ok, err := archiveTarget(dir, subdir, f.Name)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		if f.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", f.Name, err)
+		}
+		err = writeArchiveFile(target, rc, f.Mode())
+		rc.Close() //nolint:errcheck,gosec // read-only zip entry
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func extractTarGz(data []byte, dir, subdir string) error {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("reading gzip archive: %w", err)
+	}
+	defer gz.Close() //nolint:errcheck // read-only stream
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading tar archive: %w", err)
+		}
+		target, ok, err := archiveTarget(dir, subdir, hdr.Name)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := writeArchiveFile(target, tr, hdr.FileInfo().Mode()); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// archiveTarget maps an archive entry name to a path under dir. It reports
+// false for entries outside subdir and fails for entries escaping dir.
+func archiveTarget(dir, subdir, name string) (string, bool, error) {
+	name = filepath.ToSlash(filepath.Clean(filepath.FromSlash(name)))
+	if subdir != "" {
+		if name != subdir && !strings.HasPrefix(name, subdir+"/") {
+			return "", false, nil
+		}
+		name = strings.TrimPrefix(strings.TrimPrefix(name, subdir), "/")
+		if name == "" {
+			return "", false, nil
+		}
+	}
+	if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+		return "", false, fmt.Errorf("archive entry %q escapes the target directory", name)
+	}
+	return filepath.Join(dir, filepath.FromSlash(name)), true, nil
+}
+
+func writeArchiveFile(target string, r io.Reader, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	perm := mode.Perm() | 0o600
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil { //nolint:gosec // archives come from the configured registry
+		f.Close() //nolint:errcheck,gosec // already failing
+		return fmt.Errorf("writing %s: %w", target, err)
+	}
+	return f.Close()
+}
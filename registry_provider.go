@@ -0,0 +1,237 @@
+package terrakube
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+)
+
+// Sentinel errors returned by provider package verification.
+var (
+	// ErrChecksumMismatch indicates a provider package does not match its published checksum.
+	ErrChecksumMismatch = errors.New("provider package checksum mismatch")
+	// ErrInvalidSignature indicates a SHA256SUMS file is not signed by any of the published keys.
+	ErrInvalidSignature = errors.New("provider checksums signature is not valid")
+)
+
+// ProviderPlatform is an os/arch pair a provider version is built for.
+type ProviderPlatform struct {
+	OS   string `json:"os"`
+	Arch string `json:"arch"`
+}
+
+// ProviderVersionInfo is a provider version as listed by the providers.v1 protocol.
+type ProviderVersionInfo struct {
+	Version   string             `json:"version"`
+	Protocols []string           `json:"protocols"`
+	Platforms []ProviderPlatform `json:"platforms"`
+}
+
+// ProviderSigningKey is a public key that signs a provider's SHA256SUMS file.
+type ProviderSigningKey struct {
+	KeyID          string `json:"key_id"`
+	ASCIIArmor     string `json:"ascii_armor"`
+	TrustSignature string `json:"trust_signature,omitempty"`
+	Source         string `json:"source,omitempty"`
+	SourceURL      string `json:"source_url,omitempty"`
+}
+
+// ProviderPackage describes the download of a provider version for one platform.
+type ProviderPackage struct {
+	Protocols           []string `json:"protocols"`
+	OS                  string   `json:"os"`
+	Arch                string   `json:"arch"`
+	Filename            string   `json:"filename"`
+	DownloadURL         string   `json:"download_url"`
+	ShasumsURL          string   `json:"shasums_url"`
+	ShasumsSignatureURL string   `json:"shasums_signature_url"`
+	Shasum              string   `json:"shasum"`
+	SigningKeys         struct {
+		GPGPublicKeys []ProviderSigningKey `json:"gpg_public_keys"`
+	} `json:"signing_keys"`
+}
+
+// providerAddress splits a provider address of the form "namespace/type".
+func providerAddress(addr string) ([]string, error) {
+	parts := strings.Split(addr, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, &ValidationError{Field: "provider", Message: fmt.Sprintf("%q is not of the form namespace/type", addr)}
+	}
+	return parts, nil
+}
+
+// ProviderVersions lists the available versions of a provider, addressed as
+// "namespace/type" where the namespace is the organization name.
+// It returns a *ValidationError if the address is malformed and a *APIError on server errors.
+func (s *RegistryService) ProviderVersions(ctx context.Context, provider string) ([]*ProviderVersionInfo, error) {
+	parts, err := providerAddress(provider)
+	if err != nil {
+		return nil, err
+	}
+	ref, err := s.serviceURL(ctx, ServiceProvidersV1, append(parts, "versions")...)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := s.client.download(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Versions []*ProviderVersionInfo `json:"versions"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("decoding provider versions: %w", err)
+	}
+	return resp.Versions, nil
+}
+
+// ProviderPackage returns the download metadata of a provider version for
+// the given platform.
+// It returns a *ValidationError if any argument is empty or the address is
+// malformed and a *APIError on server errors.
+func (s *RegistryService) ProviderPackage(ctx context.Context, provider, version, osName, arch string) (*ProviderPackage, error) {
+	parts, err := providerAddress(provider)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateID("version", version); err != nil {
+		return nil, err
+	}
+	if err := validateID("os", osName); err != nil {
+		return nil, err
+	}
+	if err := validateID("arch", arch); err != nil {
+		return nil, err
+	}
+	ref, err := s.serviceURL(ctx, ServiceProvidersV1, append(parts, version, "download", osName, arch)...)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := s.client.download(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	pkg := &ProviderPackage{}
+	if err := json.Unmarshal(data, pkg); err != nil {
+		return nil, fmt.Errorf("decoding provider package: %w", err)
+	}
+	return pkg, nil
+}
+
+// DownloadProvider downloads a provider package and verifies it the way
+// terraform init does: the SHA256SUMS file must be signed by one of the
+// published keys, and the package must match its entry in that file.
+// It returns the package metadata and the zip archive.
+// It returns a *ValidationError if any argument is empty or the address is
+// malformed, an error wrapping ErrInvalidSignature or ErrChecksumMismatch if
+// verification fails, and a *APIError on server errors.
+func (s *RegistryService) DownloadProvider(ctx context.Context, provider, version, osName, arch string) (*ProviderPackage, []byte, error) {
+	pkg, err := s.ProviderPackage(ctx, provider, version, osName, arch)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	shasums, err := s.client.download(ctx, pkg.ShasumsURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	signature, err := s.client.download(ctx, pkg.ShasumsSignatureURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	archive, err := s.client.download(ctx, pkg.DownloadURL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := VerifyProviderPackage(pkg, shasums, signature, archive); err != nil {
+		return nil, nil, fmt.Errorf("provider %s %s %s_%s: %w", provider, version, osName, arch, err)
+	}
+	return pkg, archive, nil
+}
+
+// VerifyProviderPackage checks that signature is a valid detached signature
+// of shasums by one of pkg's signing keys, and that archive matches both the
+// shasums entry for pkg.Filename and pkg.Shasum.
+// It returns an error wrapping ErrInvalidSignature or ErrChecksumMismatch.
+func VerifyProviderPackage(pkg *ProviderPackage, shasums, signature, archive []byte) error {
+	if err := verifySignature(pkg.SigningKeys.GPGPublicKeys, shasums, signature); err != nil {
+		return err
+	}
+
+	sums, err := ParseShasums(shasums)
+	if err != nil {
+		return err
+	}
+	want, ok := sums[pkg.Filename]
+	if !ok {
+		return fmt.Errorf("%w: %s is not listed in SHA256SUMS", ErrChecksumMismatch, pkg.Filename)
+	}
+	if pkg.Shasum != "" && !strings.EqualFold(pkg.Shasum, want) {
+		return fmt.Errorf("%w: registry reports %s, SHA256SUMS lists %s", ErrChecksumMismatch, pkg.Shasum, want)
+	}
+
+	sum := sha256.Sum256(archive)
+	if got := hex.EncodeToString(sum[:]); !strings.EqualFold(got, want) {
+		return fmt.Errorf("%w: %s has checksum %s, want %s", ErrChecksumMismatch, pkg.Filename, got, want)
+	}
+	return nil
+}
+
+// ParseShasums parses a SHA256SUMS file into a map from file name to
+// hex-encoded checksum.
+func ParseShasums(data []byte) (map[string]string, error) {
+	sums := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid SHA256SUMS line %q", line)
+		}
+		// sha256sum marks binary-mode entries with a leading "*".
+		sums[strings.TrimPrefix(fields[1], "*")] = fields[0]
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading SHA256SUMS: %w", err)
+	}
+	return sums, nil
+}
+
+// verifySignature checks a binary or armored detached signature against the
+// given ASCII-armored public keys.
+func verifySignature(keys []ProviderSigningKey, signed, signature []byte) error {
+	var keyring openpgp.EntityList
+	for _, k := range keys {
+		entities, err := openpgp.ReadArmoredKeyRing(strings.NewReader(k.ASCIIArmor))
+		if err != nil {
+			return fmt.Errorf("%w: reading key %s: %v", ErrInvalidSignature, k.KeyID, err)
+		}
+		keyring = append(keyring, entities...)
+	}
+	if len(keyring) == 0 {
+		return fmt.Errorf("%w: no signing keys published", ErrInvalidSignature)
+	}
+
+	check := openpgp.CheckDetachedSignature
+	if bytes.HasPrefix(bytes.TrimSpace(signature), []byte("-----BEGIN")) {
+		check = openpgp.CheckArmoredDetachedSignature
+	}
+	if _, err := check(keyring, bytes.NewReader(signed), bytes.NewReader(signature), nil); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+package terrakube_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+
+	terrakube "github.com/terrakube-io/terrakube-go"
+	"github.com/terrakube-io/terrakube-go/testutil"
+)
+
+// testSigner is a throwaway OpenPGP key for signing SHA256SUMS files.
+type testSigner struct {
+	entity *openpgp.Entity
+	armor  string
+}
+
+func newTestSigner(t *testing.T) *testSigner {
+	t.Helper()
+	entity, err := openpgp.NewEntity("Test Signer", "", "signer@example.com", nil)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+	var buf bytes.Buffer
+	w, err := armor.Encode(&buf, openpgp.PublicKeyType, nil)
+	if err != nil {
+		t.Fatalf("armoring key: %v", err)
+	}
+	if err := entity.Serialize(w); err != nil {
+		t.Fatalf("serializing key: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("armoring key: %v", err)
+	}
+	return &testSigner{entity: entity, armor: buf.String()}
+}
+
+func (s *testSigner) sign(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var sig bytes.Buffer
+	if err := openpgp.DetachSign(&sig, s.entity, bytes.NewReader(data), nil); err != nil {
+		t.Fatalf("signing: %v", err)
+	}
+	return sig.Bytes()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+type providerRelease struct {
+	archive   []byte
+	shasums   []byte
+	signature []byte
+	pkg       *terrakube.ProviderPackage
+}
+
+func newProviderRelease(t *testing.T, signer *testSigner) *providerRelease {
+	t.Helper()
+	archive := []byte("PK\x03\x04 fake provider zip")
+	shasums := []byte(sha256Hex(archive) + "  terraform-provider-demo_1.0.0_linux_amd64.zip\n" +
+		sha256Hex([]byte("other")) + "  terraform-provider-demo_1.0.0_darwin_arm64.zip\n")
+
+	pkg := &terrakube.ProviderPackage{
+		OS:                  "linux",
+		Arch:                "amd64",
+		Filename:            "terraform-provider-demo_1.0.0_linux_amd64.zip",
+		DownloadURL:         "/files/terraform-provider-demo_1.0.0_linux_amd64.zip",
+		ShasumsURL:          "/files/SHA256SUMS",
+		ShasumsSignatureURL: "/files/SHA256SUMS.sig",
+		Shasum:              sha256Hex(archive),
+	}
+	pkg.SigningKeys.GPGPublicKeys = []terrakube.ProviderSigningKey{{KeyID: signer.entity.PrimaryKey.KeyIdString(), ASCIIArmor: signer.armor}}
+
+	return &providerRelease{archive: archive, shasums: shasums, signature: signer.sign(t, shasums), pkg: pkg}
+}
+
+func TestRegistryService_DownloadProvider(t *testing.T) {
+	t.Parallel()
+
+	rel := newProviderRelease(t, newTestSigner(t))
+
+	srv := newRegistryServer(t, nil)
+	srv.HandleFunc("GET /terraform/providers/v1/my-org/demo/versions", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSON(t, w, http.StatusOK, map[string]interface{}{
+			"versions": []*terrakube.ProviderVersionInfo{{
+				Version:   "1.0.0",
+				Protocols: []string{"5.0"},
+				Platforms: []terrakube.ProviderPlatform{{OS: "linux", Arch: "amd64"}},
+			}},
+		})
+	})
+	srv.HandleFunc("GET /terraform/providers/v1/my-org/demo/1.0.0/download/linux/amd64", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSON(t, w, http.StatusOK, rel.pkg)
+	})
+	srv.HandleFunc("GET /files/terraform-provider-demo_1.0.0_linux_amd64.zip", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(rel.archive)
+	})
+	srv.HandleFunc("GET /files/SHA256SUMS", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(rel.shasums)
+	})
+	srv.HandleFunc("GET /files/SHA256SUMS.sig", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(rel.signature)
+	})
+
+	client := newTestClient(t, srv)
+
+	versions, err := client.Registry.ProviderVersions(context.Background(), "my-org/demo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(versions) != 1 || versions[0].Platforms[0].OS != "linux" {
+		t.Errorf("versions = %+v", versions)
+	}
+
+	pkg, archive, err := client.Registry.DownloadProvider(context.Background(), "my-org/demo", "1.0.0", "linux", "amd64")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pkg.Filename != rel.pkg.Filename || !bytes.Equal(archive, rel.archive) {
+		t.Errorf("got %s (%d bytes)", pkg.Filename, len(archive))
+	}
+}
+
+func TestVerifyProviderPackage(t *testing.T) {
+	t.Parallel()
+
+	signer := newTestSigner(t)
+	rel := newProviderRelease(t, signer)
+
+	if err := terrakube.VerifyProviderPackage(rel.pkg, rel.shasums, rel.signature, rel.archive); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var armored bytes.Buffer
+	w, _ := armor.Encode(&armored, "PGP SIGNATURE", nil)
+	_, _ = w.Write(rel.signature)
+	_ = w.Close()
+	if err := terrakube.VerifyProviderPackage(rel.pkg, rel.shasums, armored.Bytes(), rel.archive); err != nil {
+		t.Errorf("armored signature: unexpected error: %v", err)
+	}
+
+	otherSig := newTestSigner(t).sign(t, rel.shasums)
+	tamperedSums := bytes.Replace(rel.shasums, []byte("darwin"), []byte("window"), 1)
+	wrongShasum := *rel.pkg
+	wrongShasum.Shasum = sha256Hex([]byte("nope"))
+	noKeys := *rel.pkg
+	noKeys.SigningKeys.GPGPublicKeys = nil
+
+	tests := []struct {
+		name      string
+		pkg       *terrakube.ProviderPackage
+		shasums   []byte
+		signature []byte
+		archive   []byte
+		want      error
+	}{
+		{"signed by unknown key", rel.pkg, rel.shasums, otherSig, rel.archive, terrakube.ErrInvalidSignature},
+		{"tampered shasums", rel.pkg, tamperedSums, rel.signature, rel.archive, terrakube.ErrInvalidSignature},
+		{"no signing keys", &noKeys, rel.shasums, rel.signature, rel.archive, terrakube.ErrInvalidSignature},
+		{"tampered archive", rel.pkg, rel.shasums, rel.signature, []byte("evil"), terrakube.ErrChecksumMismatch},
+		{"registry shasum differs", &wrongShasum, rel.shasums, rel.signature, rel.archive, terrakube.ErrChecksumMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := terrakube.VerifyProviderPackage(tt.pkg, tt.shasums, tt.signature, tt.archive)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseShasums(t *testing.T) {
+	t.Parallel()
+
+	sums, err := terrakube.ParseShasums([]byte("abc  a.zip\ndef *b.zip\n\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sums["a.zip"] != "abc" || sums["b.zip"] != "def" {
+		t.Errorf("sums = %v", sums)
+	}
+	if _, err := terrakube.ParseShasums([]byte("just-one-field\n")); err == nil {
+		t.Error("expected error for malformed line")
+	}
+}
+
+func TestRegistryService_ProviderPackage_Validation(t *testing.T) {
+	t.Parallel()
+
+	client := newTestClientFromURL(t, "https://example.com")
+
+	_, err := client.Registry.ProviderPackage(context.Background(), "my-org/demo", "1.0.0", "", "amd64")
+	assertValidationError(t, err, "os")
+	if _, err := client.Registry.ProviderVersions(context.Background(), "demo"); err == nil {
+		t.Error("expected error for malformed provider address")
+	}
+}
@@ -0,0 +1,309 @@
+package terrakube_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	terrakube "github.com/terrakube-io/terrakube-go"
+	"github.com/terrakube-io/terrakube-go/testutil"
+)
+
+// newRegistryServer serves a discovery document and the versions of my-org/vpc/aws.
+func newRegistryServer(t *testing.T, discoveries *atomic.Int32) *testutil.Server {
+	t.Helper()
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("GET /.well-known/terraform.json", func(w http.ResponseWriter, _ *http.Request) {
+		if discoveries != nil {
+			discoveries.Add(1)
+		}
+		testutil.WriteJSON(t, w, http.StatusOK, map[string]interface{}{
+			"modules.v1":   "/terraform/modules/v1/",
+			"providers.v1": "/terraform/providers/v1/",
+			"login.v1":     map[string]interface{}{"client": "terraform-cli"},
+		})
+	})
+	srv.HandleFunc("GET /terraform/modules/v1/my-org/vpc/aws/versions", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			t.Error("missing Authorization header")
+		}
+		testutil.WriteJSON(t, w, http.StatusOK, map[string]interface{}{
+			"modules": []interface{}{map[string]interface{}{
+				"versions": []interface{}{
+					map[string]string{"version": "1.0.0"},
+					map[string]string{"version": "1.2.0"},
+					map[string]string{"version": "2.0.0"},
+				},
+			}},
+		})
+	})
+	return srv
+}
+
+func TestRegistryService_ModuleVersions(t *testing.T) {
+	t.Parallel()
+
+	var discoveries atomic.Int32
+	client := newTestClient(t, newRegistryServer(t, &discoveries))
+
+	versions, err := client.Registry.ModuleVersions(context.Background(), "my-org/vpc/aws")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(versions) != 3 || versions[2] != "2.0.0" {
+		t.Errorf("versions = %v", versions)
+	}
+
+	v, err := client.Registry.ResolveModuleVersion(context.Background(), "my-org/vpc/aws", "~> 1.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v != "1.2.0" {
+		t.Errorf("ResolveModuleVersion = %q, want %q", v, "1.2.0")
+	}
+	if discoveries.Load() != 1 {
+		t.Errorf("discovery fetched %d times, want 1", discoveries.Load())
+	}
+}
+
+func TestRegistryService_Discover(t *testing.T) {
+	t.Parallel()
+
+	client := newTestClient(t, newRegistryServer(t, nil))
+
+	services, err := client.Registry.Discover(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if services[terrakube.ServiceProvidersV1] != "/terraform/providers/v1/" {
+		t.Errorf("providers.v1 = %q", services[terrakube.ServiceProvidersV1])
+	}
+	if _, ok := services["login.v1"]; ok {
+		t.Error("non-URL services should be skipped")
+	}
+
+	delete(services, terrakube.ServiceModulesV1)
+	again, err := client.Registry.Discover(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if again[terrakube.ServiceModulesV1] != "/terraform/modules/v1/" {
+		t.Errorf("changing a Discover result changed the cache: modules.v1 = %q", again[terrakube.ServiceModulesV1])
+	}
+}
+
+func TestRegistryService_Discover_SlowRequest(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("GET /.well-known/terraform.json", func(w http.ResponseWriter, _ *http.Request) {
+		if requests.Add(1) == 1 {
+			close(entered)
+			<-release
+		}
+		testutil.WriteJSON(t, w, http.StatusOK, map[string]interface{}{"modules.v1": "/terraform/modules/v1/"})
+	})
+	client := newTestClient(t, srv)
+
+	slowCtx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.Registry.Discover(slowCtx)
+		done <- err
+	}()
+	<-entered
+
+	// The first request is still in flight; a second caller must not wait for it.
+	fast := make(chan error, 1)
+	go func() {
+		_, err := client.Registry.Discover(context.Background())
+		fast <- err
+	}()
+	select {
+	case err := <-fast:
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Discover waited for another caller's request")
+	}
+
+	cancel()
+	if err := <-done; err == nil {
+		t.Error("expected the cancelled discovery to fail")
+	}
+}
+
+func zipArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("zip: %v", err)
+		}
+		_, _ = w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("zip: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func tarGzArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatalf("tar: %v", err)
+		}
+		_, _ = tw.Write([]byte(content))
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("tar: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestRegistryService_DownloadModule(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		source  string
+		archive func(*testing.T, map[string]string) []byte
+		files   map[string]string
+		want    map[string]string
+	}{
+		{
+			name:    "zip",
+			source:  "/archives/vpc.zip",
+			archive: zipArchive,
+			files:   map[string]string{"main.tf": "# main", "modules/sub/variables.tf": "# vars"},
+			want:    map[string]string{"main.tf": "# main", "modules/sub/variables.tf": "# vars"},
+		},
+		{
+			name:    "tar.gz with subdir",
+			source:  "/archives/vpc.tar.gz//modules/sub",
+			archive: tarGzArchive,
+			files:   map[string]string{"main.tf": "# main", "modules/sub/variables.tf": "# vars"},
+			want:    map[string]string{"variables.tf": "# vars"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := newRegistryServer(t, nil)
+			srv.HandleFunc("GET /terraform/modules/v1/my-org/vpc/aws/1.2.0/download", func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("X-Terraform-Get", tt.source)
+				w.WriteHeader(http.StatusNoContent)
+			})
+			archive := tt.archive(t, tt.files)
+			srv.HandleFunc("GET /archives/", func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write(archive)
+			})
+
+			client := newTestClient(t, srv)
+			dir := t.TempDir()
+
+			if err := client.Registry.DownloadModule(context.Background(), "my-org/vpc/aws", "1.2.0", dir); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for name, content := range tt.want {
+				got, err := os.ReadFile(filepath.Join(dir, name))
+				if err != nil {
+					t.Errorf("missing %s: %v", name, err)
+					continue
+				}
+				if string(got) != content {
+					t.Errorf("%s = %q, want %q", name, got, content)
+				}
+			}
+			if _, err := os.Stat(filepath.Join(dir, "main.tf")); tt.want["main.tf"] == "" && err == nil {
+				t.Error("files outside the subdir should not be extracted")
+			}
+		})
+	}
+}
+
+func TestRegistryService_DownloadModule_PathTraversal(t *testing.T) {
+	t.Parallel()
+
+	srv := newRegistryServer(t, nil)
+	srv.HandleFunc("GET /terraform/modules/v1/my-org/vpc/aws/1.2.0/download", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("X-Terraform-Get", "/archives/evil.zip")
+		w.WriteHeader(http.StatusNoContent)
+	})
+	archive := zipArchive(t, map[string]string{"../evil.tf": "# evil"})
+	srv.HandleFunc("GET /archives/evil.zip", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(archive)
+	})
+
+	client := newTestClient(t, srv)
+	dir := filepath.Join(t.TempDir(), "module")
+
+	if err := client.Registry.DownloadModule(context.Background(), "my-org/vpc/aws", "1.2.0", dir); err == nil {
+		t.Fatal("expected error for archive entry escaping the target directory")
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(dir), "evil.tf")); err == nil {
+		t.Error("archive entry was written outside the target directory")
+	}
+}
+
+func TestRegistryService_ModuleDownloadURL_Forced(t *testing.T) {
+	t.Parallel()
+
+	srv := newRegistryServer(t, nil)
+	srv.HandleFunc("GET /terraform/modules/v1/my-org/vpc/aws/1.2.0/download", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("X-Terraform-Get", "git::https://example.com/vpc.git?ref=v1.2.0")
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	client := newTestClient(t, srv)
+
+	src, err := client.Registry.ModuleDownloadURL(context.Background(), "my-org/vpc/aws", "1.2.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if src != "git::https://example.com/vpc.git?ref=v1.2.0" {
+		t.Errorf("source = %q", src)
+	}
+	if err := client.Registry.DownloadModule(context.Background(), "my-org/vpc/aws", "1.2.0", t.TempDir()); err == nil {
+		t.Error("expected error for unsupported git source")
+	}
+}
+
+func TestRegistryService_InvalidAddress(t *testing.T) {
+	t.Parallel()
+
+	client := newTestClientFromURL(t, "https://example.com")
+
+	for _, addr := range []string{"", "my-org/vpc", "my-org//aws", "a/b/c/d"} {
+		if _, err := client.Registry.ModuleVersions(context.Background(), addr); err == nil {
+			t.Errorf("ModuleVersions(%q): expected error", addr)
+		}
+	}
+	_, err := client.Registry.ModuleDownloadURL(context.Background(), "my-org/vpc/aws", "")
+	assertValidationError(t, err, "version")
+}
@@ -0,0 +1,378 @@
+package terrakube
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// ReportFormat selects the output format of a rendered PlanReport.
+type ReportFormat string
+
+// Supported report formats.
+const (
+	ReportFormatMarkdown ReportFormat = "markdown"
+	ReportFormatText     ReportFormat = "text"
+)
+
+// DefaultReportMaxLength is the rendered report size limit used when
+// ReportOptions.MaxLength is zero. It fits within GitHub's 65536 character
+// comment limit.
+const DefaultReportMaxLength = 65000
+
+// PlanReport gathers everything needed to summarize a job for a merge request.
+type PlanReport struct {
+	Job       *Job
+	Steps     []*Step
+	Addresses []*Address
+	// Plan is nil when the job has no stored plan.
+	Plan *Plan
+	// JobURL links back to the job in the Terrakube UI. It is omitted when empty.
+	JobURL string
+}
+
+// ReportOptions configures PlanReport.Render.
+type ReportOptions struct {
+	// Format defaults to ReportFormatMarkdown.
+	Format ReportFormat
+	// MaxLength caps the rendered size in bytes. Resource lines beyond the
+	// limit are replaced by a note. Space goes first to the job link, then
+	// to the note, then to the header, which is shortened only when it does
+	// not fit in what is left. A job link longer than the limit on its own
+	// is written as the bare URL. Defaults to DefaultReportMaxLength.
+	MaxLength int
+}
+
+// reportActionOrder lists actions in the order they are rendered, most
+// destructive first.
+var reportActionOrder = []PlanAction{
+	PlanActionDelete,
+	PlanActionReplace,
+	PlanActionCreate,
+	PlanActionUpdate,
+	PlanActionRead,
+}
+
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]`)
+
+// reportLine is one rendered body line. Items are resource or address entries
+// and are counted when the report is truncated.
+type reportLine struct {
+	text string
+	item bool
+}
+
+// Report fetches a job with its steps, addresses, and plan, ready to render.
+// A job without a stored plan yields a report with a nil Plan.
+// It returns a *ValidationError if orgID or id is empty and a *APIError on server errors.
+func (s *JobService) Report(ctx context.Context, orgID, id string) (*PlanReport, error) {
+	job, err := s.Get(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	steps, err := s.client.Steps.List(ctx, orgID, id, nil)
+	if err != nil {
+		return nil, err
+	}
+	addresses, err := s.client.Addresses.List(ctx, orgID, id, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := s.planFor(ctx, job)
+	if err != nil && !errors.Is(err, ErrNoPlan) {
+		return nil, err
+	}
+
+	report := &PlanReport{
+		Job:       job,
+		Steps:     steps,
+		Addresses: addresses,
+		Plan:      plan,
+	}
+	if job.Workspace != nil && job.Workspace.ID != "" {
+		report.JobURL = s.client.JobURL(orgID, job.Workspace.ID, job.ID)
+	}
+	return report, nil
+}
+
+// Render formats the report as Markdown or plain text. The output never
+// contains ANSI escape sequences.
+func (r *PlanReport) Render(opts ReportOptions) string {
+	md := opts.Format != ReportFormatText
+	maxLen := opts.MaxLength
+	if maxLen <= 0 {
+		maxLen = DefaultReportMaxLength
+	}
+
+	head := r.renderHead(md)
+	body := r.renderBody(md)
+	foot := ""
+	if r.JobURL != "" {
+		if md {
+			foot = fmt.Sprintf("\n[View job in Terrakube](%s)\n", r.JobURL)
+		} else {
+			foot = fmt.Sprintf("\nView job in Terrakube: %s\n", r.JobURL)
+		}
+	}
+
+	bodyLen, items := 0, 0
+	for _, line := range body {
+		bodyLen += len(line.text) + 1
+		if line.item {
+			items++
+		}
+	}
+	if len(head)+bodyLen+len(foot) <= maxLen {
+		return renderLines(head, body, foot, maxLen, 0, md)
+	}
+
+	// The job link has priority, then the note, then the header; the body
+	// gets whatever room is left.
+	room := maxLen
+	if len(foot) > room {
+		foot = truncateText(r.JobURL+"\n", room)
+	}
+	room -= len(foot)
+	reserve := min(len(truncationNote(items, md)), room)
+	room -= reserve
+	head = truncateText(head, room)
+	return renderLines(head, body, foot, maxLen, reserve, md)
+}
+
+// renderLines writes the head, the body lines that fit in maxLen beside the
+// foot and the reserved room, a truncation note in that room for the lines
+// left out, and the foot.
+func renderLines(head string, body []reportLine, foot string, maxLen, reserve int, md bool) string {
+	var b strings.Builder
+	b.WriteString(head)
+	budget := maxLen - len(head) - len(foot) - reserve
+	for i, line := range body {
+		if len(line.text)+1 > budget {
+			remaining := 0
+			for _, l := range body[i:] {
+				if l.item {
+					remaining++
+				}
+			}
+			b.WriteString(truncateText(truncationNote(remaining, md), reserve))
+			break
+		}
+		b.WriteString(line.text + "\n")
+		budget -= len(line.text) + 1
+	}
+	b.WriteString(foot)
+	return b.String()
+}
+
+// truncationNote is the line that replaces the entries that did not fit.
+func truncationNote(remaining int, md bool) string {
+	note := fmt.Sprintf("... %d more entries not shown", remaining)
+	if md {
+		return "\n_" + note + "_\n"
+	}
+	return note + "\n"
+}
+
+// truncateText shortens s to at most n bytes, cutting at a rune boundary
+// and ending with an ellipsis line when anything was removed.
+func truncateText(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	const marker = "...\n"
+	if n < len(marker) {
+		return ""
+	}
+	cut := n - len(marker)
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + marker
+}
+
+// renderHead renders the title, plan totals, destroy warning, and steps.
+func (r *PlanReport) renderHead(md bool) string {
+	var b strings.Builder
+
+	title := "Terrakube job"
+	if r.Job != nil {
+		title = fmt.Sprintf("Terrakube job %s: %s", reportText(r.Job.ID), reportText(r.Job.Status))
+	}
+	if md {
+		fmt.Fprintf(&b, "### %s\n\n", title)
+	} else {
+		fmt.Fprintf(&b, "%s\n\n", title)
+	}
+
+	switch {
+	case r.Plan == nil:
+		b.WriteString("No plan available.\n")
+	case !r.Plan.HasChanges():
+		b.WriteString("No changes. Infrastructure is up-to-date.\n")
+	default:
+		s := r.Plan.Summary()
+		totals := fmt.Sprintf("%d to add, %d to change, %d to destroy.", s.Add, s.Change, s.Destroy)
+		if md {
+			fmt.Fprintf(&b, "**Plan:** %s\n", totals)
+		} else {
+			fmt.Fprintf(&b, "Plan: %s\n", totals)
+		}
+		if s.Destroy > 0 {
+			warning := fmt.Sprintf("%d resource(s) will be destroyed", s.Destroy)
+			if s.Replace > 0 {
+				warning += fmt.Sprintf(", %d of them replaced", s.Replace)
+			}
+			if md {
+				fmt.Fprintf(&b, "\n> **Warning:** %s.\n", warning)
+			} else {
+				fmt.Fprintf(&b, "WARNING: %s.\n", warning)
+			}
+		}
+	}
+
+	if len(r.Steps) > 0 {
+		steps := make([]*Step, len(r.Steps))
+		copy(steps, r.Steps)
+		sort.SliceStable(steps, func(i, j int) bool { return steps[i].StepNumber < steps[j].StepNumber })
+
+		if md {
+			b.WriteString("\n| Step | Name | Status |\n|---:|---|---|\n")
+			for _, st := range steps {
+				fmt.Fprintf(&b, "| %d | %s | %s |\n", st.StepNumber, escapeTableCell(reportText(st.Name)), reportText(st.Status))
+			}
+		} else {
+			b.WriteString("\nSteps:\n")
+			for _, st := range steps {
+				fmt.Fprintf(&b, "  %d  %s  %s\n", st.StepNumber, reportText(st.Name), reportText(st.Status))
+			}
+		}
+	}
+
+	return b.String()
+}
+
+// renderBody renders resource changes grouped by module and action, followed
+// by the job's addresses.
+func (r *PlanReport) renderBody(md bool) []reportLine {
+	var lines []reportLine
+
+	if r.Plan != nil {
+		groups := map[string][]ResourceSummary{}
+		var modules []string
+		for _, rs := range r.Plan.Summary().Resources {
+			if _, ok := groups[rs.ModuleAddress]; !ok {
+				modules = append(modules, rs.ModuleAddress)
+			}
+			groups[rs.ModuleAddress] = append(groups[rs.ModuleAddress], rs)
+		}
+		sort.Strings(modules)
+
+		for _, mod := range modules {
+			name := "Root module"
+			if mod != "" {
+				name = reportText(mod)
+			}
+			if md {
+				if mod != "" {
+					name = "`" + name + "`"
+				}
+				lines = append(lines, reportLine{text: "\n#### " + name})
+			} else {
+				lines = append(lines, reportLine{text: "\n" + name + ":"})
+			}
+
+			for _, action := range reportActionOrder {
+				var entries []reportLine
+				for _, rs := range groups[mod] {
+					if rs.Action == action {
+						entries = append(entries, reportLine{text: resourceLine(rs, md), item: true})
+					}
+				}
+				if len(entries) == 0 {
+					continue
+				}
+				heading := actionHeading(action)
+				if md {
+					lines = append(lines, reportLine{text: "**" + heading + "**"})
+				} else {
+					lines = append(lines, reportLine{text: "  " + heading + ":"})
+				}
+				lines = append(lines, entries...)
+			}
+		}
+	}
+
+	if len(r.Addresses) > 0 {
+		if md {
+			lines = append(lines, reportLine{text: "\n#### Addresses"})
+		} else {
+			lines = append(lines, reportLine{text: "\nAddresses:"})
+		}
+		for _, a := range r.Addresses {
+			if md {
+				lines = append(lines, reportLine{text: fmt.Sprintf("- `%s` (%s)", reportText(a.Name), reportText(a.Type)), item: true})
+			} else {
+				lines = append(lines, reportLine{text: fmt.Sprintf("  - %s (%s)", reportText(a.Name), reportText(a.Type)), item: true})
+			}
+		}
+	}
+
+	return lines
+}
+
+// resourceLine renders a single resource entry with its annotations.
+func resourceLine(rs ResourceSummary, md bool) string {
+	var notes []string
+	if rs.Action == PlanActionDelete || rs.Action == PlanActionReplace {
+		notes = append(notes, "destroyed")
+	}
+	if rs.Sensitive {
+		notes = append(notes, "sensitive")
+	}
+	if rs.Unknown {
+		notes = append(notes, "known after apply")
+	}
+
+	suffix := ""
+	if len(notes) > 0 {
+		suffix = " (" + strings.Join(notes, ", ") + ")"
+	}
+	if md {
+		return "- `" + reportText(rs.Address) + "`" + suffix
+	}
+	return "    - " + reportText(rs.Address) + suffix
+}
+
+// actionHeading returns the group heading for an action.
+func actionHeading(a PlanAction) string {
+	switch a {
+	case PlanActionDelete:
+		return "Destroy"
+	case PlanActionReplace:
+		return "Replace"
+	case PlanActionCreate:
+		return "Create"
+	case PlanActionUpdate:
+		return "Update"
+	case PlanActionRead:
+		return "Read"
+	}
+	return string(a)
+}
+
+// reportText strips ANSI escape sequences and newlines from a value embedded in a report.
+func reportText(s string) string {
+	s = ansiEscape.ReplaceAllString(s, "")
+	return strings.NewReplacer("\r", "", "\n", " ").Replace(s)
+}
+
+// escapeTableCell escapes characters that would break a Markdown table cell.
+func escapeTableCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
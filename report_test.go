@@ -0,0 +1,239 @@
+package terrakube_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	terrakube "github.com/terrakube-io/terrakube-go"
+	"github.com/terrakube-io/terrakube-go/testutil"
+)
+
+func newTestReport(t *testing.T) *terrakube.PlanReport {
+	t.Helper()
+	plan, err := terrakube.ParsePlan([]byte(testPlanJSON))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return &terrakube.PlanReport{
+		Job: &terrakube.Job{ID: "job-1", Status: "waitingApproval"},
+		Steps: []*terrakube.Step{
+			{ID: "s2", Name: "Apply", Status: "pending", StepNumber: 200},
+			{ID: "s1", Name: "\x1b[32mPlan\x1b[0m", Status: "completed", StepNumber: 100},
+		},
+		Addresses: []*terrakube.Address{{ID: "a1", Name: "aws_s3_bucket.logs", Type: "resource"}},
+		Plan:      plan,
+		JobURL:    "https://ui.example.com/organizations/org-1/workspaces/ws-1/runs/job-1",
+	}
+}
+
+func TestPlanReport_RenderMarkdown(t *testing.T) {
+	t.Parallel()
+
+	out := newTestReport(t).Render(terrakube.ReportOptions{})
+
+	for _, want := range []string{
+		"### Terrakube job job-1: waitingApproval",
+		"**Plan:** 2 to add, 1 to change, 2 to destroy.",
+		"> **Warning:** 2 resource(s) will be destroyed, 1 of them replaced.",
+		"| 100 | Plan | completed |",
+		"#### Root module",
+		"#### `module.db`",
+		"**Destroy**\n- `aws_iam_role.old` (destroyed)",
+		"**Replace**\n- `aws_instance.web[0]` (destroyed, known after apply)",
+		"- `module.db.aws_db_instance.main` (sensitive)",
+		"#### Addresses\n- `aws_s3_bucket.logs` (resource)",
+		"[View job in Terrakube](https://ui.example.com/organizations/org-1/workspaces/ws-1/runs/job-1)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q\n%s", want, out)
+		}
+	}
+	if strings.Index(out, "| 100 |") > strings.Index(out, "| 200 |") {
+		t.Error("steps are not ordered by step number")
+	}
+	if strings.Contains(out, "aws_vpc.main") {
+		t.Error("no-op resources should not be listed")
+	}
+}
+
+func TestPlanReport_RenderText(t *testing.T) {
+	t.Parallel()
+
+	out := newTestReport(t).Render(terrakube.ReportOptions{Format: terrakube.ReportFormatText})
+
+	for _, want := range []string{
+		"Terrakube job job-1: waitingApproval",
+		"Plan: 2 to add, 1 to change, 2 to destroy.",
+		"WARNING: 2 resource(s) will be destroyed, 1 of them replaced.",
+		"  100  Plan  completed",
+		"Root module:\n  Destroy:\n    - aws_iam_role.old (destroyed)",
+		"View job in Terrakube: https://ui.example.com/",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q\n%s", want, out)
+		}
+	}
+	for _, bad := range []string{"\x1b", "**", "###", "`"} {
+		if strings.Contains(out, bad) {
+			t.Errorf("text output contains %q\n%s", bad, out)
+		}
+	}
+}
+
+func TestPlanReport_RenderTruncated(t *testing.T) {
+	t.Parallel()
+
+	report := newTestReport(t)
+	full := report.Render(terrakube.ReportOptions{})
+	limit := len(full) - 40
+
+	out := report.Render(terrakube.ReportOptions{MaxLength: limit})
+	if len(out) > limit {
+		t.Errorf("len(output) = %d, want <= %d", len(out), limit)
+	}
+	if !strings.Contains(out, "more entries not shown") {
+		t.Errorf("output missing truncation note\n%s", out)
+	}
+	if !strings.Contains(out, "[View job in Terrakube]") {
+		t.Errorf("truncated output lost the job link\n%s", out)
+	}
+}
+
+func TestPlanReport_RenderLongHeader(t *testing.T) {
+	t.Parallel()
+
+	const limit = 400
+	for _, format := range []terrakube.ReportFormat{terrakube.ReportFormatMarkdown, terrakube.ReportFormatText} {
+		report := newTestReport(t)
+		report.Job.ID = strings.Repeat("j", limit-120)
+
+		out := report.Render(terrakube.ReportOptions{Format: format, MaxLength: limit})
+		if len(out) > limit {
+			t.Errorf("%s: len(output) = %d, want <= %d\n%s", format, len(out), limit, out)
+		}
+		if !strings.Contains(out, "more entries not shown") {
+			t.Errorf("%s: output missing truncation note\n%s", format, out)
+		}
+		if !strings.Contains(out, report.JobURL) {
+			t.Errorf("%s: truncated output lost the job link\n%s", format, out)
+		}
+	}
+}
+
+func TestPlanReport_RenderKeepsJobLink(t *testing.T) {
+	t.Parallel()
+
+	report := newTestReport(t)
+	report.JobURL = "https://ui.example.com/organizations/org-1/workspaces/" + strings.Repeat("w", 150) + "/runs/job-1"
+	links := map[terrakube.ReportFormat]string{
+		terrakube.ReportFormatMarkdown: "[View job in Terrakube](" + report.JobURL + ")\n",
+		terrakube.ReportFormatText:     "View job in Terrakube: " + report.JobURL + "\n",
+	}
+
+	for format, link := range links {
+		tests := []struct {
+			limit int
+			want  string
+		}{
+			// Room for the link but not the note beside it.
+			{len(link) + 10, link},
+			// Smaller than the link itself.
+			{len(report.JobURL) + 5, report.JobURL + "\n"},
+		}
+		for _, tt := range tests {
+			out := report.Render(terrakube.ReportOptions{Format: format, MaxLength: tt.limit})
+			if len(out) > tt.limit {
+				t.Errorf("%s, limit %d: len(output) = %d\n%s", format, tt.limit, len(out), out)
+			}
+			if !strings.HasSuffix(out, tt.want) {
+				t.Errorf("%s, limit %d: output does not end with %q\n%s", format, tt.limit, tt.want, out)
+			}
+		}
+	}
+}
+
+func TestPlanReport_RenderNoPlan(t *testing.T) {
+	t.Parallel()
+
+	report := &terrakube.PlanReport{Job: &terrakube.Job{ID: "job-1", Status: "failed"}}
+	out := report.Render(terrakube.ReportOptions{Format: terrakube.ReportFormatText})
+	if !strings.Contains(out, "No plan available.") {
+		t.Errorf("output missing no-plan note\n%s", out)
+	}
+}
+
+func TestJobService_Report(t *testing.T) {
+	t.Parallel()
+
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("GET /api/v1/organization/org-1/job/job-1", func(w http.ResponseWriter, _ *http.Request) {
+		job := newTestJob()
+		job.Workspace = &terrakube.Workspace{ID: "ws-1"}
+		ref := "/tfoutput/plan.json"
+		job.TerraformPlan = &ref
+		testutil.WriteJSONAPI(t, w, http.StatusOK, job)
+	})
+	srv.HandleFunc("GET /api/v1/organization/org-1/job/job-1/step", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSONAPIList(t, w, http.StatusOK, []*terrakube.Step{{ID: "s1", Name: "Plan", StepNumber: 100}})
+	})
+	srv.HandleFunc("GET /api/v1/organization/org-1/job/job-1/address", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSONAPIList(t, w, http.StatusOK, []*terrakube.Address{})
+	})
+	srv.HandleFunc("GET /tfoutput/plan.json", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(testPlanJSON))
+	})
+
+	client, err := terrakube.NewClient(
+		terrakube.WithEndpoint(srv.URL),
+		terrakube.WithToken("test-token"),
+		terrakube.WithUIEndpoint("https://ui.example.com"),
+	)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	report, err := client.Jobs.Report(context.Background(), "org-1", "job-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Plan == nil {
+		t.Fatal("expected plan in report")
+	}
+	if len(report.Steps) != 1 {
+		t.Errorf("got %d steps, want 1", len(report.Steps))
+	}
+	want := "https://ui.example.com/organizations/org-1/workspaces/ws-1/runs/job-1"
+	if report.JobURL != want {
+		t.Errorf("JobURL = %q, want %q", report.JobURL, want)
+	}
+}
+
+func TestJobService_Report_NoPlan(t *testing.T) {
+	t.Parallel()
+
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("GET /api/v1/organization/org-1/job/job-1", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSONAPI(t, w, http.StatusOK, newTestJob())
+	})
+	srv.HandleFunc("GET /api/v1/organization/org-1/job/job-1/step", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSONAPIList(t, w, http.StatusOK, []*terrakube.Step{})
+	})
+	srv.HandleFunc("GET /api/v1/organization/org-1/job/job-1/address", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSONAPIList(t, w, http.StatusOK, []*terrakube.Address{})
+	})
+
+	client := newTestClient(t, srv)
+
+	report, err := client.Jobs.Report(context.Background(), "org-1", "job-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Plan != nil {
+		t.Error("expected nil plan")
+	}
+	if report.JobURL != "" {
+		t.Errorf("JobURL = %q, want empty without a workspace", report.JobURL)
+	}
+}
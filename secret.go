@@ -0,0 +1,247 @@
+package terrakube
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"gopkg.in/yaml.v3"
+)
+
+// Secret reference schemes handled by the built-in resolvers.
+const (
+	envScheme  = "env://"
+	fileScheme = "file://"
+	ageScheme  = "age://"
+)
+
+// SecretResolver expands secret references in variable values, such as
+// env://DB_PASSWORD, into the secret itself.
+//
+// Resolve reports ok=false for values it does not handle; those are sent
+// unchanged. A non-nil error means the value is a reference that could not
+// be resolved.
+type SecretResolver interface {
+	Resolve(ctx context.Context, ref string) (value string, ok bool, err error)
+}
+
+// SecretResolverFunc adapts a function to the SecretResolver interface.
+type SecretResolverFunc func(ctx context.Context, ref string) (string, bool, error)
+
+// Resolve calls f(ctx, ref).
+func (f SecretResolverFunc) Resolve(ctx context.Context, ref string) (string, bool, error) {
+	return f(ctx, ref)
+}
+
+// ChainSecretResolvers returns a SecretResolver that tries each resolver in
+// order and uses the first one that handles the reference.
+func ChainSecretResolvers(resolvers ...SecretResolver) SecretResolver {
+	return SecretResolverFunc(func(ctx context.Context, ref string) (string, bool, error) {
+		for _, r := range resolvers {
+			value, ok, err := r.Resolve(ctx, ref)
+			if ok || err != nil {
+				return value, ok, err
+			}
+		}
+		return "", false, nil
+	})
+}
+
+// DefaultSecretResolver resolves env:// and file:// references.
+func DefaultSecretResolver() SecretResolver {
+	return ChainSecretResolvers(EnvSecretResolver(), FileSecretResolver())
+}
+
+// EnvSecretResolver resolves env://NAME references to the value of the
+// environment variable NAME. An unset variable is an error; an empty one is not.
+func EnvSecretResolver() SecretResolver {
+	return SecretResolverFunc(func(_ context.Context, ref string) (string, bool, error) {
+		name, ok := strings.CutPrefix(ref, envScheme)
+		if !ok {
+			return "", false, nil
+		}
+		if name == "" {
+			return "", true, fmt.Errorf("missing environment variable name")
+		}
+		value, set := os.LookupEnv(name)
+		if !set {
+			return "", true, fmt.Errorf("environment variable %s is not set", name)
+		}
+		return value, true, nil
+	})
+}
+
+// FileSecretResolver resolves file:///path references to the contents of the
+// file, without a trailing newline. Paths after file:// that do not start
+// with a slash are relative to the working directory.
+func FileSecretResolver() SecretResolver {
+	return SecretResolverFunc(func(_ context.Context, ref string) (string, bool, error) {
+		name, ok := strings.CutPrefix(ref, fileScheme)
+		if !ok {
+			return "", false, nil
+		}
+		data, err := readSecretFile(name)
+		if err != nil {
+			return "", true, err
+		}
+		return trimNewline(string(data)), true, nil
+	})
+}
+
+// AgeSecretResolver resolves age:///path references to files encrypted with
+// age (https://age-encryption.org), binary or armored, using the X25519
+// identities in identityFile, in the format written by age-keygen.
+//
+// A fragment selects one top-level key of a decrypted YAML or JSON document,
+// so a single encrypted file can hold several secrets in the style of sops:
+// age:///secrets/prod.yaml.age#db_password. Without a fragment the whole
+// file, without a trailing newline, is the secret.
+func AgeSecretResolver(identityFile string) (SecretResolver, error) {
+	f, err := os.Open(identityFile)
+	if err != nil {
+		return nil, fmt.Errorf("opening age identity file: %w", err)
+	}
+	defer f.Close() //nolint:errcheck // read-only file
+
+	identities, err := age.ParseIdentities(f)
+	if err != nil {
+		return nil, fmt.Errorf("reading age identity file %s: %w", identityFile, err)
+	}
+
+	return SecretResolverFunc(func(_ context.Context, ref string) (string, bool, error) {
+		rest, ok := strings.CutPrefix(ref, ageScheme)
+		if !ok {
+			return "", false, nil
+		}
+		name, key, hasKey := strings.Cut(rest, "#")
+		data, err := readSecretFile(name)
+		if err != nil {
+			return "", true, err
+		}
+
+		var src io.Reader = bytes.NewReader(data)
+		if bytes.HasPrefix(bytes.TrimSpace(data), []byte(armor.Header)) {
+			src = armor.NewReader(bytes.NewReader(bytes.TrimSpace(data)))
+		}
+		r, err := age.Decrypt(src, identities...)
+		if err != nil {
+			return "", true, fmt.Errorf("decrypting %s: %w", name, err)
+		}
+		plain, err := io.ReadAll(r)
+		if err != nil {
+			return "", true, fmt.Errorf("decrypting %s: %w", name, err)
+		}
+		if !hasKey {
+			return trimNewline(string(plain)), true, nil
+		}
+		value, err := secretDocumentValue(plain, key)
+		if err != nil {
+			return "", true, fmt.Errorf("%s: %w", name, err)
+		}
+		return value, true, nil
+	}), nil
+}
+
+// readSecretFile reads the file a reference points to.
+func readSecretFile(name string) ([]byte, error) {
+	if name == "" {
+		return nil, fmt.Errorf("missing file path")
+	}
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("reading secret file: %w", err)
+	}
+	return data, nil
+}
+
+// secretDocumentValue returns the scalar value of a top-level key in a YAML
+// or JSON document.
+func secretDocumentValue(doc []byte, key string) (string, error) {
+	var values map[string]yaml.Node
+	if err := yaml.Unmarshal(doc, &values); err != nil {
+		return "", fmt.Errorf("decrypted content is not a YAML or JSON document: %w", err)
+	}
+	node, ok := values[key]
+	if !ok {
+		return "", fmt.Errorf("key %q not found", key)
+	}
+	if node.Kind != yaml.ScalarNode {
+		return "", fmt.Errorf("key %q is not a scalar value", key)
+	}
+	return node.Value, nil
+}
+
+// trimNewline removes a single trailing newline, as left by most editors and
+// echo.
+func trimNewline(s string) string {
+	s = strings.TrimSuffix(s, "\n")
+	return strings.TrimSuffix(s, "\r")
+}
+
+// resolveSecret expands value through the client's SecretResolver and
+// reports whether it was a secret reference.
+func (c *Client) resolveSecret(ctx context.Context, key, value string) (string, bool, error) {
+	if c.secretResolver == nil {
+		return value, false, nil
+	}
+	resolved, ok, err := c.secretResolver.Resolve(ctx, value)
+	if err != nil {
+		return "", false, &SecretError{Key: key, Ref: value, Err: err}
+	}
+	if !ok {
+		return value, false, nil
+	}
+	return resolved, true, nil
+}
+
+// resolveVariable returns a copy of v with a secret reference in its value
+// resolved and Sensitive set, or v itself if the value is not a reference.
+func (c *Client) resolveVariable(ctx context.Context, v *Variable) (*Variable, error) {
+	value, ok, err := c.resolveSecret(ctx, v.Key, v.Value)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return v, nil
+	}
+	resolved := *v
+	resolved.Value = value
+	resolved.Sensitive = true
+	return &resolved, nil
+}
+
+// resolveOrganizationVariable is resolveVariable for organization variables.
+func (c *Client) resolveOrganizationVariable(ctx context.Context, v *OrganizationVariable) (*OrganizationVariable, error) {
+	value, ok, err := c.resolveSecret(ctx, v.Key, v.Value)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return v, nil
+	}
+	sensitive := true
+	resolved := *v
+	resolved.Value = value
+	resolved.Sensitive = &sensitive
+	return &resolved, nil
+}
+
+// resolveCollectionItem is resolveVariable for collection items.
+func (c *Client) resolveCollectionItem(ctx context.Context, item *CollectionItem) (*CollectionItem, error) {
+	value, ok, err := c.resolveSecret(ctx, item.Key, item.Value)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return item, nil
+	}
+	resolved := *item
+	resolved.Value = value
+	resolved.Sensitive = true
+	return &resolved, nil
+}
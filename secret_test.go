@@ -0,0 +1,233 @@
+package terrakube_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+
+	terrakube "github.com/terrakube-io/terrakube-go"
+	"github.com/terrakube-io/terrakube-go/testutil"
+)
+
+func TestDefaultSecretResolver(t *testing.T) {
+	t.Setenv("TERRAKUBE_TEST_SECRET", "from-env")
+	t.Setenv("TERRAKUBE_TEST_EMPTY", "")
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "token")
+	if err := os.WriteFile(file, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	r := terrakube.DefaultSecretResolver()
+	ctx := context.Background()
+
+	tests := []struct {
+		ref     string
+		want    string
+		ok      bool
+		wantErr string
+	}{
+		{ref: "env://TERRAKUBE_TEST_SECRET", want: "from-env", ok: true},
+		{ref: "env://TERRAKUBE_TEST_EMPTY", want: "", ok: true},
+		{ref: "env://TERRAKUBE_TEST_UNSET", wantErr: "environment variable TERRAKUBE_TEST_UNSET is not set"},
+		{ref: "env://", wantErr: "missing environment variable name"},
+		{ref: "file://" + file, want: "from-file", ok: true},
+		{ref: "file://" + filepath.Join(dir, "missing"), wantErr: "reading secret file"},
+		{ref: "plain value", want: "", ok: false},
+		{ref: "https://example.com", want: "", ok: false},
+	}
+	for _, tt := range tests {
+		got, ok, err := r.Resolve(ctx, tt.ref)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Resolve(%q) error = %v, want %q", tt.ref, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Resolve(%q) unexpected error: %v", tt.ref, err)
+			continue
+		}
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Resolve(%q) = %q, %v, want %q, %v", tt.ref, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// writeAgeFile encrypts content to identity's recipient, armored if requested.
+func writeAgeFile(t *testing.T, path string, identity *age.X25519Identity, content string, armored bool) {
+	t.Helper()
+	var buf bytes.Buffer
+	var dst io.WriteCloser = nopWriteCloser{&buf}
+	if armored {
+		dst = armor.NewWriter(&buf)
+	}
+	w, err := age.Encrypt(dst, identity.Recipient())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(w, content); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := dst.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+func TestAgeSecretResolver(t *testing.T) {
+	t.Parallel()
+
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "keys.txt")
+	keys := "# created: 2024-01-01T00:00:00Z\n# public key: " + identity.Recipient().String() + "\n" + identity.String() + "\n"
+	if err := os.WriteFile(keyFile, []byte(keys), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	writeAgeFile(t, filepath.Join(dir, "token.age"), identity, "s3cret\n", false)
+	writeAgeFile(t, filepath.Join(dir, "prod.yaml.age"), identity, "db_password: hunter2\nport: 5432\nnested:\n  a: b\n", true)
+	writeAgeFile(t, filepath.Join(dir, "other.age"), other, "nope", false)
+
+	r, err := terrakube.AgeSecretResolver(keyFile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		ref     string
+		want    string
+		wantErr string
+	}{
+		{ref: "age://" + filepath.Join(dir, "token.age"), want: "s3cret"},
+		{ref: "age://" + filepath.Join(dir, "prod.yaml.age") + "#db_password", want: "hunter2"},
+		{ref: "age://" + filepath.Join(dir, "prod.yaml.age") + "#port", want: "5432"},
+		{ref: "age://" + filepath.Join(dir, "prod.yaml.age") + "#missing", wantErr: `key "missing" not found`},
+		{ref: "age://" + filepath.Join(dir, "prod.yaml.age") + "#nested", wantErr: `key "nested" is not a scalar value`},
+		{ref: "age://" + filepath.Join(dir, "other.age"), wantErr: "decrypting"},
+		{ref: "age://" + filepath.Join(dir, "missing.age"), wantErr: "reading secret file"},
+	}
+	for _, tt := range tests {
+		got, ok, err := r.Resolve(ctx, tt.ref)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Resolve(%q) error = %v, want %q", tt.ref, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || !ok || got != tt.want {
+			t.Errorf("Resolve(%q) = %q, %v, %v, want %q", tt.ref, got, ok, err, tt.want)
+		}
+	}
+
+	if _, ok, err := r.Resolve(ctx, "env://HOME"); ok || err != nil {
+		t.Errorf("age resolver should not handle env:// references, got %v, %v", ok, err)
+	}
+
+	if _, err := terrakube.AgeSecretResolver(filepath.Join(dir, "missing.txt")); err == nil {
+		t.Error("expected error for a missing identity file")
+	}
+}
+
+func TestWithSecretResolver(t *testing.T) {
+	t.Parallel()
+
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("POST /api/v1/organization/org-1/workspace/ws-1/variable", func(w http.ResponseWriter, r *http.Request) {
+		attrs := decodeAttributes(t, r)
+		if attrs["value"] != "resolved" || attrs["sensitive"] != true {
+			t.Errorf("sent value=%v sensitive=%v, want resolved and sensitive", attrs["value"], attrs["sensitive"])
+		}
+		testutil.WriteJSONAPI(t, w, http.StatusCreated, &terrakube.Variable{ID: "var-1", Key: "token", Sensitive: true})
+	})
+	srv.HandleFunc("POST /api/v1/organization/org-1/globalvar", func(w http.ResponseWriter, r *http.Request) {
+		attrs := decodeAttributes(t, r)
+		if attrs["value"] != "plain" || attrs["sensitive"] == true {
+			t.Errorf("sent value=%v sensitive=%v, want an unchanged plain value", attrs["value"], attrs["sensitive"])
+		}
+		testutil.WriteJSONAPI(t, w, http.StatusCreated, &terrakube.OrganizationVariable{ID: "gv-1", Key: "region"})
+	})
+	srv.HandleFunc("PATCH /api/v1/organization/org-1/collection/col-1/item/item-1", func(w http.ResponseWriter, r *http.Request) {
+		attrs := decodeAttributes(t, r)
+		if attrs["value"] != `{ a = 1 }` || attrs["sensitive"] != true {
+			t.Errorf("sent value=%v sensitive=%v, want resolved and sensitive", attrs["value"], attrs["sensitive"])
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	resolver := terrakube.SecretResolverFunc(func(_ context.Context, ref string) (string, bool, error) {
+		switch ref {
+		case "test://token":
+			return "resolved", true, nil
+		case "test://hcl":
+			return `{ a = 1 }`, true, nil
+		case "test://broken":
+			return "", true, errors.New("backend unavailable")
+		}
+		return "", false, nil
+	})
+	c, err := terrakube.NewClient(
+		terrakube.WithEndpoint(srv.URL),
+		terrakube.WithToken("test-token"),
+		terrakube.WithSecretResolver(resolver),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	v := &terrakube.Variable{Key: "token", Value: "test://token", Category: terrakube.CategoryEnv}
+	if _, err := c.Variables.Create(ctx, "org-1", "ws-1", v); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v.Value != "test://token" || v.Sensitive {
+		t.Errorf("caller's variable was modified: %+v", v)
+	}
+
+	if _, err := c.OrganizationVariables.Create(ctx, "org-1", &terrakube.OrganizationVariable{Key: "region", Value: "plain"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	item := &terrakube.CollectionItem{ID: "item-1", Key: "cfg", Value: "test://hcl", Hcl: true}
+	if _, err := c.CollectionItems.Update(ctx, "org-1", "col-1", item); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err = c.Variables.Create(ctx, "org-1", "ws-1", &terrakube.Variable{Key: "db", Value: "test://broken"})
+	var serr *terrakube.SecretError
+	if !errors.As(err, &serr) || serr.Key != "db" || serr.Ref != "test://broken" {
+		t.Errorf("expected a *SecretError for db, got %v", err)
+	}
+
+	if _, err := terrakube.NewClient(terrakube.WithEndpoint(srv.URL), terrakube.WithToken("t"), terrakube.WithSecretResolver(nil)); err == nil {
+		t.Error("expected error for a nil resolver")
+	}
+}
@@ -0,0 +1,236 @@
+package terrakube
+
+import (
+	"context"
+	"crypto/md5" //nolint:gosec // MD5 is the checksum Terraform state versions are tracked by
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// stateArchivePath is where raw state files are uploaded for a history entry.
+const stateArchivePath = "/tfstate/v1/archive"
+
+// Sentinel errors returned by the HistoryService state helpers.
+var (
+	// ErrNoState indicates a workspace has no state history yet.
+	ErrNoState = errors.New("workspace has no state history")
+	// ErrLineageMismatch indicates the uploaded state belongs to a different lineage.
+	ErrLineageMismatch = errors.New("state lineage does not match the workspace state")
+	// ErrStaleSerial indicates the uploaded state is not newer than the workspace state.
+	ErrStaleSerial = errors.New("state serial is not greater than the workspace state serial")
+)
+
+// State is a Terraform state file in the version 4 format.
+type State struct {
+	Version          int                     `json:"version"`
+	TerraformVersion string                  `json:"terraform_version"`
+	Serial           int64                   `json:"serial"`
+	Lineage          string                  `json:"lineage"`
+	Outputs          map[string]*StateOutput `json:"outputs"`
+	Resources        []*StateResource        `json:"resources"`
+}
+
+// StateOutput is a root module output value recorded in the state.
+type StateOutput struct {
+	Value interface{} `json:"value"`
+	// Type is the output's type constraint in Terraform's JSON type syntax,
+	// for example "string" or ["list","string"].
+	Type      json.RawMessage `json:"type,omitempty"`
+	Sensitive bool            `json:"sensitive,omitempty"`
+}
+
+// StateResource is a resource block recorded in the state, with one entry per instance.
+type StateResource struct {
+	Module    string           `json:"module,omitempty"`
+	Mode      string           `json:"mode"`
+	Type      string           `json:"type"`
+	Name      string           `json:"name"`
+	Each      string           `json:"each,omitempty"`
+	Provider  string           `json:"provider"`
+	Instances []*StateInstance `json:"instances"`
+}
+
+// StateInstance is a single instance of a state resource.
+type StateInstance struct {
+	// IndexKey is a float64 for count instances, a string for for_each
+	// instances, and nil for single instances.
+	IndexKey            interface{}            `json:"index_key,omitempty"`
+	Status              string                 `json:"status,omitempty"`
+	Deposed             string                 `json:"deposed,omitempty"`
+	SchemaVersion       int                    `json:"schema_version"`
+	Attributes          map[string]interface{} `json:"attributes,omitempty"`
+	SensitiveAttributes json.RawMessage        `json:"sensitive_attributes,omitempty"`
+	Private             string                 `json:"private,omitempty"`
+	Dependencies        []string               `json:"dependencies,omitempty"`
+	CreateBeforeDestroy bool                   `json:"create_before_destroy,omitempty"`
+}
+
+// ParseState decodes a Terraform state file. Only the version 4 format,
+// written by Terraform 0.12 and later, is supported.
+func ParseState(data []byte) (*State, error) {
+	state := &State{}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("decoding terraform state: %w", err)
+	}
+	if state.Version != 4 {
+		return nil, fmt.Errorf("decoding terraform state: unsupported version %d", state.Version)
+	}
+	return state, nil
+}
+
+// Address returns the resource address, including its module path, for
+// example "module.db.aws_db_instance.main" or "data.aws_ami.ubuntu".
+func (r *StateResource) Address() string {
+	addr := r.Type + "." + r.Name
+	if r.Mode == "data" {
+		addr = "data." + addr
+	}
+	if r.Module != "" {
+		addr = r.Module + "." + addr
+	}
+	return addr
+}
+
+// InstanceAddress returns the address of one of the resource's instances,
+// for example `aws_instance.web[0]` or `aws_instance.web["a"]`.
+func (r *StateResource) InstanceAddress(inst *StateInstance) string {
+	addr := r.Address()
+	switch k := inst.IndexKey.(type) {
+	case float64:
+		addr += "[" + strconv.FormatFloat(k, 'f', -1, 64) + "]"
+	case string:
+		addr += "[" + strconv.Quote(k) + "]"
+	}
+	if inst.Deposed != "" {
+		addr += " (deposed " + inst.Deposed + ")"
+	}
+	return addr
+}
+
+// StateChecksum returns the hex-encoded MD5 checksum Terrakube records for a state file.
+func StateChecksum(data []byte) string {
+	sum := md5.Sum(data) //nolint:gosec // See import comment.
+	return hex.EncodeToString(sum[:])
+}
+
+// DownloadState fetches the raw state file recorded by a history entry.
+// It returns a *ValidationError if orgID, workspaceID, or id is empty or the
+// entry has no state reference, and a *APIError on server errors.
+func (s *HistoryService) DownloadState(ctx context.Context, orgID, workspaceID, id string) ([]byte, error) {
+	h, err := s.Get(ctx, orgID, workspaceID, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.downloadState(ctx, h)
+}
+
+// downloadState fetches the state file referenced by a fetched history entry.
+func (s *HistoryService) downloadState(ctx context.Context, h *History) ([]byte, error) {
+	if h.Output == "" {
+		return nil, &ValidationError{Field: "output", Message: fmt.Sprintf("history %s has no state reference", h.ID)}
+	}
+	return s.client.download(ctx, h.Output)
+}
+
+// UploadState records a new state version for a workspace. The state must
+// parse as a version 4 state; when the workspace already has state, its
+// lineage must match and its serial must be greater than the latest serial.
+// The checksum, serial, and lineage are computed from the state itself, and
+// the entry's Output is set to the uploaded archive so DownloadState can read
+// it back. If the upload fails, the new entry is deleted again.
+// It returns a *ValidationError if orgID or workspaceID is empty, an error
+// wrapping ErrLineageMismatch or ErrStaleSerial if validation fails, and a
+// *APIError on server errors.
+func (s *HistoryService) UploadState(ctx context.Context, orgID, workspaceID string, data []byte) (*History, error) {
+	if err := validateID("organizationID", orgID); err != nil {
+		return nil, err
+	}
+	if err := validateID("workspaceID", workspaceID); err != nil {
+		return nil, err
+	}
+
+	state, err := ParseState(data)
+	if err != nil {
+		return nil, err
+	}
+
+	latest, err := s.Latest(ctx, orgID, workspaceID)
+	if err != nil && !errors.Is(err, ErrNoState) {
+		return nil, err
+	}
+	if latest != nil {
+		if latest.Lineage != nil && *latest.Lineage != "" && *latest.Lineage != state.Lineage {
+			return nil, fmt.Errorf("%w: got %q, workspace has %q", ErrLineageMismatch, state.Lineage, *latest.Lineage)
+		}
+		if state.Serial <= int64(latest.Serial) {
+			return nil, fmt.Errorf("%w: got %d, workspace has %d", ErrStaleSerial, state.Serial, latest.Serial)
+		}
+	}
+
+	md5sum := StateChecksum(data)
+	created, err := s.Create(ctx, orgID, workspaceID, &History{
+		Serial:  int(state.Serial),
+		Md5:     &md5sum,
+		Lineage: &state.Lineage,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The archive path needs the entry's ID, so the entry is created first and
+	// removed again if the state never makes it into the archive.
+	archive := strings.Join([]string{stateArchivePath, created.ID, "terraform.tfstate"}, "/")
+	if err := s.client.upload(ctx, http.MethodPut, archive, jsonType, data); err != nil {
+		return nil, s.discard(ctx, orgID, workspaceID, created.ID, err)
+	}
+
+	created.Output = s.client.baseURL.ResolveReference(&url.URL{Path: archive}).String()
+	if _, err := s.Update(ctx, orgID, workspaceID, &History{
+		ID:      created.ID,
+		Output:  created.Output,
+		Serial:  created.Serial,
+		Md5:     &md5sum,
+		Lineage: &state.Lineage,
+	}); err != nil {
+		return nil, s.discard(ctx, orgID, workspaceID, created.ID, err)
+	}
+
+	return created, nil
+}
+
+// discard deletes a history entry whose state could not be stored and
+// returns cause, joined with any error from the delete.
+func (s *HistoryService) discard(ctx context.Context, orgID, workspaceID, id string, cause error) error {
+	if err := s.Delete(ctx, orgID, workspaceID, id); err != nil {
+		return errors.Join(cause, fmt.Errorf("removing history %s: %w", id, err))
+	}
+	return cause
+}
+
+// Latest returns the workspace's history entry with the highest serial.
+// It returns a *ValidationError if orgID or workspaceID is empty, an error
+// wrapping ErrNoState if the workspace has no history, and a *APIError on
+// server errors.
+func (s *HistoryService) Latest(ctx context.Context, orgID, workspaceID string) (*History, error) {
+	entries, err := s.List(ctx, orgID, workspaceID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var latest *History
+	for _, h := range entries {
+		if latest == nil || h.Serial > latest.Serial {
+			latest = h
+		}
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("workspace %s: %w", workspaceID, ErrNoState)
+	}
+	return latest, nil
+}
@@ -0,0 +1,377 @@
+package terrakube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SensitiveMask replaces sensitive values in state diffs.
+const SensitiveMask = "(sensitive)"
+
+// StateDiff describes the changes between two Terraform states.
+type StateDiff struct {
+	FromSerial int64           `json:"from_serial"`
+	ToSerial   int64           `json:"to_serial"`
+	Added      []*InstanceDiff `json:"added"`
+	Removed    []*InstanceDiff `json:"removed"`
+	Modified   []*InstanceDiff `json:"modified"`
+	Outputs    []*OutputDiff   `json:"outputs"`
+}
+
+// InstanceDiff describes a resource instance that was added, removed, or
+// modified. For added and removed instances Attributes lists every attribute
+// with only After or Before set.
+type InstanceDiff struct {
+	Address    string           `json:"address"`
+	Attributes []*AttributeDiff `json:"attributes,omitempty"`
+}
+
+// AttributeDiff describes a changed attribute, identified by its path such as
+// "tags.Name" or "ingress[0].cidr_blocks[1]". Sensitive values are replaced
+// by SensitiveMask.
+type AttributeDiff struct {
+	Path      string      `json:"path"`
+	Before    interface{} `json:"before"`
+	After     interface{} `json:"after"`
+	Sensitive bool        `json:"sensitive,omitempty"`
+}
+
+// OutputDiff describes a root module output that was added, removed, or
+// changed. Sensitive values are replaced by SensitiveMask.
+type OutputDiff struct {
+	Name      string      `json:"name"`
+	Action    PlanAction  `json:"action"`
+	Before    interface{} `json:"before"`
+	After     interface{} `json:"after"`
+	Sensitive bool        `json:"sensitive,omitempty"`
+}
+
+// Diff downloads two history entries of a workspace and compares their states.
+// It returns a *ValidationError if any ID is empty and a *APIError on server errors.
+func (s *HistoryService) Diff(ctx context.Context, orgID, workspaceID, fromID, toID string) (*StateDiff, error) {
+	if err := validateID("organizationID", orgID); err != nil {
+		return nil, err
+	}
+	if err := validateID("workspaceID", workspaceID); err != nil {
+		return nil, err
+	}
+	if err := validateID("fromID", fromID); err != nil {
+		return nil, err
+	}
+	if err := validateID("toID", toID); err != nil {
+		return nil, err
+	}
+
+	var states [2]*State
+	for i, id := range []string{fromID, toID} {
+		data, err := s.DownloadState(ctx, orgID, workspaceID, id)
+		if err != nil {
+			return nil, err
+		}
+		states[i], err = ParseState(data)
+		if err != nil {
+			return nil, fmt.Errorf("history %s: %w", id, err)
+		}
+	}
+
+	return DiffStates(states[0], states[1]), nil
+}
+
+// DiffStates compares two states and returns the instance and output changes,
+// sorted by address and name.
+func DiffStates(from, to *State) *StateDiff {
+	d := &StateDiff{FromSerial: from.Serial, ToSerial: to.Serial}
+
+	before := stateInstances(from)
+	after := stateInstances(to)
+
+	for addr, b := range before {
+		a, ok := after[addr]
+		if !ok {
+			d.Removed = append(d.Removed, &InstanceDiff{Address: addr, Attributes: diffAttributes(b, nil)})
+			continue
+		}
+		if attrs := diffAttributes(b, a); len(attrs) > 0 {
+			d.Modified = append(d.Modified, &InstanceDiff{Address: addr, Attributes: attrs})
+		}
+	}
+	for addr, a := range after {
+		if _, ok := before[addr]; !ok {
+			d.Added = append(d.Added, &InstanceDiff{Address: addr, Attributes: diffAttributes(nil, a)})
+		}
+	}
+
+	for name, b := range from.Outputs {
+		a, ok := to.Outputs[name]
+		switch {
+		case !ok:
+			d.Outputs = append(d.Outputs, newOutputDiff(name, PlanActionDelete, b, nil))
+		case !reflect.DeepEqual(b.Value, a.Value) || b.Sensitive != a.Sensitive:
+			d.Outputs = append(d.Outputs, newOutputDiff(name, PlanActionUpdate, b, a))
+		}
+	}
+	for name, a := range to.Outputs {
+		if _, ok := from.Outputs[name]; !ok {
+			d.Outputs = append(d.Outputs, newOutputDiff(name, PlanActionCreate, nil, a))
+		}
+	}
+
+	for _, list := range [][]*InstanceDiff{d.Added, d.Removed, d.Modified} {
+		sort.Slice(list, func(i, j int) bool { return list[i].Address < list[j].Address })
+	}
+	sort.Slice(d.Outputs, func(i, j int) bool { return d.Outputs[i].Name < d.Outputs[j].Name })
+	return d
+}
+
+// IsEmpty reports whether the diff contains no changes.
+func (d *StateDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Modified) == 0 && len(d.Outputs) == 0
+}
+
+// JSON renders the diff as indented JSON.
+func (d *StateDiff) JSON() ([]byte, error) {
+	return json.MarshalIndent(d, "", "  ")
+}
+
+// Text renders the diff as plain text in a layout similar to a Terraform plan.
+func (d *StateDiff) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "State serial %d -> %d\n", d.FromSerial, d.ToSerial)
+	if d.IsEmpty() {
+		b.WriteString("\nNo changes.\n")
+		return b.String()
+	}
+
+	section := func(title, marker string, list []*InstanceDiff, withAttrs bool) {
+		if len(list) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n%s:\n", title)
+		for _, inst := range list {
+			fmt.Fprintf(&b, "  %s %s\n", marker, inst.Address)
+			if !withAttrs {
+				continue
+			}
+			for _, attr := range inst.Attributes {
+				fmt.Fprintf(&b, "      %s: %s -> %s\n", attr.Path, formatDiffValue(attr.Before), formatDiffValue(attr.After))
+			}
+		}
+	}
+	section("Added", "+", d.Added, false)
+	section("Removed", "-", d.Removed, false)
+	section("Modified", "~", d.Modified, true)
+
+	if len(d.Outputs) > 0 {
+		b.WriteString("\nOutputs:\n")
+		for _, o := range d.Outputs {
+			switch o.Action {
+			case PlanActionCreate:
+				fmt.Fprintf(&b, "  + %s = %s\n", o.Name, formatDiffValue(o.After))
+			case PlanActionDelete:
+				fmt.Fprintf(&b, "  - %s = %s\n", o.Name, formatDiffValue(o.Before))
+			default:
+				fmt.Fprintf(&b, "  ~ %s: %s -> %s\n", o.Name, formatDiffValue(o.Before), formatDiffValue(o.After))
+			}
+		}
+	}
+	return b.String()
+}
+
+// formatDiffValue renders a value for the text diff.
+func formatDiffValue(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		if t == SensitiveMask {
+			return t
+		}
+		return strconv.Quote(t)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
+
+// newOutputDiff builds an output diff, masking values if either side is sensitive.
+func newOutputDiff(name string, action PlanAction, before, after *StateOutput) *OutputDiff {
+	od := &OutputDiff{Name: name, Action: action}
+	if before != nil {
+		od.Before = before.Value
+		od.Sensitive = before.Sensitive
+	}
+	if after != nil {
+		od.After = after.Value
+		od.Sensitive = od.Sensitive || after.Sensitive
+	}
+	if od.Sensitive {
+		if before != nil {
+			od.Before = SensitiveMask
+		}
+		if after != nil {
+			od.After = SensitiveMask
+		}
+	}
+	return od
+}
+
+// stateInstance pairs an instance's flattened attributes with its sensitive paths.
+type stateInstance struct {
+	attrs     map[string]interface{}
+	sensitive []string
+}
+
+// stateInstances indexes a state's resource instances by address.
+func stateInstances(s *State) map[string]*stateInstance {
+	out := map[string]*stateInstance{}
+	for _, r := range s.Resources {
+		for _, inst := range r.Instances {
+			flat := map[string]interface{}{}
+			flattenAttributes("", inst.Attributes, flat)
+			out[r.InstanceAddress(inst)] = &stateInstance{
+				attrs:     flat,
+				sensitive: sensitivePaths(inst.SensitiveAttributes),
+			}
+		}
+	}
+	return out
+}
+
+// diffAttributes compares two flattened instances. Either side may be nil.
+func diffAttributes(before, after *stateInstance) []*AttributeDiff {
+	paths := map[string]bool{}
+	var sensitive []string
+	for _, si := range []*stateInstance{before, after} {
+		if si == nil {
+			continue
+		}
+		for p := range si.attrs {
+			paths[p] = true
+		}
+		sensitive = append(sensitive, si.sensitive...)
+	}
+
+	var diffs []*AttributeDiff
+	for p := range paths {
+		var b, a interface{}
+		var hasB, hasA bool
+		if before != nil {
+			b, hasB = before.attrs[p]
+		}
+		if after != nil {
+			a, hasA = after.attrs[p]
+		}
+		if hasB == hasA && reflect.DeepEqual(b, a) {
+			continue
+		}
+
+		ad := &AttributeDiff{Path: p, Before: b, After: a, Sensitive: isSensitivePath(p, sensitive)}
+		if ad.Sensitive {
+			if hasB {
+				ad.Before = SensitiveMask
+			}
+			if hasA {
+				ad.After = SensitiveMask
+			}
+		}
+		diffs = append(diffs, ad)
+	}
+
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Path < diffs[j].Path })
+	return diffs
+}
+
+// flattenAttributes flattens nested attribute values into dotted and indexed paths.
+func flattenAttributes(prefix string, v interface{}, out map[string]interface{}) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		if len(t) == 0 && prefix != "" {
+			out[prefix] = t
+		}
+		for k, e := range t {
+			p := k
+			if prefix != "" {
+				p = prefix + "." + k
+			}
+			flattenAttributes(p, e, out)
+		}
+	case []interface{}:
+		if len(t) == 0 {
+			out[prefix] = t
+		}
+		for i, e := range t {
+			flattenAttributes(prefix+"["+strconv.Itoa(i)+"]", e, out)
+		}
+	default:
+		out[prefix] = v
+	}
+}
+
+// sensitivePaths converts state v4 sensitive_attributes, a list of path step
+// lists, into attribute paths matching flattenAttributes.
+func sensitivePaths(raw json.RawMessage) []string {
+	if len(raw) == 0 {
+		return nil
+	}
+	var steps [][]struct {
+		Type  string          `json:"type"`
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(raw, &steps); err != nil {
+		return nil
+	}
+
+	var paths []string
+	for _, path := range steps {
+		var b strings.Builder
+		for _, step := range path {
+			switch step.Type {
+			case "get_attr":
+				var name string
+				if json.Unmarshal(step.Value, &name) == nil {
+					if b.Len() > 0 {
+						b.WriteByte('.')
+					}
+					b.WriteString(name)
+				}
+			case "index":
+				var key struct {
+					Value interface{} `json:"value"`
+				}
+				if json.Unmarshal(step.Value, &key) != nil {
+					continue
+				}
+				switch k := key.Value.(type) {
+				case float64:
+					b.WriteString("[" + strconv.FormatFloat(k, 'f', -1, 64) + "]")
+				case string:
+					if b.Len() > 0 {
+						b.WriteByte('.')
+					}
+					b.WriteString(k)
+				}
+			}
+		}
+		if b.Len() > 0 {
+			paths = append(paths, b.String())
+		}
+	}
+	return paths
+}
+
+// isSensitivePath reports whether p equals or is nested under a sensitive path.
+func isSensitivePath(p string, sensitive []string) bool {
+	for _, s := range sensitive {
+		if p == s || strings.HasPrefix(p, s+".") || strings.HasPrefix(p, s+"[") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,202 @@
+package terrakube_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	terrakube "github.com/terrakube-io/terrakube-go"
+	"github.com/terrakube-io/terrakube-go/testutil"
+)
+
+const testStateFromJSON = `{
+  "version": 4, "serial": 1, "lineage": "lin-1",
+  "outputs": {
+    "vpc_id": {"value": "vpc-1", "type": "string"},
+    "old": {"value": 1, "type": "number"},
+    "token": {"value": "a", "type": "string", "sensitive": true}
+  },
+  "resources": [
+    {"mode": "managed", "type": "aws_db_instance", "name": "main", "provider": "aws",
+     "instances": [{"schema_version": 0,
+       "attributes": {"id": "db-1", "password": "old-secret", "tags": {"env": "dev"}},
+       "sensitive_attributes": [[{"type": "get_attr", "value": "password"}]]}]},
+    {"mode": "managed", "type": "aws_iam_role", "name": "legacy", "provider": "aws",
+     "instances": [{"schema_version": 0, "attributes": {"id": "role-1"}}]}
+  ]
+}`
+
+const testStateToJSON = `{
+  "version": 4, "serial": 2, "lineage": "lin-1",
+  "outputs": {
+    "vpc_id": {"value": "vpc-2", "type": "string"},
+    "new": {"value": true, "type": "bool"},
+    "token": {"value": "b", "type": "string", "sensitive": true}
+  },
+  "resources": [
+    {"mode": "managed", "type": "aws_db_instance", "name": "main", "provider": "aws",
+     "instances": [{"schema_version": 0,
+       "attributes": {"id": "db-1", "password": "new-secret", "tags": {"env": "prod"}},
+       "sensitive_attributes": [[{"type": "get_attr", "value": "password"}]]}]},
+    {"mode": "managed", "type": "aws_instance", "name": "web", "provider": "aws",
+     "instances": [{"index_key": "a", "schema_version": 0, "attributes": {"id": "i-1"}}]}
+  ]
+}`
+
+func parseTestState(t *testing.T, data string) *terrakube.State {
+	t.Helper()
+	state, err := terrakube.ParseState([]byte(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return state
+}
+
+func TestDiffStates(t *testing.T) {
+	t.Parallel()
+
+	d := terrakube.DiffStates(parseTestState(t, testStateFromJSON), parseTestState(t, testStateToJSON))
+
+	if len(d.Added) != 1 || d.Added[0].Address != `aws_instance.web["a"]` {
+		t.Errorf("Added = %+v, want aws_instance.web[\"a\"]", d.Added)
+	}
+	if len(d.Removed) != 1 || d.Removed[0].Address != "aws_iam_role.legacy" {
+		t.Errorf("Removed = %+v, want aws_iam_role.legacy", d.Removed)
+	}
+	if len(d.Modified) != 1 {
+		t.Fatalf("got %d modified, want 1", len(d.Modified))
+	}
+
+	attrs := d.Modified[0].Attributes
+	if len(attrs) != 2 {
+		t.Fatalf("got %d attribute diffs, want 2: %+v", len(attrs), attrs)
+	}
+	if attrs[0].Path != "password" || !attrs[0].Sensitive || attrs[0].Before != terrakube.SensitiveMask || attrs[0].After != terrakube.SensitiveMask {
+		t.Errorf("password diff = %+v, want masked sensitive change", attrs[0])
+	}
+	if attrs[1].Path != "tags.env" || attrs[1].Before != "dev" || attrs[1].After != "prod" {
+		t.Errorf("tags diff = %+v, want dev -> prod", attrs[1])
+	}
+
+	wantOutputs := map[string]terrakube.PlanAction{
+		"new":    terrakube.PlanActionCreate,
+		"old":    terrakube.PlanActionDelete,
+		"token":  terrakube.PlanActionUpdate,
+		"vpc_id": terrakube.PlanActionUpdate,
+	}
+	if len(d.Outputs) != len(wantOutputs) {
+		t.Fatalf("got %d output diffs, want %d", len(d.Outputs), len(wantOutputs))
+	}
+	for _, o := range d.Outputs {
+		if o.Action != wantOutputs[o.Name] {
+			t.Errorf("output %s action = %s, want %s", o.Name, o.Action, wantOutputs[o.Name])
+		}
+		if o.Name == "token" && (o.Before != terrakube.SensitiveMask || o.After != terrakube.SensitiveMask) {
+			t.Errorf("token output not masked: %+v", o)
+		}
+	}
+}
+
+func TestDiffStates_NoChanges(t *testing.T) {
+	t.Parallel()
+
+	state := parseTestState(t, testStateFromJSON)
+	d := terrakube.DiffStates(state, state)
+	if !d.IsEmpty() {
+		t.Errorf("expected empty diff, got %+v", d)
+	}
+	if !strings.Contains(d.Text(), "No changes.") {
+		t.Errorf("Text() = %q, want no changes note", d.Text())
+	}
+}
+
+func TestStateDiff_Render(t *testing.T) {
+	t.Parallel()
+
+	d := terrakube.DiffStates(parseTestState(t, testStateFromJSON), parseTestState(t, testStateToJSON))
+
+	text := d.Text()
+	for _, want := range []string{
+		"State serial 1 -> 2",
+		"Added:\n  + aws_instance.web[\"a\"]",
+		"Removed:\n  - aws_iam_role.legacy",
+		"  ~ aws_db_instance.main\n      password: (sensitive) -> (sensitive)\n      tags.env: \"dev\" -> \"prod\"",
+		"  + new = true",
+		"  ~ vpc_id: \"vpc-1\" -> \"vpc-2\"",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Text() missing %q\n%s", want, text)
+		}
+	}
+	for _, secret := range []string{"old-secret", "new-secret"} {
+		if strings.Contains(text, secret) {
+			t.Errorf("Text() leaks %q", secret)
+		}
+	}
+
+	data, err := d.JSON()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(string(data), "secret") {
+		t.Errorf("JSON() leaks sensitive values: %s", data)
+	}
+	var decoded terrakube.StateDiff
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("JSON() output does not round-trip: %v", err)
+	}
+	if decoded.ToSerial != 2 || len(decoded.Modified) != 1 {
+		t.Errorf("decoded diff = %+v", decoded)
+	}
+}
+
+func TestHistoryService_Diff(t *testing.T) {
+	t.Parallel()
+
+	srv := testutil.NewServer(t)
+	for id, state := range map[string]string{"h-1": testStateFromJSON, "h-2": testStateToJSON} {
+		body := state
+		srv.HandleFunc("GET /api/v1/organization/org-1/workspace/ws-1/history/"+id, func(w http.ResponseWriter, _ *http.Request) {
+			testutil.WriteJSONAPI(t, w, http.StatusOK, &terrakube.History{ID: id, Output: "/tfstate/" + id + ".json"})
+		})
+		srv.HandleFunc("GET /tfstate/"+id+".json", func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(body))
+		})
+	}
+
+	client := newTestClient(t, srv)
+
+	d, err := client.History.Diff(context.Background(), "org-1", "ws-1", "h-1", "h-2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d.FromSerial != 1 || d.ToSerial != 2 {
+		t.Errorf("serials = %d -> %d, want 1 -> 2", d.FromSerial, d.ToSerial)
+	}
+}
+
+func TestHistoryService_Diff_EmptyIDs(t *testing.T) {
+	t.Parallel()
+
+	client := newTestClientFromURL(t, "https://example.com")
+
+	tests := []struct {
+		name   string
+		fromID string
+		toID   string
+		field  string
+	}{
+		{"empty from ID", "", "h-2", "fromID"},
+		{"empty to ID", "h-1", "", "toID"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := client.History.Diff(context.Background(), "org-1", "ws-1", tt.fromID, tt.toID)
+			assertValidationError(t, err, tt.field)
+		})
+	}
+}
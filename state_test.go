@@ -0,0 +1,291 @@
+package terrakube_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	terrakube "github.com/terrakube-io/terrakube-go"
+	"github.com/terrakube-io/terrakube-go/testutil"
+)
+
+const testStateJSON = `{
+  "version": 4,
+  "terraform_version": "1.7.5",
+  "serial": 7,
+  "lineage": "lin-1",
+  "outputs": {
+    "vpc_id": {"value": "vpc-123", "type": "string"},
+    "db_password": {"value": "hunter2", "type": "string", "sensitive": true}
+  },
+  "resources": [
+    {
+      "mode": "managed",
+      "type": "aws_instance",
+      "name": "web",
+      "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+      "instances": [
+        {"index_key": 0, "schema_version": 1, "attributes": {"id": "i-1"}, "dependencies": ["aws_vpc.main"]},
+        {"index_key": 1, "schema_version": 1, "attributes": {"id": "i-2"}}
+      ]
+    },
+    {
+      "module": "module.net",
+      "mode": "data",
+      "type": "aws_ami",
+      "name": "ubuntu",
+      "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+      "instances": [{"schema_version": 0, "attributes": {"id": "ami-1"}}]
+    }
+  ]
+}`
+
+func TestParseState(t *testing.T) {
+	t.Parallel()
+
+	state, err := terrakube.ParseState([]byte(testStateJSON))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state.Serial != 7 || state.Lineage != "lin-1" {
+		t.Errorf("Serial, Lineage = %d, %q; want 7, %q", state.Serial, state.Lineage, "lin-1")
+	}
+	if !state.Outputs["db_password"].Sensitive {
+		t.Error("db_password output should be sensitive")
+	}
+	if len(state.Resources) != 2 {
+		t.Fatalf("got %d resources, want 2", len(state.Resources))
+	}
+
+	web := state.Resources[0]
+	if got := web.InstanceAddress(web.Instances[1]); got != "aws_instance.web[1]" {
+		t.Errorf("InstanceAddress = %q, want %q", got, "aws_instance.web[1]")
+	}
+	if deps := web.Instances[0].Dependencies; len(deps) != 1 || deps[0] != "aws_vpc.main" {
+		t.Errorf("Dependencies = %v, want [aws_vpc.main]", deps)
+	}
+	if got := state.Resources[1].Address(); got != "module.net.data.aws_ami.ubuntu" {
+		t.Errorf("Address = %q, want %q", got, "module.net.data.aws_ami.ubuntu")
+	}
+}
+
+func TestParseState_UnsupportedVersion(t *testing.T) {
+	t.Parallel()
+
+	if _, err := terrakube.ParseState([]byte(`{"version": 3, "serial": 1}`)); err == nil {
+		t.Fatal("expected error for version 3 state")
+	}
+}
+
+func TestStateChecksum(t *testing.T) {
+	t.Parallel()
+
+	if got := terrakube.StateChecksum([]byte("abc")); got != "900150983cd24fb0d6963f7d28e17f72" {
+		t.Errorf("StateChecksum = %q", got)
+	}
+}
+
+func TestHistoryService_DownloadState(t *testing.T) {
+	t.Parallel()
+
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("GET /api/v1/organization/org-1/workspace/ws-1/history/h-1", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSONAPI(t, w, http.StatusOK, &terrakube.History{
+			ID:     "h-1",
+			Serial: 7,
+			Output: "/tfstate/v1/organization/org-1/workspace/ws-1/state/h-1.json",
+		})
+	})
+	srv.HandleFunc("GET /tfstate/v1/organization/org-1/workspace/ws-1/state/h-1.json", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(testStateJSON))
+	})
+
+	client := newTestClient(t, srv)
+
+	data, err := client.History.DownloadState(context.Background(), "org-1", "ws-1", "h-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(data) != testStateJSON {
+		t.Error("downloaded state does not match")
+	}
+}
+
+func TestHistoryService_DownloadState_NoOutput(t *testing.T) {
+	t.Parallel()
+
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("GET /api/v1/organization/org-1/workspace/ws-1/history/h-1", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSONAPI(t, w, http.StatusOK, &terrakube.History{ID: "h-1"})
+	})
+
+	client := newTestClient(t, srv)
+
+	_, err := client.History.DownloadState(context.Background(), "org-1", "ws-1", "h-1")
+	var ve *terrakube.ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("expected *ValidationError, got %T: %v", err, err)
+	}
+}
+
+func TestHistoryService_UploadState(t *testing.T) {
+	t.Parallel()
+
+	lineage := "lin-1"
+	var uploaded []byte
+
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("GET /api/v1/organization/org-1/workspace/ws-1/history", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSONAPIList(t, w, http.StatusOK, []*terrakube.History{
+			{ID: "h-5", Serial: 5, Lineage: &lineage},
+			{ID: "h-6", Serial: 6, Lineage: &lineage},
+		})
+	})
+	srv.HandleFunc("POST /api/v1/organization/org-1/workspace/ws-1/history", func(w http.ResponseWriter, r *http.Request) {
+		attrs := decodeAttributes(t, r)
+		if attrs["serial"] != float64(7) {
+			t.Errorf("serial = %v, want 7", attrs["serial"])
+		}
+		if attrs["md5"] != terrakube.StateChecksum([]byte(testStateJSON)) {
+			t.Errorf("md5 = %v", attrs["md5"])
+		}
+		if attrs["lineage"] != "lin-1" {
+			t.Errorf("lineage = %v, want %q", attrs["lineage"], "lin-1")
+		}
+		testutil.WriteJSONAPI(t, w, http.StatusCreated, &terrakube.History{ID: "h-7", Serial: 7})
+	})
+	srv.HandleFunc("PUT /tfstate/v1/archive/h-7/terraform.tfstate", func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		uploaded, err = io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("failed to read body: %v", err)
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+	var output interface{}
+	srv.HandleFunc("PATCH /api/v1/organization/org-1/workspace/ws-1/history/h-7", func(w http.ResponseWriter, r *http.Request) {
+		attrs := decodeAttributes(t, r)
+		output = attrs["output"]
+		if attrs["serial"] != float64(7) {
+			t.Errorf("update serial = %v, want 7", attrs["serial"])
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	client := newTestClient(t, srv)
+
+	h, err := client.History.UploadState(context.Background(), "org-1", "ws-1", []byte(testStateJSON))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if h.ID != "h-7" {
+		t.Errorf("ID = %q, want %q", h.ID, "h-7")
+	}
+	want := srv.URL + "/tfstate/v1/archive/h-7/terraform.tfstate"
+	if h.Output != want || output != want {
+		t.Errorf("Output = %q, sent %v, want %q", h.Output, output, want)
+	}
+	if string(uploaded) != testStateJSON {
+		t.Error("uploaded state does not match")
+	}
+}
+
+func TestHistoryService_UploadState_UploadFails(t *testing.T) {
+	t.Parallel()
+
+	deleted := false
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("GET /api/v1/organization/org-1/workspace/ws-1/history", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSONAPIList(t, w, http.StatusOK, []*terrakube.History{})
+	})
+	srv.HandleFunc("POST /api/v1/organization/org-1/workspace/ws-1/history", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSONAPI(t, w, http.StatusCreated, &terrakube.History{ID: "h-1", Serial: 7})
+	})
+	srv.HandleFunc("PUT /tfstate/v1/archive/h-1/terraform.tfstate", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteError(t, w, http.StatusInternalServerError, "storage unavailable")
+	})
+	srv.HandleFunc("PATCH /api/v1/organization/org-1/workspace/ws-1/history/h-1", func(_ http.ResponseWriter, _ *http.Request) {
+		t.Error("unexpected history update")
+	})
+	srv.HandleFunc("DELETE /api/v1/organization/org-1/workspace/ws-1/history/h-1", func(w http.ResponseWriter, _ *http.Request) {
+		deleted = true
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	client := newTestClient(t, srv)
+
+	_, err := client.History.UploadState(context.Background(), "org-1", "ws-1", []byte(testStateJSON))
+	var apiErr *terrakube.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("expected the upload *APIError, got %v", err)
+	}
+	if !deleted {
+		t.Error("history entry for the failed upload was not deleted")
+	}
+}
+
+func TestHistoryService_UploadState_Rejected(t *testing.T) {
+	t.Parallel()
+
+	otherLineage := "lin-other"
+	sameLineage := "lin-1"
+
+	tests := []struct {
+		name    string
+		history []*terrakube.History
+		want    error
+	}{
+		{"lineage mismatch", []*terrakube.History{{ID: "h-1", Serial: 1, Lineage: &otherLineage}}, terrakube.ErrLineageMismatch},
+		{"stale serial", []*terrakube.History{{ID: "h-9", Serial: 9, Lineage: &sameLineage}}, terrakube.ErrStaleSerial},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := testutil.NewServer(t)
+			srv.HandleFunc("GET /api/v1/organization/org-1/workspace/ws-1/history", func(w http.ResponseWriter, _ *http.Request) {
+				testutil.WriteJSONAPIList(t, w, http.StatusOK, tt.history)
+			})
+			srv.HandleFunc("POST /api/v1/organization/org-1/workspace/ws-1/history", func(_ http.ResponseWriter, _ *http.Request) {
+				t.Error("unexpected history creation")
+			})
+
+			client := newTestClient(t, srv)
+
+			_, err := client.History.UploadState(context.Background(), "org-1", "ws-1", []byte(testStateJSON))
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestHistoryService_Latest_NoHistory(t *testing.T) {
+	t.Parallel()
+
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("GET /api/v1/organization/org-1/workspace/ws-1/history", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSONAPIList(t, w, http.StatusOK, []*terrakube.History{})
+	})
+
+	client := newTestClient(t, srv)
+
+	_, err := client.History.Latest(context.Background(), "org-1", "ws-1")
+	if !errors.Is(err, terrakube.ErrNoState) {
+		t.Fatalf("error = %v, want %v", err, terrakube.ErrNoState)
+	}
+}
+
+func TestHistoryService_UploadState_EmptyIDs(t *testing.T) {
+	t.Parallel()
+
+	client := newTestClientFromURL(t, "https://example.com")
+
+	_, err := client.History.UploadState(context.Background(), "", "ws-1", []byte(testStateJSON))
+	assertValidationError(t, err, "organizationID")
+	_, err = client.History.UploadState(context.Background(), "org-1", "", []byte(testStateJSON))
+	assertValidationError(t, err, "workspaceID")
+}
@@ -0,0 +1,169 @@
+package terrakube
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FlowType identifies what a template flow does.
+type FlowType string
+
+// Flow types understood by the Terrakube executor.
+const (
+	FlowTerraformPlan        FlowType = "terraformPlan"
+	FlowTerraformApply       FlowType = "terraformApply"
+	FlowTerraformDestroy     FlowType = "terraformDestroy"
+	FlowTerraformPlanDestroy FlowType = "terraformPlanDestroy"
+	FlowCustomScripts        FlowType = "customScripts"
+	FlowApproval             FlowType = "approval"
+	FlowDisableWorkspace     FlowType = "disableWorkspace"
+	FlowScheduleTemplates    FlowType = "scheduleTemplates"
+)
+
+// Runtime is the interpreter a template command's script runs in.
+type Runtime string
+
+// Runtimes supported by the Terrakube executor.
+const (
+	RuntimeGroovy Runtime = "GROOVY"
+	RuntimeBash   Runtime = "BASH"
+)
+
+// TCL is a parsed Terrakube Configuration Language document, the YAML held
+// in Template.Content and Job.Tcl.
+type TCL struct {
+	Flow []*Flow `yaml:"flow"`
+
+	// Extra holds keys this package does not model, so they survive a round trip.
+	Extra map[string]interface{} `yaml:",inline"`
+
+	// Base64 reports whether the parsed content was base64 encoded.
+	// MarshalTemplate encodes the output again when it is set.
+	Base64 bool `yaml:"-"`
+}
+
+// Flow is one stage of a template, run in ascending Step order.
+type Flow struct {
+	Type FlowType `yaml:"type"`
+	Name string   `yaml:"name,omitempty"`
+	Step int      `yaml:"step"`
+	// Team is the team allowed to approve an approval flow.
+	Team string `yaml:"team,omitempty"`
+	// IgnoreError lets the job continue when the flow fails.
+	IgnoreError bool `yaml:"ignoreError,omitempty"`
+	// InputsEnv and InputsTerraform add environment and Terraform variables
+	// to the flow.
+	InputsEnv       map[string]string `yaml:"inputsEnv,omitempty"`
+	InputsTerraform map[string]string `yaml:"inputsTerraform,omitempty"`
+	// ImportCommands loads commands from a template in a git repository.
+	ImportCommands *ImportCommands `yaml:"importComands,omitempty"`
+	Commands       []*Command      `yaml:"commands,omitempty"`
+	// Templates lists the templates a scheduleTemplates flow schedules.
+	Templates []*ScheduledTemplate `yaml:"templates,omitempty"`
+
+	Extra map[string]interface{} `yaml:",inline"`
+}
+
+// Command is a script run before or after a flow's terraform init.
+type Command struct {
+	Runtime  Runtime `yaml:"runtime"`
+	Priority int     `yaml:"priority"`
+	Before   bool    `yaml:"before,omitempty"`
+	After    bool    `yaml:"after,omitempty"`
+	Verbose  bool    `yaml:"verbose,omitempty"`
+	Script   string  `yaml:"script"`
+
+	Extra map[string]interface{} `yaml:",inline"`
+}
+
+// ImportCommands references commands defined in a git repository. The YAML
+// key keeps Terrakube's "importComands" spelling.
+type ImportCommands struct {
+	Repository      string            `yaml:"repository"`
+	Folder          string            `yaml:"folder"`
+	Branch          string            `yaml:"branch"`
+	InputsEnv       map[string]string `yaml:"inputsEnv,omitempty"`
+	InputsTerraform map[string]string `yaml:"inputsTerraform,omitempty"`
+
+	Extra map[string]interface{} `yaml:",inline"`
+}
+
+// ScheduledTemplate is a template run on a cron schedule by a
+// scheduleTemplates flow.
+type ScheduledTemplate struct {
+	Name     string `yaml:"name"`
+	Schedule string `yaml:"schedule"`
+
+	Extra map[string]interface{} `yaml:",inline"`
+}
+
+// ParseTemplate decodes TCL content such as Template.Content. Content that
+// the server stored base64 encoded is decoded first; it is recognized by
+// containing no colon, which every TCL document has. Empty content yields an
+// empty document.
+func ParseTemplate(content string) (*TCL, error) {
+	tcl, _, err := parseTemplate(content)
+	return tcl, err
+}
+
+// parseTemplate decodes TCL content and also returns the YAML document it
+// was decoded from, which records the position of every value.
+func parseTemplate(content string) (*TCL, *yaml.Node, error) {
+	tcl := &TCL{}
+	data := []byte(content)
+	if decoded, ok := decodeTemplateBase64(content); ok {
+		data = decoded
+		tcl.Base64 = true
+	}
+
+	doc := &yaml.Node{}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	if err := dec.Decode(doc); err != nil {
+		if errors.Is(err, io.EOF) {
+			return tcl, doc, nil
+		}
+		return nil, nil, fmt.Errorf("decoding template: %w", err)
+	}
+	if err := doc.Decode(tcl); err != nil {
+		return nil, nil, fmt.Errorf("decoding template: %w", err)
+	}
+	return tcl, doc, nil
+}
+
+// MarshalTemplate encodes a TCL document as YAML suitable for
+// Template.Content, base64 encoding it when t.Base64 is set.
+func MarshalTemplate(t *TCL) (string, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(t); err != nil {
+		return "", fmt.Errorf("encoding template: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return "", fmt.Errorf("encoding template: %w", err)
+	}
+	if t.Base64 {
+		return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+	}
+	return buf.String(), nil
+}
+
+// decodeTemplateBase64 decodes base64 encoded TCL content.
+func decodeTemplateBase64(content string) ([]byte, bool) {
+	s := strings.Join(strings.Fields(content), "")
+	if s == "" || strings.Contains(s, ":") {
+		return nil, false
+	}
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil || !utf8.Valid(data) {
+		return nil, false
+	}
+	return data, true
+}
@@ -0,0 +1,210 @@
+package terrakube
+
+import "errors"
+
+// stepInterval is the gap between the steps the builder assigns.
+const stepInterval = 100
+
+// TemplateBuilder composes template flows. Flows get steps 100, 200, ... in
+// the order they are added unless Step overrides them, and methods such as
+// Before, After, and Import modify the most recently added flow.
+//
+//	content, err := terrakube.NewTemplateBuilder().
+//		Plan("Plan").
+//		Approval("Approve", "OPS").
+//		Apply("Apply").
+//		Content()
+type TemplateBuilder struct {
+	flows []*Flow
+	err   error
+}
+
+// NewTemplateBuilder returns an empty TemplateBuilder.
+func NewTemplateBuilder() *TemplateBuilder {
+	return &TemplateBuilder{}
+}
+
+// PlanTemplate returns a builder for Terrakube's default plan-only template.
+func PlanTemplate() *TemplateBuilder {
+	return NewTemplateBuilder().Plan("Plan")
+}
+
+// ApplyTemplate returns a builder for Terrakube's default plan and apply template.
+func ApplyTemplate() *TemplateBuilder {
+	return NewTemplateBuilder().Plan("Plan").Apply("Apply")
+}
+
+// DestroyTemplate returns a builder for Terrakube's default destroy template.
+func DestroyTemplate() *TemplateBuilder {
+	return NewTemplateBuilder().PlanDestroy("Plan Destroy").Apply("Apply Destroy")
+}
+
+// BashCommand returns a command running script with bash.
+func BashCommand(script string) *Command {
+	return &Command{Runtime: RuntimeBash, Script: script}
+}
+
+// GroovyCommand returns a command running a Groovy script.
+func GroovyCommand(script string) *Command {
+	return &Command{Runtime: RuntimeGroovy, Script: script}
+}
+
+// Plan adds a terraform plan flow.
+func (b *TemplateBuilder) Plan(name string) *TemplateBuilder {
+	return b.add(&Flow{Type: FlowTerraformPlan, Name: name})
+}
+
+// PlanDestroy adds a terraform plan -destroy flow.
+func (b *TemplateBuilder) PlanDestroy(name string) *TemplateBuilder {
+	return b.add(&Flow{Type: FlowTerraformPlanDestroy, Name: name})
+}
+
+// Apply adds a terraform apply flow, which applies the preceding plan.
+func (b *TemplateBuilder) Apply(name string) *TemplateBuilder {
+	return b.add(&Flow{Type: FlowTerraformApply, Name: name})
+}
+
+// Destroy adds a terraform destroy flow.
+func (b *TemplateBuilder) Destroy(name string) *TemplateBuilder {
+	return b.add(&Flow{Type: FlowTerraformDestroy, Name: name})
+}
+
+// Approval adds a flow that waits for a member of team to approve the job.
+func (b *TemplateBuilder) Approval(name, team string) *TemplateBuilder {
+	return b.add(&Flow{Type: FlowApproval, Name: name, Team: team})
+}
+
+// Scripts adds a flow that runs commands outside of terraform.
+func (b *TemplateBuilder) Scripts(name string, cmds ...*Command) *TemplateBuilder {
+	b.add(&Flow{Type: FlowCustomScripts, Name: name})
+	return b.commands(false, false, cmds)
+}
+
+// DisableWorkspace adds a flow that disables the workspace.
+func (b *TemplateBuilder) DisableWorkspace(name string) *TemplateBuilder {
+	return b.add(&Flow{Type: FlowDisableWorkspace, Name: name})
+}
+
+// Step sets the step of the last flow. Later flows continue from it.
+func (b *TemplateBuilder) Step(step int) *TemplateBuilder {
+	if f := b.last(); f != nil {
+		f.Step = step
+	}
+	return b
+}
+
+// Before adds commands that run before terraform init in the last flow.
+func (b *TemplateBuilder) Before(cmds ...*Command) *TemplateBuilder {
+	return b.commands(true, false, cmds)
+}
+
+// After adds commands that run after the last flow's terraform command.
+func (b *TemplateBuilder) After(cmds ...*Command) *TemplateBuilder {
+	return b.commands(false, true, cmds)
+}
+
+// Import makes the last flow load its commands from a folder of a git repository.
+func (b *TemplateBuilder) Import(repository, folder, branch string) *TemplateBuilder {
+	if f := b.last(); f != nil {
+		f.ImportCommands = &ImportCommands{Repository: repository, Folder: folder, Branch: branch}
+	}
+	return b
+}
+
+// Env sets an environment variable for the last flow.
+func (b *TemplateBuilder) Env(name, value string) *TemplateBuilder {
+	if f := b.last(); f != nil {
+		if f.InputsEnv == nil {
+			f.InputsEnv = map[string]string{}
+		}
+		f.InputsEnv[name] = value
+	}
+	return b
+}
+
+// TerraformVar sets a Terraform variable for the last flow.
+func (b *TemplateBuilder) TerraformVar(name, value string) *TemplateBuilder {
+	if f := b.last(); f != nil {
+		if f.InputsTerraform == nil {
+			f.InputsTerraform = map[string]string{}
+		}
+		f.InputsTerraform[name] = value
+	}
+	return b
+}
+
+// IgnoreError lets the job continue when the last flow fails.
+func (b *TemplateBuilder) IgnoreError() *TemplateBuilder {
+	if f := b.last(); f != nil {
+		f.IgnoreError = true
+	}
+	return b
+}
+
+// Content returns the template as YAML for Template.Content.
+// It returns a *TemplateValidationError if the template is not valid.
+func (b *TemplateBuilder) Content() (string, error) {
+	if b.err != nil {
+		return "", b.err
+	}
+	content, err := MarshalTemplate(&TCL{Flow: b.flows})
+	if err != nil {
+		return "", err
+	}
+	if err := ValidateTemplate(content, nil); err != nil {
+		return "", err
+	}
+	return content, nil
+}
+
+// Build returns the template as a TCL document independent of the builder.
+// It returns a *TemplateValidationError if the template is not valid.
+func (b *TemplateBuilder) Build() (*TCL, error) {
+	content, err := b.Content()
+	if err != nil {
+		return nil, err
+	}
+	return ParseTemplate(content)
+}
+
+func (b *TemplateBuilder) add(f *Flow) *TemplateBuilder {
+	f.Step = stepInterval
+	if n := len(b.flows); n > 0 {
+		f.Step = b.flows[n-1].Step + stepInterval
+	}
+	b.flows = append(b.flows, f)
+	return b
+}
+
+// last returns the most recently added flow, recording an error when there
+// is none.
+func (b *TemplateBuilder) last() *Flow {
+	if len(b.flows) == 0 {
+		if b.err == nil {
+			b.err = errors.New("template builder has no flow to modify")
+		}
+		return nil
+	}
+	return b.flows[len(b.flows)-1]
+}
+
+// commands appends copies of cmds to the last flow, numbering priorities
+// 100, 200, ... where they are not set.
+func (b *TemplateBuilder) commands(before, after bool, cmds []*Command) *TemplateBuilder {
+	f := b.last()
+	if f == nil {
+		return b
+	}
+	for _, c := range cmds {
+		if c == nil {
+			continue
+		}
+		cmd := *c
+		cmd.Before, cmd.After = before, after
+		if cmd.Priority == 0 {
+			cmd.Priority = (len(f.Commands) + 1) * stepInterval
+		}
+		f.Commands = append(f.Commands, &cmd)
+	}
+	return b
+}
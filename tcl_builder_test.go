@@ -0,0 +1,126 @@
+package terrakube_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	terrakube "github.com/terrakube-io/terrakube-go"
+)
+
+func TestTemplateBuilder(t *testing.T) {
+	t.Parallel()
+
+	tcl, err := terrakube.NewTemplateBuilder().
+		Plan("Plan").
+		Import("https://github.com/AzBuilder/terrakube-extensions", "templates/terratag", "main").
+		Env("TERRATAG_VERSION", "0.1.30").
+		Before(terrakube.GroovyCommand("println 'init'"), terrakube.BashCommand("echo init")).
+		Approval("Approve", "OPS").
+		Apply("Apply").
+		After(terrakube.BashCommand("echo done")).
+		Scripts("Notify", terrakube.BashCommand("curl -X POST $WEBHOOK")).
+		IgnoreError().
+		Destroy("Cleanup").
+		Step(1000).
+		TerraformVar("region", "eu-west-1").
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantFlows := []struct {
+		typ  terrakube.FlowType
+		name string
+		step int
+	}{
+		{terrakube.FlowTerraformPlan, "Plan", 100},
+		{terrakube.FlowApproval, "Approve", 200},
+		{terrakube.FlowTerraformApply, "Apply", 300},
+		{terrakube.FlowCustomScripts, "Notify", 400},
+		{terrakube.FlowTerraformDestroy, "Cleanup", 1000},
+	}
+	if len(tcl.Flow) != len(wantFlows) {
+		t.Fatalf("got %d flows, want %d", len(tcl.Flow), len(wantFlows))
+	}
+	for i, w := range wantFlows {
+		f := tcl.Flow[i]
+		if f.Type != w.typ || f.Name != w.name || f.Step != w.step {
+			t.Errorf("flow[%d] = %s %q %d, want %s %q %d", i, f.Type, f.Name, f.Step, w.typ, w.name, w.step)
+		}
+	}
+
+	plan := tcl.Flow[0]
+	if plan.ImportCommands == nil || plan.ImportCommands.Folder != "templates/terratag" {
+		t.Errorf("ImportCommands = %+v, want folder templates/terratag", plan.ImportCommands)
+	}
+	if plan.InputsEnv["TERRATAG_VERSION"] != "0.1.30" {
+		t.Errorf("InputsEnv = %v, want TERRATAG_VERSION", plan.InputsEnv)
+	}
+	if len(plan.Commands) != 2 {
+		t.Fatalf("got %d plan commands, want 2", len(plan.Commands))
+	}
+	for i, c := range plan.Commands {
+		if !c.Before || c.After || c.Priority != (i+1)*100 {
+			t.Errorf("plan command %d = %+v, want before with priority %d", i, c, (i+1)*100)
+		}
+	}
+	if tcl.Flow[1].Team != "OPS" {
+		t.Errorf("Team = %q, want %q", tcl.Flow[1].Team, "OPS")
+	}
+	if c := tcl.Flow[2].Commands[0]; !c.After || c.Runtime != terrakube.RuntimeBash {
+		t.Errorf("apply command = %+v, want BASH after", c)
+	}
+	if c := tcl.Flow[3].Commands[0]; c.Before || c.After || !tcl.Flow[3].IgnoreError {
+		t.Errorf("script flow = %+v, command %+v, want ignoreError and a plain command", tcl.Flow[3], c)
+	}
+	if tcl.Flow[4].InputsTerraform["region"] != "eu-west-1" {
+		t.Errorf("InputsTerraform = %v, want region", tcl.Flow[4].InputsTerraform)
+	}
+}
+
+func TestTemplateBuilder_Presets(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		builder *terrakube.TemplateBuilder
+		want    string
+	}{
+		{"plan", terrakube.PlanTemplate(), "flow:\n  - type: terraformPlan\n    name: Plan\n    step: 100\n"},
+		{"apply", terrakube.ApplyTemplate(), "flow:\n  - type: terraformPlan\n    name: Plan\n    step: 100\n" +
+			"  - type: terraformApply\n    name: Apply\n    step: 200\n"},
+		{"destroy", terrakube.DestroyTemplate(), "flow:\n  - type: terraformPlanDestroy\n    name: Plan Destroy\n    step: 100\n" +
+			"  - type: terraformApply\n    name: Apply Destroy\n    step: 200\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := tt.builder.Content()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Content() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplateBuilder_Invalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := terrakube.NewTemplateBuilder().Plan("Plan").Approval("Approve", "").Content()
+	var verr *terrakube.TemplateValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected *TemplateValidationError, got %v", err)
+	}
+	if verr.Findings[0].Path != "flow[1].team" {
+		t.Errorf("Path = %q, want %q", verr.Findings[0].Path, "flow[1].team")
+	}
+
+	_, err = terrakube.NewTemplateBuilder().Before(terrakube.BashCommand("echo")).Plan("Plan").Content()
+	if err == nil || !strings.Contains(err.Error(), "no flow") {
+		t.Errorf("expected an error for modifying without a flow, got %v", err)
+	}
+}
@@ -0,0 +1,172 @@
+package terrakube_test
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	terrakube "github.com/terrakube-io/terrakube-go"
+)
+
+const testTemplateTCL = `flow:
+  - type: "terraformPlan"
+    name: "Plan"
+    step: 100
+    importComands:
+      repository: "https://github.com/AzBuilder/terrakube-extensions"
+      folder: "templates/terratag"
+      branch: "main"
+      inputsEnv:
+        TERRATAG_VERSION: "0.1.30"
+    commands:
+      - runtime: "GROOVY"
+        priority: 100
+        before: true
+        script: |
+          import TerraTag
+          new TerraTag().loadTool("$workingDirectory", "$bashToolsDirectory", "0.1.30")
+  - type: "approval"
+    name: "Approve Plan"
+    step: 150
+    team: "TERRAFORM_ADVANCED"
+  - type: "terraformApply"
+    name: "Apply"
+    step: 200
+    ignoreError: true
+    commands:
+      - runtime: "BASH"
+        priority: 100
+        after: true
+        verbose: true
+        script: |
+          echo "applied"
+    timeout: 30
+`
+
+func TestParseTemplate(t *testing.T) {
+	t.Parallel()
+
+	tcl, err := terrakube.ParseTemplate(testTemplateTCL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tcl.Base64 {
+		t.Error("Base64 = true, want false")
+	}
+	if len(tcl.Flow) != 3 {
+		t.Fatalf("got %d flows, want 3", len(tcl.Flow))
+	}
+
+	plan := tcl.Flow[0]
+	if plan.Type != terrakube.FlowTerraformPlan || plan.Name != "Plan" || plan.Step != 100 {
+		t.Errorf("flow[0] = %s %q %d, want terraformPlan \"Plan\" 100", plan.Type, plan.Name, plan.Step)
+	}
+	if plan.ImportCommands == nil || plan.ImportCommands.Folder != "templates/terratag" {
+		t.Errorf("ImportCommands = %+v, want folder templates/terratag", plan.ImportCommands)
+	}
+	if got := plan.ImportCommands.InputsEnv["TERRATAG_VERSION"]; got != "0.1.30" {
+		t.Errorf("InputsEnv[TERRATAG_VERSION] = %q, want %q", got, "0.1.30")
+	}
+	if len(plan.Commands) != 1 {
+		t.Fatalf("got %d commands, want 1", len(plan.Commands))
+	}
+	cmd := plan.Commands[0]
+	if cmd.Runtime != terrakube.RuntimeGroovy || cmd.Priority != 100 || !cmd.Before || cmd.After {
+		t.Errorf("command = %+v, want GROOVY priority 100 before", cmd)
+	}
+	if !strings.HasPrefix(cmd.Script, "import TerraTag\n") {
+		t.Errorf("Script = %q, want import TerraTag first", cmd.Script)
+	}
+
+	if approval := tcl.Flow[1]; approval.Type != terrakube.FlowApproval || approval.Team != "TERRAFORM_ADVANCED" {
+		t.Errorf("flow[1] = %s team %q, want approval team TERRAFORM_ADVANCED", approval.Type, approval.Team)
+	}
+
+	apply := tcl.Flow[2]
+	if !apply.IgnoreError {
+		t.Error("IgnoreError = false, want true")
+	}
+	if !apply.Commands[0].Verbose || !apply.Commands[0].After {
+		t.Errorf("command = %+v, want verbose after", apply.Commands[0])
+	}
+	if got := apply.Extra["timeout"]; got != 30 {
+		t.Errorf("Extra[timeout] = %v, want 30", got)
+	}
+}
+
+func TestMarshalTemplate_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	tcl, err := terrakube.ParseTemplate(testTemplateTCL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out, err := terrakube.MarshalTemplate(tcl)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"importComands:", "team: TERRAFORM_ADVANCED", "timeout: 30", "script: |"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	again, err := terrakube.ParseTemplate(out)
+	if err != nil {
+		t.Fatalf("reparsing: %v", err)
+	}
+	out2, err := terrakube.MarshalTemplate(again)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != out2 {
+		t.Errorf("round trip changed output:\n%s\nvs\n%s", out, out2)
+	}
+	if again.Flow[0].Commands[0].Script != tcl.Flow[0].Commands[0].Script {
+		t.Errorf("Script = %q, want %q", again.Flow[0].Commands[0].Script, tcl.Flow[0].Commands[0].Script)
+	}
+}
+
+func TestParseTemplate_Base64(t *testing.T) {
+	t.Parallel()
+
+	encoded := base64.StdEncoding.EncodeToString([]byte(testTemplateTCL))
+	tcl, err := terrakube.ParseTemplate(encoded)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !tcl.Base64 {
+		t.Error("Base64 = false, want true")
+	}
+	if len(tcl.Flow) != 3 {
+		t.Fatalf("got %d flows, want 3", len(tcl.Flow))
+	}
+
+	out, err := terrakube.MarshalTemplate(tcl)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(out)
+	if err != nil {
+		t.Fatalf("output is not base64: %v", err)
+	}
+	if !strings.HasPrefix(string(decoded), "flow:\n") {
+		t.Errorf("decoded output = %q, want a TCL document", decoded)
+	}
+}
+
+func TestParseTemplate_Invalid(t *testing.T) {
+	t.Parallel()
+
+	if _, err := terrakube.ParseTemplate("flow: [unclosed"); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	tcl, err := terrakube.ParseTemplate("")
+	if err != nil {
+		t.Fatalf("unexpected error for empty content: %v", err)
+	}
+	if len(tcl.Flow) != 0 {
+		t.Errorf("got %d flows, want 0", len(tcl.Flow))
+	}
+}
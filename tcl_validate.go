@@ -0,0 +1,255 @@
+package terrakube
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// knownFlowTypes lists the flow types ValidateTemplate accepts.
+var knownFlowTypes = map[FlowType]bool{
+	FlowTerraformPlan:        true,
+	FlowTerraformApply:       true,
+	FlowTerraformDestroy:     true,
+	FlowTerraformPlanDestroy: true,
+	FlowCustomScripts:        true,
+	FlowApproval:             true,
+	FlowDisableWorkspace:     true,
+	FlowScheduleTemplates:    true,
+}
+
+// TemplateFinding is a problem found in a template.
+type TemplateFinding struct {
+	// Path locates the offending value, such as "flow[1].commands[0].runtime".
+	Path string
+	// Line and Column give its position in the decoded YAML document.
+	Line    int
+	Column  int
+	Message string
+}
+
+// String returns the finding with its location.
+func (f TemplateFinding) String() string {
+	return fmt.Sprintf("line %d, column %d: %s: %s", f.Line, f.Column, f.Path, f.Message)
+}
+
+// TemplateValidationError is returned when a template fails validation.
+type TemplateValidationError struct {
+	Findings []TemplateFinding
+}
+
+// Error returns all findings on one line.
+func (e *TemplateValidationError) Error() string {
+	parts := make([]string, len(e.Findings))
+	for i, f := range e.Findings {
+		parts[i] = f.String()
+	}
+	return "invalid template: " + strings.Join(parts, "; ")
+}
+
+// ValidateTemplate checks TCL content for unknown flow types, missing names,
+// duplicate or out-of-order step numbers, approval flows without a team,
+// empty commands, and unsupported runtimes. When teams is not nil, approval
+// teams must also be one of its names.
+// It returns a *TemplateValidationError listing every finding, or an error if
+// the content cannot be decoded.
+func ValidateTemplate(content string, teams []string) error {
+	tcl, doc, err := parseTemplate(content)
+	if err != nil {
+		return err
+	}
+	return validateTemplate(tcl, doc, teams)
+}
+
+// Validate checks TCL content like ValidateTemplate, resolving approval teams
+// against the organization's teams.
+// It returns a *ValidationError if orgID is empty, a *TemplateValidationError
+// if the template is invalid, and a *APIError on server errors.
+func (s *TemplateService) Validate(ctx context.Context, orgID, content string) error {
+	if err := validateID("organizationID", orgID); err != nil {
+		return err
+	}
+
+	tcl, doc, err := parseTemplate(content)
+	if err != nil {
+		return err
+	}
+
+	var teams []string
+	for _, f := range tcl.Flow {
+		if f != nil && f.Type == FlowApproval && f.Team != "" {
+			list, err := s.client.Teams.List(ctx, orgID, nil)
+			if err != nil {
+				return err
+			}
+			teams = make([]string, 0, len(list))
+			for _, t := range list {
+				teams = append(teams, t.Name)
+			}
+			break
+		}
+	}
+	return validateTemplate(tcl, doc, teams)
+}
+
+// validateTemplate checks a decoded template, locating findings in doc.
+func validateTemplate(tcl *TCL, doc *yaml.Node, teams []string) error {
+	v := &templateValidator{doc: doc}
+	if len(tcl.Flow) == 0 {
+		v.report("template has no flows", "flow")
+	}
+
+	var knownTeams map[string]bool
+	if teams != nil {
+		knownTeams = make(map[string]bool, len(teams))
+		for _, t := range teams {
+			knownTeams[strings.ToLower(t)] = true
+		}
+	}
+
+	stepOwner := map[int]int{}
+	last := -1
+	for i, f := range tcl.Flow {
+		if f == nil {
+			v.report("flow is empty", "flow", i)
+			continue
+		}
+
+		switch {
+		case f.Type == "":
+			v.report("type is required", "flow", i, "type")
+		case !knownFlowTypes[f.Type]:
+			v.report(fmt.Sprintf("unknown flow type %q", f.Type), "flow", i, "type")
+		}
+		if strings.TrimSpace(f.Name) == "" {
+			v.report("name is required", "flow", i, "name")
+		}
+
+		switch owner, dup := stepOwner[f.Step]; {
+		case f.Step <= 0:
+			v.report("step must be a positive number", "flow", i, "step")
+		case dup:
+			v.report(fmt.Sprintf("step %d is already used by flow[%d]", f.Step, owner), "flow", i, "step")
+		case last >= 0 && f.Step < tcl.Flow[last].Step:
+			v.report(fmt.Sprintf("step %d is lower than step %d of flow[%d]; flows must be in ascending step order",
+				f.Step, tcl.Flow[last].Step, last), "flow", i, "step")
+		}
+		if f.Step > 0 {
+			if _, dup := stepOwner[f.Step]; !dup {
+				stepOwner[f.Step] = i
+			}
+			if last < 0 || f.Step > tcl.Flow[last].Step {
+				last = i
+			}
+		}
+
+		if f.Type == FlowApproval {
+			switch {
+			case f.Team == "":
+				v.report("approval flow requires a team", "flow", i, "team")
+			case knownTeams != nil && !knownTeams[strings.ToLower(f.Team)]:
+				v.report(fmt.Sprintf("team %q does not exist in the organization", f.Team), "flow", i, "team")
+			}
+		}
+
+		_, hasCommands := childNode(v.flowNode(i), "commands")
+		if len(f.Commands) == 0 && (hasCommands || f.Type == FlowCustomScripts && f.ImportCommands == nil) {
+			v.report("flow has no commands", "flow", i, "commands")
+		}
+		for j, c := range f.Commands {
+			if c == nil {
+				v.report("command is empty", "flow", i, "commands", j)
+				continue
+			}
+			if c.Runtime != RuntimeGroovy && c.Runtime != RuntimeBash {
+				v.report(fmt.Sprintf("unsupported runtime %q; use %s or %s", c.Runtime, RuntimeGroovy, RuntimeBash),
+					"flow", i, "commands", j, "runtime")
+			}
+			if strings.TrimSpace(c.Script) == "" {
+				v.report("script is empty", "flow", i, "commands", j, "script")
+			}
+		}
+	}
+
+	if len(v.findings) > 0 {
+		return &TemplateValidationError{Findings: v.findings}
+	}
+	return nil
+}
+
+// templateValidator collects findings located in a template's YAML document.
+type templateValidator struct {
+	doc      *yaml.Node
+	findings []TemplateFinding
+}
+
+// report records a finding at the value addressed by path, a sequence of
+// mapping keys and sequence indexes. When the value is missing, the finding
+// points at its closest existing parent.
+func (v *templateValidator) report(msg string, path ...interface{}) {
+	var b strings.Builder
+	for _, p := range path {
+		switch p := p.(type) {
+		case int:
+			b.WriteString("[" + strconv.Itoa(p) + "]")
+		case string:
+			if b.Len() > 0 {
+				b.WriteByte('.')
+			}
+			b.WriteString(p)
+		}
+	}
+
+	node := v.doc
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	for _, p := range path {
+		next, ok := childNode(node, p)
+		if !ok {
+			break
+		}
+		node = next
+	}
+	v.findings = append(v.findings, TemplateFinding{Path: b.String(), Line: node.Line, Column: node.Column, Message: msg})
+}
+
+// flowNode returns the YAML node of the i-th flow, or nil.
+func (v *templateValidator) flowNode(i int) *yaml.Node {
+	node := v.doc
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	flows, ok := childNode(node, "flow")
+	if !ok {
+		return nil
+	}
+	flow, _ := childNode(flows, i)
+	return flow
+}
+
+// childNode returns the value of a mapping key or the element of a sequence.
+func childNode(n *yaml.Node, key interface{}) (*yaml.Node, bool) {
+	if n == nil {
+		return nil, false
+	}
+	switch key := key.(type) {
+	case string:
+		if n.Kind != yaml.MappingNode {
+			return nil, false
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if n.Content[i].Value == key {
+				return n.Content[i+1], true
+			}
+		}
+	case int:
+		if n.Kind == yaml.SequenceNode && key < len(n.Content) {
+			return n.Content[key], true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,174 @@
+package terrakube_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	terrakube "github.com/terrakube-io/terrakube-go"
+	"github.com/terrakube-io/terrakube-go/testutil"
+)
+
+const testInvalidTemplateTCL = `flow:
+  - type: "terraformPlan"
+    name: "Plan"
+    step: 100
+  - type: "terraformPlann"
+    step: 200
+  - type: "approval"
+    name: "Approve"
+    step: 200
+    team: "UNKNOWN"
+  - type: "customScripts"
+    name: "Notify"
+    step: 150
+    commands:
+      - runtime: "PYTHON"
+        priority: 100
+        script: "print('hi')"
+      - runtime: "BASH"
+        priority: 200
+        script: ""
+  - type: "terraformApply"
+    name: "Apply"
+    step: 300
+    commands: []
+`
+
+func TestValidateTemplate(t *testing.T) {
+	t.Parallel()
+
+	if err := terrakube.ValidateTemplate(testTemplateTCL, []string{"terraform_advanced"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err := terrakube.ValidateTemplate(testInvalidTemplateTCL, []string{"TERRAFORM_ADVANCED"})
+	var verr *terrakube.TemplateValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected *TemplateValidationError, got %T: %v", err, err)
+	}
+
+	want := []terrakube.TemplateFinding{
+		{Path: "flow[1].type", Line: 5, Column: 11},
+		{Path: "flow[1].name", Line: 5, Column: 5},
+		{Path: "flow[2].step", Line: 9, Column: 11},
+		{Path: "flow[2].team", Line: 10, Column: 11},
+		{Path: "flow[3].step", Line: 13, Column: 11},
+		{Path: "flow[3].commands[0].runtime", Line: 15, Column: 18},
+		{Path: "flow[3].commands[1].script", Line: 20, Column: 17},
+		{Path: "flow[4].commands", Line: 24, Column: 15},
+	}
+	if len(verr.Findings) != len(want) {
+		t.Fatalf("got %d findings, want %d: %v", len(verr.Findings), len(want), verr)
+	}
+	for i, w := range want {
+		got := verr.Findings[i]
+		if got.Path != w.Path || got.Line != w.Line || got.Column != w.Column {
+			t.Errorf("finding %d = %s line %d col %d, want %s line %d col %d: %s",
+				i, got.Path, got.Line, got.Column, w.Path, w.Line, w.Column, got.Message)
+		}
+		if got.Message == "" {
+			t.Errorf("finding %d has no message", i)
+		}
+	}
+}
+
+func TestValidateTemplate_NoTeamCheck(t *testing.T) {
+	t.Parallel()
+
+	content := "flow:\n  - type: approval\n    name: Approve\n    step: 100\n    team: ANYONE\n"
+	if err := terrakube.ValidateTemplate(content, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err := terrakube.ValidateTemplate("flow:\n  - type: approval\n    name: Approve\n    step: 100\n", nil)
+	var verr *terrakube.TemplateValidationError
+	if !errors.As(err, &verr) || len(verr.Findings) != 1 || verr.Findings[0].Path != "flow[0].team" {
+		t.Fatalf("expected a missing team finding, got %v", err)
+	}
+}
+
+func TestValidateTemplate_Empty(t *testing.T) {
+	t.Parallel()
+
+	var verr *terrakube.TemplateValidationError
+	if err := terrakube.ValidateTemplate("", nil); !errors.As(err, &verr) {
+		t.Fatalf("expected *TemplateValidationError, got %v", err)
+	}
+	if err := terrakube.ValidateTemplate("flow: [", nil); err == nil || errors.As(err, &verr) {
+		t.Fatalf("expected a decoding error, got %v", err)
+	}
+}
+
+func TestTemplateService_Validate(t *testing.T) {
+	t.Parallel()
+	srv := testutil.NewServer(t)
+
+	srv.HandleFunc("GET /api/v1/organization/org-1/team", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSONAPIList(t, w, http.StatusOK, []*terrakube.Team{
+			{ID: "team-1", Name: "TERRAFORM_ADVANCED"},
+		})
+	})
+
+	c := newTestClient(t, srv)
+
+	if err := c.Templates.Validate(context.Background(), "org-1", testTemplateTCL); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err := c.Templates.Validate(context.Background(), "org-1",
+		"flow:\n  - type: approval\n    name: Approve\n    step: 100\n    team: OPS\n")
+	var verr *terrakube.TemplateValidationError
+	if !errors.As(err, &verr) || len(verr.Findings) != 1 || verr.Findings[0].Path != "flow[0].team" {
+		t.Fatalf("expected an unknown team finding, got %v", err)
+	}
+}
+
+func TestTemplateService_Validate_EmptyOrgID(t *testing.T) {
+	t.Parallel()
+
+	c := newTestClientFromURL(t, "https://example.com")
+
+	err := c.Templates.Validate(context.Background(), "", testTemplateTCL)
+	assertValidationError(t, err, "organizationID")
+}
+
+func TestTemplateService_Create_WithTemplateValidation(t *testing.T) {
+	t.Parallel()
+	srv := testutil.NewServer(t)
+
+	var creates atomic.Int32
+	srv.HandleFunc("POST /api/v1/organization/org-1/template", func(w http.ResponseWriter, _ *http.Request) {
+		creates.Add(1)
+		testutil.WriteJSONAPI(t, w, http.StatusCreated, &terrakube.Template{ID: "tmpl-new", Name: "plan"})
+	})
+	srv.HandleFunc("PATCH /api/v1/organization/org-1/template/tmpl-1", func(w http.ResponseWriter, _ *http.Request) {
+		t.Error("invalid template was sent to the server")
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	c, err := terrakube.NewClient(
+		terrakube.WithEndpoint(srv.URL),
+		terrakube.WithToken("test-token"),
+		terrakube.WithTemplateValidation(),
+	)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	content := "flow:\n  - type: terraformPlan\n    name: Plan\n    step: 100\n"
+	if _, err := c.Templates.Create(context.Background(), "org-1", &terrakube.Template{Name: "plan", Content: content}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if creates.Load() != 1 {
+		t.Errorf("creates = %d, want 1", creates.Load())
+	}
+
+	_, err = c.Templates.Update(context.Background(), "org-1", &terrakube.Template{ID: "tmpl-1", Name: "plan", Content: "flow: []\n"})
+	var verr *terrakube.TemplateValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected *TemplateValidationError, got %v", err)
+	}
+}
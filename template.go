@@ -46,19 +46,28 @@ func (s *TemplateService) Get(ctx context.Context, orgID, id string) (*Template,
 	return s.get(ctx, path)
 }
 
-// Create creates a new template in the given organization.
-// It returns a *ValidationError if orgID is empty and a *APIError on server errors.
+// Create creates a new template in the given organization. With
+// WithTemplateValidation, the content is validated first.
+// It returns a *ValidationError if orgID is empty, a *TemplateValidationError
+// if validation fails, and a *APIError on server errors.
 func (s *TemplateService) Create(ctx context.Context, orgID string, tmpl *Template) (*Template, error) {
 	if err := validateID("organizationID", orgID); err != nil {
 		return nil, err
 	}
+	if s.client.validateTemplates {
+		if err := s.Validate(ctx, orgID, tmpl.Content); err != nil {
+			return nil, err
+		}
+	}
 
 	path := s.client.apiPath("organization", orgID, "template")
 	return s.create(ctx, path, tmpl)
 }
 
 // Update modifies an existing template in the given organization. The template's ID field must be set.
-// It returns a *ValidationError if orgID or the ID is empty and a *APIError on server errors.
+// With WithTemplateValidation, the content is validated first.
+// It returns a *ValidationError if orgID or the ID is empty, a *TemplateValidationError
+// if validation fails, and a *APIError on server errors.
 func (s *TemplateService) Update(ctx context.Context, orgID string, tmpl *Template) (*Template, error) {
 	if err := validateID("organizationID", orgID); err != nil {
 		return nil, err
@@ -66,6 +75,11 @@ func (s *TemplateService) Update(ctx context.Context, orgID string, tmpl *Templa
 	if err := validateID("templateID", tmpl.ID); err != nil {
 		return nil, err
 	}
+	if s.client.validateTemplates {
+		if err := s.Validate(ctx, orgID, tmpl.Content); err != nil {
+			return nil, err
+		}
+	}
 
 	path := s.client.apiPath("organization", orgID, "template", tmpl.ID)
 	return s.update(ctx, path, tmpl)
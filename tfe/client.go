@@ -0,0 +1,158 @@
+// Package tfe is a client for the Terraform Cloud/Enterprise-compatible API
+// that Terrakube serves under /remote/tfe/v2 for the Terraform "cloud" and
+// "remote" backends. It reuses the endpoint, token, and HTTP transport of a
+// [terrakube.Client]:
+//
+//	base, err := terrakube.NewClient(
+//		terrakube.WithEndpoint("https://terrakube.example.com"),
+//		terrakube.WithToken("your-api-token"),
+//	)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	client := tfe.NewClient(base)
+//	ws, err := client.Workspaces.Read(ctx, "my-org", "networking")
+//
+// Server errors are returned as *terrakube.APIError and empty identifiers as
+// *terrakube.ValidationError, as in the parent package.
+package tfe
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+
+	"github.com/google/jsonapi"
+
+	terrakube "github.com/terrakube-io/terrakube-go"
+)
+
+const (
+	basePath  = "/remote/tfe/v2/"
+	mediaType = "application/vnd.api+json"
+)
+
+// Client manages communication with the TFE-compatible API.
+type Client struct {
+	client *terrakube.Client
+
+	Organizations *OrganizationService
+	Workspaces    *WorkspaceService
+	StateVersions *StateVersionService
+	Runs          *RunService
+}
+
+// NewClient creates a TFE-compatible API client that sends its requests
+// through c.
+func NewClient(c *terrakube.Client) *Client {
+	tc := &Client{client: c}
+	tc.Organizations = &OrganizationService{client: tc}
+	tc.Workspaces = &WorkspaceService{client: tc}
+	tc.StateVersions = &StateVersionService{client: tc}
+	tc.Runs = &RunService{client: tc}
+	return tc
+}
+
+// Ping checks that the server exposes the TFE-compatible API.
+// It returns a *terrakube.APIError on server errors.
+func (c *Client) Ping(ctx context.Context) error {
+	return c.do(ctx, http.MethodGet, c.path("ping"), nil, nil)
+}
+
+// path constructs a full API path by joining segments under basePath.
+func (c *Client) path(segments ...string) string {
+	return path.Join(basePath, path.Join(segments...))
+}
+
+// marshal encodes v as a JSON:API document.
+func marshal(v interface{}) ([]byte, error) {
+	var b bytes.Buffer
+	if err := jsonapi.MarshalPayload(&b, v); err != nil {
+		return nil, fmt.Errorf("marshaling request body: %w", err)
+	}
+	return b.Bytes(), nil
+}
+
+// marshalJSON encodes v as a plain JSON body, used by the action endpoints.
+func marshalJSON(v interface{}) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling request body: %w", err)
+	}
+	return b, nil
+}
+
+// do sends a request and decodes a JSON:API response into out when out is
+// not nil.
+func (c *Client) do(ctx context.Context, method, reqPath string, body []byte, out interface{}) error {
+	data, err := c.send(ctx, method, reqPath, body, mediaType)
+	if err != nil {
+		return err
+	}
+	if out != nil && len(data) > 0 {
+		if err := jsonapi.UnmarshalPayload(bytes.NewReader(data), out); err != nil {
+			return fmt.Errorf("decoding JSON:API response: %w", err)
+		}
+	}
+	return nil
+}
+
+// send performs a request and returns the response body.
+func (c *Client) send(ctx context.Context, method, ref string, body []byte, accept string) ([]byte, error) {
+	var buf io.Reader
+	if body != nil {
+		buf = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, ref, buf)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", mediaType)
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() //nolint:errcheck // response body close errors are inconsequential
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response body: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		apiErr := &terrakube.APIError{
+			StatusCode: resp.StatusCode,
+			Method:     req.Method,
+			Path:       req.URL.Path,
+			Body:       data,
+		}
+		var errResp struct {
+			Errors []terrakube.ErrorDetail `json:"errors"`
+		}
+		if json.Unmarshal(data, &errResp) == nil {
+			apiErr.Errors = errResp.Errors
+		}
+		return nil, apiErr
+	}
+
+	return data, nil
+}
+
+// validateID checks that an identifier is not empty.
+func validateID(field, value string) error {
+	if value == "" {
+		return &terrakube.ValidationError{Field: field, Message: "must not be empty"}
+	}
+	return nil
+}
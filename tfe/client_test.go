@@ -0,0 +1,102 @@
+package tfe_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	terrakube "github.com/terrakube-io/terrakube-go"
+	"github.com/terrakube-io/terrakube-go/testutil"
+	"github.com/terrakube-io/terrakube-go/tfe"
+)
+
+// newTestClient creates a TFE client pointing at the test server.
+func newTestClient(t *testing.T, srv *testutil.Server) *tfe.Client {
+	t.Helper()
+	base, err := terrakube.NewClient(
+		terrakube.WithEndpoint(srv.URL),
+		terrakube.WithToken("test-token"),
+		terrakube.WithUserAgent("tfe-test"),
+	)
+	if err != nil {
+		t.Fatalf("failed to create test client: %v", err)
+	}
+	return tfe.NewClient(base)
+}
+
+// newOfflineClient creates a TFE client for tests that never reach a server.
+func newOfflineClient(t *testing.T) *tfe.Client {
+	t.Helper()
+	base, err := terrakube.NewClient(
+		terrakube.WithEndpoint("https://example.com"),
+		terrakube.WithToken("test-token"),
+	)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	return tfe.NewClient(base)
+}
+
+// assertValidationError checks that err is a *terrakube.ValidationError for the given field.
+func assertValidationError(t *testing.T, err error, field string) {
+	t.Helper()
+	var ve *terrakube.ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("expected *ValidationError, got %T: %v", err, err)
+	}
+	if ve.Field != field {
+		t.Errorf("ValidationError.Field = %q, want %q", ve.Field, field)
+	}
+	if !strings.Contains(ve.Message, "must not be empty") {
+		t.Errorf("ValidationError.Message = %q, want it to contain %q", ve.Message, "must not be empty")
+	}
+}
+
+func TestClient_Ping(t *testing.T) {
+	t.Parallel()
+
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("GET /remote/tfe/v2/ping", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer test-token" {
+			t.Errorf("Authorization = %q, want %q", got, "Bearer test-token")
+		}
+		if got := r.Header.Get("User-Agent"); got != "tfe-test" {
+			t.Errorf("User-Agent = %q, want %q", got, "tfe-test")
+		}
+		if got := r.Header.Get("Accept"); got != "application/vnd.api+json" {
+			t.Errorf("Accept = %q, want JSON:API media type", got)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	client := newTestClient(t, srv)
+
+	if err := client.Ping(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestClient_APIError(t *testing.T) {
+	t.Parallel()
+
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("GET /remote/tfe/v2/ping", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteError(t, w, http.StatusUnauthorized, "invalid token")
+	})
+
+	client := newTestClient(t, srv)
+
+	err := client.Ping(context.Background())
+	var apiErr *terrakube.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *APIError, got %T: %v", err, err)
+	}
+	if !terrakube.IsUnauthorized(err) {
+		t.Errorf("StatusCode = %d, want 401", apiErr.StatusCode)
+	}
+	if len(apiErr.Errors) != 1 || apiErr.Errors[0].Detail != "invalid token" {
+		t.Errorf("Errors = %+v, want the server's detail", apiErr.Errors)
+	}
+}
@@ -0,0 +1,41 @@
+package tfe
+
+import (
+	"context"
+	"net/http"
+)
+
+// Entitlements lists the features the server enables for an organization.
+// The Terraform CLI checks them before using the remote backend.
+type Entitlements struct {
+	ID                    string `jsonapi:"primary,entitlement-sets"`
+	Agents                bool   `jsonapi:"attr,agents"`
+	AuditLogging          bool   `jsonapi:"attr,audit-logging"`
+	CostEstimation        bool   `jsonapi:"attr,cost-estimation"`
+	Operations            bool   `jsonapi:"attr,operations"`
+	PrivateModuleRegistry bool   `jsonapi:"attr,private-module-registry"`
+	Sentinel              bool   `jsonapi:"attr,sentinel"`
+	StateStorage          bool   `jsonapi:"attr,state-storage"`
+	Teams                 bool   `jsonapi:"attr,teams"`
+	VCSIntegrations       bool   `jsonapi:"attr,vcs-integrations"`
+}
+
+// OrganizationService handles the organization endpoints of the TFE-compatible API.
+type OrganizationService struct {
+	client *Client
+}
+
+// Entitlements returns the entitlement set of the named organization.
+// It returns a *terrakube.ValidationError if organization is empty and a
+// *terrakube.APIError on server errors.
+func (s *OrganizationService) Entitlements(ctx context.Context, organization string) (*Entitlements, error) {
+	if err := validateID("organization", organization); err != nil {
+		return nil, err
+	}
+
+	e := &Entitlements{}
+	if err := s.client.do(ctx, http.MethodGet, s.client.path("organizations", organization, "entitlement-set"), nil, e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
@@ -0,0 +1,36 @@
+package tfe_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/terrakube-io/terrakube-go/testutil"
+	"github.com/terrakube-io/terrakube-go/tfe"
+)
+
+func TestOrganizationService_Entitlements(t *testing.T) {
+	t.Parallel()
+
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("GET /remote/tfe/v2/organizations/my-org/entitlement-set", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSONAPI(t, w, http.StatusOK, &tfe.Entitlements{ID: "org-1", Operations: true, StateStorage: true})
+	})
+
+	client := newTestClient(t, srv)
+
+	e, err := client.Organizations.Entitlements(context.Background(), "my-org")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !e.Operations || !e.StateStorage || e.Sentinel {
+		t.Errorf("Entitlements = %+v", e)
+	}
+}
+
+func TestOrganizationService_Entitlements_EmptyOrganization(t *testing.T) {
+	t.Parallel()
+
+	_, err := newOfflineClient(t).Organizations.Entitlements(context.Background(), "")
+	assertValidationError(t, err, "organization")
+}
@@ -0,0 +1,111 @@
+package tfe
+
+import (
+	"context"
+	"net/http"
+)
+
+// Run status values reported by the TFE-compatible API.
+const (
+	RunPending            = "pending"
+	RunPlanning           = "planning"
+	RunPlanned            = "planned"
+	RunPlannedAndFinished = "planned_and_finished"
+	RunApplying           = "applying"
+	RunApplied            = "applied"
+	RunDiscarded          = "discarded"
+	RunErrored            = "errored"
+	RunCanceled           = "canceled"
+)
+
+// Run is a plan, and optionally an apply, of a workspace.
+type Run struct {
+	ID         string     `jsonapi:"primary,runs"`
+	Status     string     `jsonapi:"attr,status,omitempty"`
+	Message    string     `jsonapi:"attr,message,omitempty"`
+	IsDestroy  bool       `jsonapi:"attr,is-destroy"`
+	AutoApply  bool       `jsonapi:"attr,auto-apply"`
+	HasChanges bool       `jsonapi:"attr,has-changes"`
+	CreatedAt  *string    `jsonapi:"attr,created-at"`
+	Workspace  *Workspace `jsonapi:"relation,workspace,omitempty"`
+}
+
+// RunService handles the run endpoints of the TFE-compatible API.
+type RunService struct {
+	client *Client
+}
+
+// Create queues a run. The run's Workspace must be set with at least its ID.
+// It returns a *terrakube.ValidationError if the workspace ID is empty and a
+// *terrakube.APIError on server errors.
+func (s *RunService) Create(ctx context.Context, run *Run) (*Run, error) {
+	var workspaceID string
+	if run.Workspace != nil {
+		workspaceID = run.Workspace.ID
+	}
+	if err := validateID("workspaceID", workspaceID); err != nil {
+		return nil, err
+	}
+
+	body, err := marshal(run)
+	if err != nil {
+		return nil, err
+	}
+	created := &Run{}
+	if err := s.client.do(ctx, http.MethodPost, s.client.path("runs"), body, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// Read returns a run by ID.
+// It returns a *terrakube.ValidationError if id is empty and a
+// *terrakube.APIError on server errors.
+func (s *RunService) Read(ctx context.Context, id string) (*Run, error) {
+	if err := validateID("runID", id); err != nil {
+		return nil, err
+	}
+
+	run := &Run{}
+	if err := s.client.do(ctx, http.MethodGet, s.client.path("runs", id), nil, run); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// Apply applies a planned run, recording an optional comment.
+// It returns a *terrakube.ValidationError if id is empty and a
+// *terrakube.APIError on server errors.
+func (s *RunService) Apply(ctx context.Context, id, comment string) error {
+	return s.action(ctx, id, "apply", comment)
+}
+
+// Cancel interrupts a run that is planning or applying.
+// It returns a *terrakube.ValidationError if id is empty and a
+// *terrakube.APIError on server errors.
+func (s *RunService) Cancel(ctx context.Context, id, comment string) error {
+	return s.action(ctx, id, "cancel", comment)
+}
+
+// Discard discards a run that is waiting for confirmation.
+// It returns a *terrakube.ValidationError if id is empty and a
+// *terrakube.APIError on server errors.
+func (s *RunService) Discard(ctx context.Context, id, comment string) error {
+	return s.action(ctx, id, "discard", comment)
+}
+
+func (s *RunService) action(ctx context.Context, id, action, comment string) error {
+	if err := validateID("runID", id); err != nil {
+		return err
+	}
+
+	var body []byte
+	if comment != "" {
+		var err error
+		body, err = marshalJSON(map[string]string{"comment": comment})
+		if err != nil {
+			return err
+		}
+	}
+	return s.client.do(ctx, http.MethodPost, s.client.path("runs", id, "actions", action), body, nil)
+}
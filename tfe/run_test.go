@@ -0,0 +1,118 @@
+package tfe_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/terrakube-io/terrakube-go/testutil"
+	"github.com/terrakube-io/terrakube-go/tfe"
+)
+
+func TestRunService_Create(t *testing.T) {
+	t.Parallel()
+
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("POST /remote/tfe/v2/runs", func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Data struct {
+				Attributes    map[string]interface{} `json:"attributes"`
+				Relationships struct {
+					Workspace struct {
+						Data struct {
+							ID string `json:"id"`
+						} `json:"data"`
+					} `json:"workspace"`
+				} `json:"relationships"`
+			} `json:"data"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Fatalf("failed to decode body: %v", err)
+		}
+		if payload.Data.Relationships.Workspace.Data.ID != "ws-1" {
+			t.Errorf("workspace relationship = %q, want %q", payload.Data.Relationships.Workspace.Data.ID, "ws-1")
+		}
+		if v, ok := payload.Data.Attributes["is-destroy"]; !ok || v != true {
+			t.Errorf("is-destroy = %v (present %v), want true", v, ok)
+		}
+		testutil.WriteJSONAPI(t, w, http.StatusCreated, &tfe.Run{ID: "run-1", Status: tfe.RunPending, IsDestroy: true})
+	})
+
+	client := newTestClient(t, srv)
+
+	run, err := client.Runs.Create(context.Background(), &tfe.Run{
+		Message:   "teardown",
+		IsDestroy: true,
+		Workspace: &tfe.Workspace{ID: "ws-1"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if run.ID != "run-1" || run.Status != tfe.RunPending {
+		t.Errorf("Run = %+v", run)
+	}
+}
+
+func TestRunService_ReadAndActions(t *testing.T) {
+	t.Parallel()
+
+	var actions []string
+
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("GET /remote/tfe/v2/runs/run-1", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSONAPI(t, w, http.StatusOK, &tfe.Run{ID: "run-1", Status: tfe.RunPlanned, HasChanges: true})
+	})
+	srv.HandleFunc("POST /remote/tfe/v2/runs/run-1/actions/{action}", func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("failed to read body: %v", err)
+		}
+		actions = append(actions, r.PathValue("action")+":"+string(body))
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	client := newTestClient(t, srv)
+	ctx := context.Background()
+
+	run, err := client.Runs.Read(ctx, "run-1")
+	if err != nil {
+		t.Fatalf("Read: unexpected error: %v", err)
+	}
+	if run.Status != tfe.RunPlanned || !run.HasChanges {
+		t.Errorf("Run = %+v", run)
+	}
+
+	if err := client.Runs.Apply(ctx, "run-1", "lgtm"); err != nil {
+		t.Fatalf("Apply: unexpected error: %v", err)
+	}
+	if err := client.Runs.Discard(ctx, "run-1", ""); err != nil {
+		t.Fatalf("Discard: unexpected error: %v", err)
+	}
+	if err := client.Runs.Cancel(ctx, "run-1", ""); err != nil {
+		t.Fatalf("Cancel: unexpected error: %v", err)
+	}
+
+	want := []string{`apply:{"comment":"lgtm"}`, "discard:", "cancel:"}
+	if len(actions) != len(want) {
+		t.Fatalf("actions = %v, want %v", actions, want)
+	}
+	for i := range want {
+		if actions[i] != want[i] {
+			t.Errorf("action %d = %q, want %q", i, actions[i], want[i])
+		}
+	}
+}
+
+func TestRunService_EmptyIDs(t *testing.T) {
+	t.Parallel()
+
+	client := newOfflineClient(t)
+
+	_, err := client.Runs.Create(context.Background(), &tfe.Run{})
+	assertValidationError(t, err, "workspaceID")
+	_, err = client.Runs.Read(context.Background(), "")
+	assertValidationError(t, err, "runID")
+	assertValidationError(t, client.Runs.Apply(context.Background(), "", ""), "runID")
+}
@@ -0,0 +1,89 @@
+package tfe
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+
+	terrakube "github.com/terrakube-io/terrakube-go"
+)
+
+// StateVersion is a workspace state version.
+type StateVersion struct {
+	ID      string `jsonapi:"primary,state-versions"`
+	Serial  int64  `jsonapi:"attr,serial"`
+	MD5     string `jsonapi:"attr,md5,omitempty"`
+	Lineage string `jsonapi:"attr,lineage,omitempty"`
+	// State is the base64-encoded state file. It is only sent on creation.
+	State                  string  `jsonapi:"attr,state,omitempty"`
+	HostedStateDownloadURL string  `jsonapi:"attr,hosted-state-download-url,omitempty"`
+	CreatedAt              *string `jsonapi:"attr,created-at"`
+}
+
+// StateVersionService handles the state version endpoints of the TFE-compatible API.
+type StateVersionService struct {
+	client *Client
+}
+
+// Create uploads a new state version to a workspace, as the Terraform CLI
+// does after an apply. The serial, lineage, and checksum are read from the
+// state itself, which must be in the version 4 format. The workspace should
+// be locked first.
+// It returns a *terrakube.ValidationError if workspaceID is empty and a
+// *terrakube.APIError on server errors.
+func (s *StateVersionService) Create(ctx context.Context, workspaceID string, state []byte) (*StateVersion, error) {
+	if err := validateID("workspaceID", workspaceID); err != nil {
+		return nil, err
+	}
+
+	parsed, err := terrakube.ParseState(state)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := marshal(&StateVersion{
+		Serial:  parsed.Serial,
+		MD5:     terrakube.StateChecksum(state),
+		Lineage: parsed.Lineage,
+		State:   base64.StdEncoding.EncodeToString(state),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sv := &StateVersion{}
+	if err := s.client.do(ctx, http.MethodPost, s.client.path("workspaces", workspaceID, "state-versions"), body, sv); err != nil {
+		return nil, err
+	}
+	return sv, nil
+}
+
+// Current returns the workspace's current state version.
+// It returns a *terrakube.ValidationError if workspaceID is empty and a
+// *terrakube.APIError on server errors, with status 404 if the workspace has
+// no state.
+func (s *StateVersionService) Current(ctx context.Context, workspaceID string) (*StateVersion, error) {
+	if err := validateID("workspaceID", workspaceID); err != nil {
+		return nil, err
+	}
+
+	sv := &StateVersion{}
+	if err := s.client.do(ctx, http.MethodGet, s.client.path("workspaces", workspaceID, "current-state-version"), nil, sv); err != nil {
+		return nil, err
+	}
+	return sv, nil
+}
+
+// Download fetches the raw state file of a state version.
+// It returns a *terrakube.ValidationError if the state version has no
+// download URL and a *terrakube.APIError on server errors.
+func (s *StateVersionService) Download(ctx context.Context, sv *StateVersion) ([]byte, error) {
+	if sv.HostedStateDownloadURL == "" {
+		return nil, &terrakube.ValidationError{
+			Field:   "hostedStateDownloadURL",
+			Message: fmt.Sprintf("state version %s has no download URL", sv.ID),
+		}
+	}
+	return s.client.send(ctx, http.MethodGet, sv.HostedStateDownloadURL, nil, "")
+}
@@ -0,0 +1,99 @@
+package tfe_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	terrakube "github.com/terrakube-io/terrakube-go"
+	"github.com/terrakube-io/terrakube-go/testutil"
+	"github.com/terrakube-io/terrakube-go/tfe"
+)
+
+const testState = `{"version": 4, "serial": 4, "lineage": "lin-1", "outputs": {}, "resources": []}`
+
+func TestStateVersionService_Create(t *testing.T) {
+	t.Parallel()
+
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("POST /remote/tfe/v2/workspaces/ws-1/state-versions", func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Data struct {
+				Attributes map[string]interface{} `json:"attributes"`
+			} `json:"data"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Fatalf("failed to decode body: %v", err)
+		}
+		attrs := payload.Data.Attributes
+		if attrs["serial"] != float64(4) || attrs["lineage"] != "lin-1" {
+			t.Errorf("serial, lineage = %v, %v", attrs["serial"], attrs["lineage"])
+		}
+		if attrs["md5"] != terrakube.StateChecksum([]byte(testState)) {
+			t.Errorf("md5 = %v", attrs["md5"])
+		}
+		if attrs["state"] != base64.StdEncoding.EncodeToString([]byte(testState)) {
+			t.Errorf("state = %v, want base64 state", attrs["state"])
+		}
+		testutil.WriteJSONAPI(t, w, http.StatusCreated, &tfe.StateVersion{ID: "sv-4", Serial: 4})
+	})
+
+	client := newTestClient(t, srv)
+
+	sv, err := client.StateVersions.Create(context.Background(), "ws-1", []byte(testState))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sv.ID != "sv-4" || sv.Serial != 4 {
+		t.Errorf("StateVersion = %+v", sv)
+	}
+}
+
+func TestStateVersionService_CurrentAndDownload(t *testing.T) {
+	t.Parallel()
+
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("GET /remote/tfe/v2/workspaces/ws-1/current-state-version", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSONAPI(t, w, http.StatusOK, &tfe.StateVersion{
+			ID:                     "sv-4",
+			Serial:                 4,
+			HostedStateDownloadURL: "/tfstate/v1/organization/org-1/workspace/ws-1/state/terraform.tfstate",
+		})
+	})
+	srv.HandleFunc("GET /tfstate/v1/organization/org-1/workspace/ws-1/state/terraform.tfstate", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			t.Error("missing Authorization header on same-host download")
+		}
+		_, _ = w.Write([]byte(testState))
+	})
+
+	client := newTestClient(t, srv)
+
+	sv, err := client.StateVersions.Current(context.Background(), "ws-1")
+	if err != nil {
+		t.Fatalf("Current: unexpected error: %v", err)
+	}
+	data, err := client.StateVersions.Download(context.Background(), sv)
+	if err != nil {
+		t.Fatalf("Download: unexpected error: %v", err)
+	}
+	if string(data) != testState {
+		t.Errorf("downloaded state = %s", data)
+	}
+}
+
+func TestStateVersionService_Validation(t *testing.T) {
+	t.Parallel()
+
+	client := newOfflineClient(t)
+
+	_, err := client.StateVersions.Create(context.Background(), "", []byte(testState))
+	assertValidationError(t, err, "workspaceID")
+	_, err = client.StateVersions.Current(context.Background(), "")
+	assertValidationError(t, err, "workspaceID")
+	if _, err := client.StateVersions.Download(context.Background(), &tfe.StateVersion{ID: "sv-1"}); err == nil {
+		t.Error("expected error for state version without download URL")
+	}
+}
@@ -0,0 +1,116 @@
+package tfe
+
+import (
+	"context"
+	"net/http"
+)
+
+// Workspace is a workspace as represented by the TFE-compatible API.
+type Workspace struct {
+	ID               string  `jsonapi:"primary,workspaces"`
+	Name             string  `jsonapi:"attr,name"`
+	Description      *string `jsonapi:"attr,description"`
+	ExecutionMode    string  `jsonapi:"attr,execution-mode,omitempty"`
+	TerraformVersion string  `jsonapi:"attr,terraform-version,omitempty"`
+	WorkingDirectory string  `jsonapi:"attr,working-directory,omitempty"`
+	AutoApply        bool    `jsonapi:"attr,auto-apply"`
+	Locked           bool    `jsonapi:"attr,locked"`
+	Operations       bool    `jsonapi:"attr,operations"`
+	CreatedAt        *string `jsonapi:"attr,created-at"`
+}
+
+// WorkspaceService handles the workspace endpoints of the TFE-compatible API.
+type WorkspaceService struct {
+	client *Client
+}
+
+// Read returns a workspace by organization and workspace name, as the
+// Terraform CLI resolves its backend configuration.
+// It returns a *terrakube.ValidationError if organization or name is empty
+// and a *terrakube.APIError on server errors.
+func (s *WorkspaceService) Read(ctx context.Context, organization, name string) (*Workspace, error) {
+	if err := validateID("organization", organization); err != nil {
+		return nil, err
+	}
+	if err := validateID("name", name); err != nil {
+		return nil, err
+	}
+
+	ws := &Workspace{}
+	if err := s.client.do(ctx, http.MethodGet, s.client.path("organizations", organization, "workspaces", name), nil, ws); err != nil {
+		return nil, err
+	}
+	return ws, nil
+}
+
+// ReadByID returns a workspace by ID.
+// It returns a *terrakube.ValidationError if id is empty and a
+// *terrakube.APIError on server errors.
+func (s *WorkspaceService) ReadByID(ctx context.Context, id string) (*Workspace, error) {
+	if err := validateID("workspaceID", id); err != nil {
+		return nil, err
+	}
+
+	ws := &Workspace{}
+	if err := s.client.do(ctx, http.MethodGet, s.client.path("workspaces", id), nil, ws); err != nil {
+		return nil, err
+	}
+	return ws, nil
+}
+
+// Create creates a workspace in the named organization.
+// It returns a *terrakube.ValidationError if organization is empty and a
+// *terrakube.APIError on server errors.
+func (s *WorkspaceService) Create(ctx context.Context, organization string, ws *Workspace) (*Workspace, error) {
+	if err := validateID("organization", organization); err != nil {
+		return nil, err
+	}
+
+	body, err := marshal(ws)
+	if err != nil {
+		return nil, err
+	}
+	created := &Workspace{}
+	if err := s.client.do(ctx, http.MethodPost, s.client.path("organizations", organization, "workspaces"), body, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// Lock locks a workspace with the given reason. The server responds with
+// 409 Conflict if the workspace is already locked.
+// It returns a *terrakube.ValidationError if id is empty and a
+// *terrakube.APIError on server errors.
+func (s *WorkspaceService) Lock(ctx context.Context, id, reason string) (*Workspace, error) {
+	body, err := marshalJSON(map[string]string{"reason": reason})
+	if err != nil {
+		return nil, err
+	}
+	return s.action(ctx, id, "lock", body)
+}
+
+// Unlock unlocks a workspace.
+// It returns a *terrakube.ValidationError if id is empty and a
+// *terrakube.APIError on server errors.
+func (s *WorkspaceService) Unlock(ctx context.Context, id string) (*Workspace, error) {
+	return s.action(ctx, id, "unlock", nil)
+}
+
+// ForceUnlock unlocks a workspace locked by another user.
+// It returns a *terrakube.ValidationError if id is empty and a
+// *terrakube.APIError on server errors.
+func (s *WorkspaceService) ForceUnlock(ctx context.Context, id string) (*Workspace, error) {
+	return s.action(ctx, id, "force-unlock", nil)
+}
+
+func (s *WorkspaceService) action(ctx context.Context, id, action string, body []byte) (*Workspace, error) {
+	if err := validateID("workspaceID", id); err != nil {
+		return nil, err
+	}
+
+	ws := &Workspace{}
+	if err := s.client.do(ctx, http.MethodPost, s.client.path("workspaces", id, "actions", action), body, ws); err != nil {
+		return nil, err
+	}
+	return ws, nil
+}
@@ -0,0 +1,144 @@
+package tfe_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	terrakube "github.com/terrakube-io/terrakube-go"
+	"github.com/terrakube-io/terrakube-go/testutil"
+	"github.com/terrakube-io/terrakube-go/tfe"
+)
+
+func TestWorkspaceService_Read(t *testing.T) {
+	t.Parallel()
+
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("GET /remote/tfe/v2/organizations/my-org/workspaces/networking", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSONAPI(t, w, http.StatusOK, &tfe.Workspace{ID: "ws-1", Name: "networking", ExecutionMode: "remote"})
+	})
+
+	client := newTestClient(t, srv)
+
+	ws, err := client.Workspaces.Read(context.Background(), "my-org", "networking")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ws.ID != "ws-1" || ws.ExecutionMode != "remote" {
+		t.Errorf("Workspace = %+v", ws)
+	}
+}
+
+func TestWorkspaceService_Read_NotFound(t *testing.T) {
+	t.Parallel()
+
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("GET /remote/tfe/v2/organizations/my-org/workspaces/missing", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteError(t, w, http.StatusNotFound, "not found")
+	})
+
+	client := newTestClient(t, srv)
+
+	_, err := client.Workspaces.Read(context.Background(), "my-org", "missing")
+	if !terrakube.IsNotFound(err) {
+		t.Fatalf("expected 404 APIError, got %v", err)
+	}
+}
+
+func TestWorkspaceService_Create(t *testing.T) {
+	t.Parallel()
+
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("POST /remote/tfe/v2/organizations/my-org/workspaces", func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Data struct {
+				Type       string                 `json:"type"`
+				Attributes map[string]interface{} `json:"attributes"`
+			} `json:"data"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Fatalf("failed to decode body: %v", err)
+		}
+		if payload.Data.Type != "workspaces" || payload.Data.Attributes["name"] != "app" {
+			t.Errorf("payload = %+v", payload.Data)
+		}
+		if v, ok := payload.Data.Attributes["auto-apply"]; !ok || v != false {
+			t.Errorf("auto-apply = %v (present %v), want false", v, ok)
+		}
+		testutil.WriteJSONAPI(t, w, http.StatusCreated, &tfe.Workspace{ID: "ws-2", Name: "app"})
+	})
+
+	client := newTestClient(t, srv)
+
+	ws, err := client.Workspaces.Create(context.Background(), "my-org", &tfe.Workspace{Name: "app"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ws.ID != "ws-2" {
+		t.Errorf("ID = %q, want %q", ws.ID, "ws-2")
+	}
+}
+
+func TestWorkspaceService_LockUnlock(t *testing.T) {
+	t.Parallel()
+
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("POST /remote/tfe/v2/workspaces/ws-1/actions/lock", func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("failed to read body: %v", err)
+		}
+		if string(body) != `{"reason":"maintenance"}` {
+			t.Errorf("body = %s", body)
+		}
+		testutil.WriteJSONAPI(t, w, http.StatusOK, &tfe.Workspace{ID: "ws-1", Locked: true})
+	})
+	srv.HandleFunc("POST /remote/tfe/v2/workspaces/ws-1/actions/unlock", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSONAPI(t, w, http.StatusOK, &tfe.Workspace{ID: "ws-1"})
+	})
+	srv.HandleFunc("POST /remote/tfe/v2/workspaces/ws-2/actions/lock", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteError(t, w, http.StatusConflict, "already locked")
+	})
+
+	client := newTestClient(t, srv)
+
+	ws, err := client.Workspaces.Lock(context.Background(), "ws-1", "maintenance")
+	if err != nil {
+		t.Fatalf("Lock: unexpected error: %v", err)
+	}
+	if !ws.Locked {
+		t.Error("expected workspace to be locked")
+	}
+
+	ws, err = client.Workspaces.Unlock(context.Background(), "ws-1")
+	if err != nil {
+		t.Fatalf("Unlock: unexpected error: %v", err)
+	}
+	if ws.Locked {
+		t.Error("expected workspace to be unlocked")
+	}
+
+	_, err = client.Workspaces.Lock(context.Background(), "ws-2", "")
+	if !terrakube.IsConflict(err) {
+		t.Errorf("expected 409 APIError, got %v", err)
+	}
+}
+
+func TestWorkspaceService_EmptyIDs(t *testing.T) {
+	t.Parallel()
+
+	client := newOfflineClient(t)
+
+	_, err := client.Workspaces.Read(context.Background(), "", "networking")
+	assertValidationError(t, err, "organization")
+	_, err = client.Workspaces.Read(context.Background(), "my-org", "")
+	assertValidationError(t, err, "name")
+	_, err = client.Workspaces.ReadByID(context.Background(), "")
+	assertValidationError(t, err, "workspaceID")
+	_, err = client.Workspaces.Lock(context.Background(), "", "reason")
+	assertValidationError(t, err, "workspaceID")
+	_, err = client.Workspaces.ForceUnlock(context.Background(), "")
+	assertValidationError(t, err, "workspaceID")
+}
@@ -2,6 +2,12 @@ package terrakube
 
 import "context"
 
+// Variable categories shared by workspace, organization, and collection variables.
+const (
+	CategoryTerraform = "TERRAFORM"
+	CategoryEnv       = "ENV"
+)
+
 // Variable represents a Terrakube workspace variable.
 type Variable struct {
 	ID          string  `jsonapi:"primary,variable"`
@@ -54,7 +60,9 @@ func (s *VariableService) Get(ctx context.Context, orgID, workspaceID, id string
 }
 
 // Create creates a new variable in the workspace.
-// It returns a *ValidationError if orgID or workspaceID is empty and a *APIError on server errors.
+// It returns a *ValidationError if orgID or workspaceID is empty or the value is
+// not valid HCL while Hcl is set, a *SecretError if a secret reference in the
+// value cannot be resolved, and a *APIError on server errors.
 func (s *VariableService) Create(ctx context.Context, orgID, workspaceID string, variable *Variable) (*Variable, error) {
 	if err := validateID("organization ID", orgID); err != nil {
 		return nil, err
@@ -62,13 +70,24 @@ func (s *VariableService) Create(ctx context.Context, orgID, workspaceID string,
 	if err := validateID("workspace ID", workspaceID); err != nil {
 		return nil, err
 	}
+	variable, err := s.client.resolveVariable(ctx, variable)
+	if err != nil {
+		return nil, err
+	}
+	if variable.Hcl {
+		if err := validateHCLValue(variable.Key, variable.Value); err != nil {
+			return nil, err
+		}
+	}
 
 	path := s.client.apiPath("organization", orgID, "workspace", workspaceID, "variable")
 	return s.create(ctx, path, variable)
 }
 
 // Update modifies an existing variable. The variable's ID field must be set.
-// It returns a *ValidationError if orgID, workspaceID, or the ID is empty and a *APIError on server errors.
+// It returns a *ValidationError if orgID, workspaceID, or the ID is empty or the
+// value is not valid HCL while Hcl is set, a *SecretError if a secret reference
+// in the value cannot be resolved, and a *APIError on server errors.
 func (s *VariableService) Update(ctx context.Context, orgID, workspaceID string, variable *Variable) (*Variable, error) {
 	if err := validateID("organization ID", orgID); err != nil {
 		return nil, err
@@ -79,6 +98,15 @@ func (s *VariableService) Update(ctx context.Context, orgID, workspaceID string,
 	if err := validateID("variable ID", variable.ID); err != nil {
 		return nil, err
 	}
+	variable, err := s.client.resolveVariable(ctx, variable)
+	if err != nil {
+		return nil, err
+	}
+	if variable.Hcl {
+		if err := validateHCLValue(variable.Key, variable.Value); err != nil {
+			return nil, err
+		}
+	}
 
 	path := s.client.apiPath("organization", orgID, "workspace", workspaceID, "variable", variable.ID)
 	return s.update(ctx, path, variable)
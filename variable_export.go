@@ -0,0 +1,216 @@
+package terrakube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// VariableFormat is the file format for exported TERRAFORM variables.
+type VariableFormat string
+
+// Variable file formats.
+const (
+	VariableFormatTfvars     VariableFormat = "tfvars"
+	VariableFormatTfvarsJSON VariableFormat = "tfvars.json"
+)
+
+// DefaultSensitivePlaceholder replaces the values of sensitive variables in
+// exports unless VariableExportOptions.Placeholder is set.
+const DefaultSensitivePlaceholder = "<sensitive>"
+
+// VariableExportOptions controls what Variables.Export writes.
+type VariableExportOptions struct {
+	// Inherited includes the organization variables and collection items
+	// the workspace inherits, merged as by Workspaces.EffectiveVariables.
+	Inherited bool
+	// Placeholder replaces the values of sensitive variables.
+	Placeholder string
+}
+
+// VariableExport holds a workspace's variables as file contents.
+type VariableExport struct {
+	Format VariableFormat
+	// Terraform holds the TERRAFORM variables in Format.
+	Terraform []byte
+	// Env holds the ENV variables as a dotenv file.
+	Env []byte
+	// Redacted lists the sensitive variables written as placeholders.
+	Redacted []*Variable
+}
+
+// dotenvKey matches the environment variable names a dotenv file can hold.
+var dotenvKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+// Export returns a workspace's variables as files for running Terraform
+// locally: TERRAFORM variables as a .tfvars or .tfvars.json file, with Hcl
+// values written as expressions, and ENV variables as a dotenv file. Both
+// are sorted by key, and LoadVariableFiles reads them back to the same
+// values, template sequences included. Sensitive values, which the API does
+// not return, are written as placeholders.
+// It returns a *ValidationError if orgID or workspaceID is empty, the format
+// is unknown, or a key cannot be written in its file, and a *APIError on
+// server errors.
+func (s *VariableService) Export(ctx context.Context, orgID, workspaceID string, format VariableFormat, opts *VariableExportOptions) (*VariableExport, error) {
+	if err := validateID("organization ID", orgID); err != nil {
+		return nil, err
+	}
+	if err := validateID("workspace ID", workspaceID); err != nil {
+		return nil, err
+	}
+	if format != VariableFormatTfvars && format != VariableFormatTfvarsJSON {
+		return nil, &ValidationError{Field: "format", Message: fmt.Sprintf("must be %s or %s", VariableFormatTfvars, VariableFormatTfvarsJSON)}
+	}
+	if opts == nil {
+		opts = &VariableExportOptions{}
+	}
+	placeholder := opts.Placeholder
+	if placeholder == "" {
+		placeholder = DefaultSensitivePlaceholder
+	}
+
+	vars, err := s.exportedVariables(ctx, orgID, workspaceID, opts.Inherited)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &VariableExport{Format: format}
+	var terraform, env []*Variable
+	for _, v := range vars {
+		if v.Sensitive {
+			export.Redacted = append(export.Redacted, v)
+			redacted := *v
+			redacted.Value, redacted.Hcl = placeholder, false
+			v = &redacted
+		}
+		switch v.Category {
+		case CategoryTerraform:
+			terraform = append(terraform, v)
+		case CategoryEnv:
+			env = append(env, v)
+		}
+	}
+
+	if format == VariableFormatTfvarsJSON {
+		export.Terraform, err = encodeTfvarsJSON(terraform)
+	} else {
+		export.Terraform, err = encodeTfvars(terraform)
+	}
+	if err != nil {
+		return nil, err
+	}
+	export.Env, err = encodeDotenv(env)
+	if err != nil {
+		return nil, err
+	}
+	return export, nil
+}
+
+// exportedVariables lists the workspace's variables, or its effective
+// variables if inherited is set, sorted by key.
+func (s *VariableService) exportedVariables(ctx context.Context, orgID, workspaceID string, inherited bool) ([]*Variable, error) {
+	var vars []*Variable
+	if inherited {
+		effective, err := s.client.Workspaces.EffectiveVariables(ctx, orgID, workspaceID)
+		if err != nil {
+			return nil, err
+		}
+		for _, ev := range effective {
+			w := ev.Winner
+			vars = append(vars, &Variable{
+				ID: w.ID, Key: ev.Key, Value: w.Value, Description: w.Description,
+				Category: ev.Category, Sensitive: w.Sensitive, Hcl: w.Hcl,
+			})
+		}
+	} else {
+		var err error
+		vars, err = s.List(ctx, orgID, workspaceID, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.SliceStable(vars, func(i, j int) bool { return vars[i].Key < vars[j].Key })
+	return vars, nil
+}
+
+// encodeTfvars writes variables as "name = value" lines.
+func encodeTfvars(vars []*Variable) ([]byte, error) {
+	var b strings.Builder
+	for _, v := range vars {
+		if !hclIdentifier.MatchString(v.Key) {
+			return nil, &ValidationError{Field: "variable key", Message: fmt.Sprintf("%q is not a valid Terraform variable name", v.Key)}
+		}
+		value := quoteHCL(v.Value)
+		if v.Hcl {
+			value = strings.TrimSpace(v.Value)
+		}
+		fmt.Fprintf(&b, "%s = %s\n", v.Key, value)
+	}
+	return []byte(b.String()), nil
+}
+
+// encodeTfvarsJSON writes variables as a JSON object. Hcl values must be
+// literals that DecodeHCL accepts.
+func encodeTfvarsJSON(vars []*Variable) ([]byte, error) {
+	if len(vars) == 0 {
+		return nil, nil
+	}
+	values := make(map[string]interface{}, len(vars))
+	for _, v := range vars {
+		if !v.Hcl {
+			values[v.Key] = v.Value
+			continue
+		}
+		value, err := parseHCL(v.Value)
+		if err != nil {
+			return nil, &ValidationError{Field: "value", Message: fmt.Sprintf("of %s cannot be written as JSON: %v", v.Key, err)}
+		}
+		values[v.Key] = value
+	}
+	data, err := json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encoding tfvars JSON: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// encodeDotenv writes variables as KEY="value" lines, escaped as ParseDotenv
+// expects.
+func encodeDotenv(vars []*Variable) ([]byte, error) {
+	escaper := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	var b strings.Builder
+	for _, v := range vars {
+		if !dotenvKey.MatchString(v.Key) {
+			return nil, &ValidationError{Field: "variable key", Message: fmt.Sprintf("%q is not a valid environment variable name", v.Key)}
+		}
+		fmt.Fprintf(&b, "%s=\"%s\"\n", v.Key, escaper.Replace(v.Value))
+	}
+	return []byte(b.String()), nil
+}
+
+// WriteFiles writes the export into dir as terrakube.auto.tfvars (or
+// terrakube.auto.tfvars.json), which Terraform loads automatically, and
+// .env. Files with no variables are not written.
+func (e *VariableExport) WriteFiles(dir string) error {
+	files := []struct {
+		name string
+		data []byte
+	}{
+		{"terrakube.auto." + string(e.Format), e.Terraform},
+		{".env", e.Env},
+	}
+	for _, f := range files {
+		if len(f.data) == 0 {
+			continue
+		}
+		if err := os.WriteFile(filepath.Join(dir, f.name), f.data, 0o600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package terrakube_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	terrakube "github.com/terrakube-io/terrakube-go"
+	"github.com/terrakube-io/terrakube-go/testutil"
+)
+
+func newVariableExportServer(t *testing.T) *terrakube.Client {
+	t.Helper()
+	srv := testutil.NewServer(t)
+	org := "/api/v1/organization/org-1"
+
+	srv.HandleFunc("GET "+org+"/workspace/ws-1/variable", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSONAPIList(t, w, http.StatusOK, []*terrakube.Variable{
+			{ID: "var-1", Key: "zones", Value: `["a", "b"]`, Category: terrakube.CategoryTerraform, Hcl: true},
+			{ID: "var-2", Key: "motd", Value: "say \"hi\"\n${name} %{if x}", Category: terrakube.CategoryTerraform},
+			{ID: "var-3", Key: "password", Category: terrakube.CategoryTerraform, Sensitive: true},
+			{ID: "var-4", Key: "TF_LOG", Value: "debug", Category: terrakube.CategoryEnv},
+			{ID: "var-5", Key: "API_TOKEN", Category: terrakube.CategoryEnv, Sensitive: true},
+			{ID: "var-6", Key: "count", Value: "3", Category: terrakube.CategoryTerraform},
+		})
+	})
+	srv.HandleFunc("GET "+org+"/globalvar", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSONAPIList(t, w, http.StatusOK, []*terrakube.OrganizationVariable{
+			{ID: "gv-1", Key: "owner", Value: "platform", Category: terrakube.CategoryTerraform},
+			{ID: "gv-2", Key: "count", Value: "1", Category: terrakube.CategoryTerraform},
+		})
+	})
+	srv.HandleFunc("GET "+org+"/collection", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSONAPIList(t, w, http.StatusOK, []*terrakube.Collection{})
+	})
+	return newTestClient(t, srv)
+}
+
+func TestVariableService_Export(t *testing.T) {
+	t.Parallel()
+
+	c := newVariableExportServer(t)
+	export, err := c.Variables.Export(context.Background(), "org-1", "ws-1", terrakube.VariableFormatTfvars, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantTerraform := `count = "3"
+motd = "say \"hi\"\n$${name} %%{if x}"
+password = "<sensitive>"
+zones = ["a", "b"]
+`
+	if got := string(export.Terraform); got != wantTerraform {
+		t.Errorf("Terraform =\n%s\nwant\n%s", got, wantTerraform)
+	}
+	wantEnv := "API_TOKEN=\"<sensitive>\"\nTF_LOG=\"debug\"\n"
+	if got := string(export.Env); got != wantEnv {
+		t.Errorf("Env =\n%s\nwant\n%s", got, wantEnv)
+	}
+	if len(export.Redacted) != 2 || export.Redacted[0].Key != "API_TOKEN" || export.Redacted[1].Key != "password" {
+		t.Errorf("Redacted = %v, want API_TOKEN and password", export.Redacted)
+	}
+
+	dir := t.TempDir()
+	if err := export.WriteFiles(dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	loaded, err := terrakube.LoadVariableFiles(filepath.Join(dir, "terrakube.auto.tfvars"), filepath.Join(dir, ".env"))
+	if err != nil {
+		t.Fatalf("loading export: %v", err)
+	}
+	want := map[string]string{
+		"count":     "3",
+		"motd":      "say \"hi\"\n${name} %{if x}",
+		"password":  "<sensitive>",
+		"zones":     `["a", "b"]`,
+		"API_TOKEN": "<sensitive>",
+		"TF_LOG":    "debug",
+	}
+	if len(loaded) != len(want) {
+		t.Fatalf("loaded %d variables, want %d", len(loaded), len(want))
+	}
+	for _, v := range loaded {
+		if v.Value != want[v.Key] {
+			t.Errorf("loaded %s = %q, want %q", v.Key, v.Value, want[v.Key])
+		}
+	}
+}
+
+func TestVariableService_Export_JSONInherited(t *testing.T) {
+	t.Parallel()
+
+	c := newVariableExportServer(t)
+	export, err := c.Variables.Export(context.Background(), "org-1", "ws-1", terrakube.VariableFormatTfvarsJSON,
+		&terrakube.VariableExportOptions{Inherited: true, Placeholder: "REDACTED"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `{
+  "count": "3",
+  "motd": "say \"hi\"\n${name} %{if x}",
+  "owner": "platform",
+  "password": "REDACTED",
+  "zones": [
+    "a",
+    "b"
+  ]
+}
+`
+	if got := string(export.Terraform); got != want {
+		t.Errorf("Terraform =\n%s\nwant\n%s", got, want)
+	}
+	if got := string(export.Env); got != "API_TOKEN=\"REDACTED\"\nTF_LOG=\"debug\"\n" {
+		t.Errorf("Env = %q", got)
+	}
+
+	loaded, err := terrakube.ParseTfvarsJSON(export.Terraform)
+	if err != nil {
+		t.Fatalf("parsing export: %v", err)
+	}
+	if loaded[1].Key != "motd" || loaded[1].Value != "say \"hi\"\n${name} %{if x}" {
+		t.Errorf("loaded %s = %q, want the original motd", loaded[1].Key, loaded[1].Value)
+	}
+}
+
+func TestVariableService_Export_Validation(t *testing.T) {
+	t.Parallel()
+
+	c := newTestClientFromURL(t, "https://example.com")
+	ctx := context.Background()
+
+	_, err := c.Variables.Export(ctx, "", "ws-1", terrakube.VariableFormatTfvars, nil)
+	assertValidationError(t, err, "organization ID")
+
+	_, err = c.Variables.Export(ctx, "org-1", "", terrakube.VariableFormatTfvars, nil)
+	assertValidationError(t, err, "workspace ID")
+
+	_, err = c.Variables.Export(ctx, "org-1", "ws-1", "yaml", nil)
+	var verr *terrakube.ValidationError
+	if !errors.As(err, &verr) || verr.Field != "format" {
+		t.Errorf("expected a format *ValidationError, got %v", err)
+	}
+}
@@ -0,0 +1,442 @@
+package terrakube
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LoadVariableFiles reads variables from .tfvars, .tfvars.json, and .env
+// files, choosing the parser by file name. Like terraform's -var-file, a
+// variable in a later file replaces one with the same Key and Category from
+// an earlier file.
+func LoadVariableFiles(paths ...string) ([]*Variable, error) {
+	var vars []*Variable
+	index := map[string]int{}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		var parsed []*Variable
+		name := filepath.Base(path)
+		switch {
+		case strings.HasSuffix(name, ".tfvars.json"):
+			parsed, err = ParseTfvarsJSON(data)
+		case strings.HasSuffix(name, ".tfvars"):
+			parsed, err = ParseTfvars(data)
+		case name == ".env" || strings.HasSuffix(name, ".env"):
+			parsed, err = ParseDotenv(data)
+		default:
+			return nil, fmt.Errorf("%s: unsupported variable file type", path)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		for _, v := range parsed {
+			k := variableKey(v.Key, v.Category)
+			if i, ok := index[k]; ok {
+				vars[i] = v
+				continue
+			}
+			index[k] = len(vars)
+			vars = append(vars, v)
+		}
+	}
+	return vars, nil
+}
+
+// ParseTfvarsJSON decodes a .tfvars.json file into TERRAFORM variables,
+// sorted by key. Strings, numbers, and booleans become plain values; lists
+// and objects become HCL values.
+func ParseTfvarsJSON(data []byte) ([]*Variable, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("decoding tfvars JSON: %w", err)
+	}
+
+	keys := make([]string, 0, len(raw))
+	for k := range raw {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	vars := make([]*Variable, 0, len(keys))
+	for _, k := range keys {
+		v := &Variable{Key: k, Category: CategoryTerraform}
+		value := bytes.TrimSpace(raw[k])
+		switch {
+		case len(value) > 0 && value[0] == '"':
+			if err := json.Unmarshal(value, &v.Value); err != nil {
+				return nil, fmt.Errorf("decoding %s: %w", k, err)
+			}
+		case len(value) > 0 && (value[0] == '[' || value[0] == '{'):
+			var compact bytes.Buffer
+			if err := json.Compact(&compact, value); err != nil {
+				return nil, fmt.Errorf("decoding %s: %w", k, err)
+			}
+			// JSON arrays and objects are valid HCL expressions.
+			v.Value, v.Hcl = compact.String(), true
+		default:
+			v.Value = string(value)
+			v.Hcl = v.Value == "null"
+		}
+		vars = append(vars, v)
+	}
+	return vars, nil
+}
+
+// ParseTfvars decodes a .tfvars file of top-level "name = value"
+// assignments into TERRAFORM variables, in file order. Quoted strings and
+// heredocs become plain values, as do numbers and booleans; lists, objects,
+// and other expressions are kept verbatim as HCL values.
+func ParseTfvars(data []byte) ([]*Variable, error) {
+	p := &tfvarsParser{src: string(data), line: 1}
+	var vars []*Variable
+	for {
+		p.skipSpace(true)
+		if p.eof() {
+			return vars, nil
+		}
+
+		key := p.identifier()
+		if key == "" {
+			return nil, p.errorf("expected a variable name")
+		}
+		p.skipSpace(false)
+		if !p.consume("=") {
+			return nil, p.errorf("expected = after %s", key)
+		}
+		p.skipSpace(false)
+
+		v := &Variable{Key: key, Category: CategoryTerraform}
+		var err error
+		switch {
+		case p.peek('"'):
+			v.Value, err = p.quoted()
+		case strings.HasPrefix(p.src[p.pos:], "<<"):
+			v.Value, err = p.heredoc()
+		case p.peek('[') || p.peek('{'):
+			v.Value, err = p.bracketed()
+			v.Hcl = true
+		default:
+			v.Value = p.bareword()
+			if v.Value == "" {
+				return nil, p.errorf("expected a value for %s", key)
+			}
+			if _, numErr := strconv.ParseFloat(v.Value, 64); numErr != nil && v.Value != "true" && v.Value != "false" {
+				v.Hcl = true
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		p.skipSpace(false)
+		switch {
+		case p.eof():
+		case p.consume("\n"):
+			p.line++
+		default:
+			return nil, p.errorf("unexpected %q after %s", p.src[p.pos], key)
+		}
+		vars = append(vars, v)
+	}
+}
+
+// tfvarsParser scans the subset of HCL used in .tfvars files.
+type tfvarsParser struct {
+	src  string
+	pos  int
+	line int
+}
+
+func (p *tfvarsParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("tfvars line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
+
+func (p *tfvarsParser) eof() bool { return p.pos >= len(p.src) }
+
+func (p *tfvarsParser) peek(c byte) bool { return !p.eof() && p.src[p.pos] == c }
+
+func (p *tfvarsParser) consume(s string) bool {
+	if strings.HasPrefix(p.src[p.pos:], s) {
+		p.pos += len(s)
+		return true
+	}
+	return false
+}
+
+// skipSpace skips blanks and comments, and newlines too when newlines is set.
+// A line comment is skipped up to, but not including, its newline.
+func (p *tfvarsParser) skipSpace(newlines bool) {
+	for !p.eof() {
+		rest := p.src[p.pos:]
+		switch {
+		case rest[0] == ' ' || rest[0] == '\t' || rest[0] == '\r':
+			p.pos++
+		case rest[0] == '\n' && newlines:
+			p.pos++
+			p.line++
+		case rest[0] == '#' || strings.HasPrefix(rest, "//"):
+			end := strings.IndexByte(rest, '\n')
+			if end < 0 {
+				end = len(rest)
+			}
+			p.pos += end
+		case strings.HasPrefix(rest, "/*"):
+			end := strings.Index(rest, "*/")
+			if end < 0 {
+				end = len(rest) - 2
+			}
+			p.line += strings.Count(rest[:end], "\n")
+			p.pos += end + 2
+		default:
+			return
+		}
+	}
+}
+
+func (p *tfvarsParser) identifier() string {
+	start := p.pos
+	for !p.eof() {
+		c := p.src[p.pos]
+		if c == '_' || c == '-' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || p.pos > start && c >= '0' && c <= '9' {
+			p.pos++
+			continue
+		}
+		break
+	}
+	return p.src[start:p.pos]
+}
+
+// quoted reads a double-quoted string and returns its unescaped value.
+// Only the escapes HCL accepts are allowed, and the template escapes $${
+// and %%{ are reduced to the literal ${ and %{.
+func (p *tfvarsParser) quoted() (string, error) {
+	start := p.pos
+	p.pos++
+	for !p.eof() {
+		switch p.src[p.pos] {
+		case '\\':
+			if p.pos+1 < len(p.src) && !strings.ContainsRune(hclEscapes, rune(p.src[p.pos+1])) {
+				return "", p.errorf("invalid escape %s in string", p.src[p.pos:p.pos+2])
+			}
+			p.pos += 2
+			continue
+		case '\n':
+			return "", p.errorf("unterminated string")
+		case '"':
+			p.pos++
+			s, err := strconv.Unquote(p.src[start:p.pos])
+			if err != nil {
+				return "", p.errorf("invalid string %s", p.src[start:p.pos])
+			}
+			return templateUnescaper.Replace(s), nil
+		}
+		p.pos++
+	}
+	return "", p.errorf("unterminated string")
+}
+
+// hclEscapes lists the characters that may follow a backslash in an HCL
+// quoted string.
+const hclEscapes = `nrt"\\uU`
+
+// templateUnescaper reduces HCL template escapes to the text they stand for.
+var templateUnescaper = strings.NewReplacer("$${", "${", "%%{", "%{")
+
+// heredoc reads a <<MARKER or <<-MARKER string. The indented form removes
+// the leading whitespace common to all lines.
+func (p *tfvarsParser) heredoc() (string, error) {
+	p.pos += 2
+	indented := p.consume("-")
+	marker := p.identifier()
+	if marker == "" {
+		return "", p.errorf("expected a heredoc marker")
+	}
+	p.skipSpace(false)
+	if !p.consume("\n") {
+		return "", p.errorf("expected a newline after <<%s", marker)
+	}
+	p.line++
+
+	var lines []string
+	for !p.eof() {
+		end := strings.IndexByte(p.src[p.pos:], '\n')
+		if end < 0 {
+			end = len(p.src) - p.pos
+		}
+		line := p.src[p.pos : p.pos+end]
+		if strings.TrimSpace(line) == marker {
+			p.pos += len(line)
+			if indented {
+				lines = dedent(lines)
+			}
+			if len(lines) == 0 {
+				return "", nil
+			}
+			return templateUnescaper.Replace(strings.Join(lines, "\n") + "\n"), nil
+		}
+		lines = append(lines, line)
+		p.pos += end
+		if p.consume("\n") {
+			p.line++
+		}
+	}
+	return "", p.errorf("heredoc %s is not terminated", marker)
+}
+
+func dedent(lines []string) []string {
+	indent := -1
+	for _, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		n := len(l) - len(strings.TrimLeft(l, " \t"))
+		if indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	out := make([]string, len(lines))
+	for i, l := range lines {
+		if len(l) >= indent && indent > 0 {
+			l = l[indent:]
+		}
+		out[i] = l
+	}
+	return out
+}
+
+// bracketed reads a list or object expression, which may span lines, and
+// returns it verbatim.
+func (p *tfvarsParser) bracketed() (string, error) {
+	start, startLine := p.pos, p.line
+	depth := 0
+	for !p.eof() {
+		switch c := p.src[p.pos]; c {
+		case '"':
+			if _, err := p.quoted(); err != nil {
+				return "", err
+			}
+			continue
+		case '#':
+			p.skipSpace(false)
+			continue
+		case '/':
+			if strings.HasPrefix(p.src[p.pos:], "//") || strings.HasPrefix(p.src[p.pos:], "/*") {
+				p.skipSpace(false)
+				continue
+			}
+		case '\n':
+			p.line++
+		case '[', '{', '(':
+			depth++
+		case ']', '}', ')':
+			depth--
+			if depth == 0 {
+				p.pos++
+				return p.src[start:p.pos], nil
+			}
+		}
+		p.pos++
+	}
+	p.line = startLine
+	return "", p.errorf("unterminated %c", p.src[start])
+}
+
+// bareword reads an unquoted expression up to the end of the line or a comment.
+func (p *tfvarsParser) bareword() string {
+	start := p.pos
+	for !p.eof() {
+		rest := p.src[p.pos:]
+		if rest[0] == '\n' || rest[0] == '#' || strings.HasPrefix(rest, "//") || strings.HasPrefix(rest, "/*") {
+			break
+		}
+		p.pos++
+	}
+	return strings.TrimSpace(p.src[start:p.pos])
+}
+
+// ParseDotenv decodes a dotenv file of KEY=VALUE lines into ENV variables,
+// in file order. Lines may start with "export"; values may be single-quoted
+// (literal), double-quoted (with \n, \t, \", and \\ escapes), or bare, where
+// a " #" starts a comment.
+func ParseDotenv(data []byte) ([]*Variable, error) {
+	var vars []*Variable
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("dotenv line %d: expected KEY=VALUE", n)
+		}
+		value = strings.TrimSpace(value)
+
+		switch {
+		case strings.HasPrefix(value, "'"):
+			end := strings.Index(value[1:], "'")
+			if end < 0 {
+				return nil, fmt.Errorf("dotenv line %d: unterminated quote", n)
+			}
+			value = value[1 : end+1]
+		case strings.HasPrefix(value, `"`):
+			s, err := unquoteDotenv(value)
+			if err != nil {
+				return nil, fmt.Errorf("dotenv line %d: %w", n, err)
+			}
+			value = s
+		default:
+			if i := strings.Index(value, " #"); i >= 0 {
+				value = strings.TrimSpace(value[:i])
+			}
+		}
+		vars = append(vars, &Variable{Key: key, Value: value, Category: CategoryEnv})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading dotenv: %w", err)
+	}
+	return vars, nil
+}
+
+// unquoteDotenv decodes a double-quoted dotenv value, ignoring anything
+// after the closing quote.
+func unquoteDotenv(s string) (string, error) {
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '"':
+			return b.String(), nil
+		case c == '\\' && i+1 < len(s):
+			i++
+			switch s[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			default:
+				b.WriteByte(s[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", fmt.Errorf("unterminated quote")
+}
@@ -0,0 +1,208 @@
+package terrakube_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	terrakube "github.com/terrakube-io/terrakube-go"
+)
+
+func TestParseTfvars(t *testing.T) {
+	t.Parallel()
+
+	data := `# Network settings
+region = "eu-west-1" // trailing comment
+instance_count = 3
+enabled        = true
+ami            = data.aws_ami.id
+tags = {
+  Team = "platform"
+  Note = "a } in a string"
+}
+zones = ["a", "b"] /* inline */
+escaped = "line\nbreak \"quoted\""
+interpolation = "$${name} stays literal"
+directive = "%%{if x}kept%%{endif}"
+script = <<-EOT
+    echo one
+      echo $${two}
+  EOT
+`
+	vars, err := terrakube.ParseTfvars([]byte(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []struct {
+		key, value string
+		hcl        bool
+	}{
+		{"region", "eu-west-1", false},
+		{"instance_count", "3", false},
+		{"enabled", "true", false},
+		{"ami", "data.aws_ami.id", true},
+		{"tags", "{\n  Team = \"platform\"\n  Note = \"a } in a string\"\n}", true},
+		{"zones", `["a", "b"]`, true},
+		{"escaped", "line\nbreak \"quoted\"", false},
+		{"interpolation", "${name} stays literal", false},
+		{"directive", "%{if x}kept%{endif}", false},
+		{"script", "echo one\n  echo ${two}\n", false},
+	}
+	if len(vars) != len(want) {
+		t.Fatalf("got %d variables, want %d", len(vars), len(want))
+	}
+	for i, w := range want {
+		v := vars[i]
+		if v.Key != w.key || v.Value != w.value || v.Hcl != w.hcl || v.Category != terrakube.CategoryTerraform {
+			t.Errorf("vars[%d] = %s=%q hcl=%v %s, want %s=%q hcl=%v TERRAFORM", i, v.Key, v.Value, v.Hcl, v.Category, w.key, w.value, w.hcl)
+		}
+	}
+}
+
+func TestParseTfvars_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"missing equals":   "region \"eu\"\n",
+		"unterminated":     "a = 1\nregion = \"eu\n",
+		"unclosed list":    "a = 1\n\nzones = [\"a\",\n",
+		"trailing garbage": "a = \"x\" y\n",
+		"no heredoc end":   "s = <<EOT\nbody\n",
+		"hex escape":       "s = \"\\x41\"\n",
+		"bell escape":      "s = \"\\a\"\n",
+		"octal escape":     "s = \"\\101\"\n",
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			_, err := terrakube.ParseTfvars([]byte(data))
+			if err == nil || !strings.Contains(err.Error(), "tfvars line") {
+				t.Errorf("expected a tfvars line error, got %v", err)
+			}
+		})
+	}
+
+	_, err := terrakube.ParseTfvars([]byte("a = 1\n\nzones = [\"a\",\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("expected the error on line 3, got %v", err)
+	}
+}
+
+func TestParseTfvarsJSON(t *testing.T) {
+	t.Parallel()
+
+	vars, err := terrakube.ParseTfvarsJSON([]byte(`{
+  "region": "eu-west-1",
+  "count": 3,
+  "enabled": false,
+  "tags": {"Team": "platform"},
+  "zones": ["a", "b"]
+}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]struct {
+		value string
+		hcl   bool
+	}{
+		"count":   {"3", false},
+		"enabled": {"false", false},
+		"region":  {"eu-west-1", false},
+		"tags":    {`{"Team":"platform"}`, true},
+		"zones":   {`["a","b"]`, true},
+	}
+	if len(vars) != len(want) {
+		t.Fatalf("got %d variables, want %d", len(vars), len(want))
+	}
+	if vars[0].Key != "count" || vars[4].Key != "zones" {
+		t.Errorf("variables are not sorted by key: %s ... %s", vars[0].Key, vars[4].Key)
+	}
+	for _, v := range vars {
+		w := want[v.Key]
+		if v.Value != w.value || v.Hcl != w.hcl || v.Category != terrakube.CategoryTerraform {
+			t.Errorf("%s = %q hcl=%v %s, want %q hcl=%v TERRAFORM", v.Key, v.Value, v.Hcl, v.Category, w.value, w.hcl)
+		}
+	}
+
+	if _, err := terrakube.ParseTfvarsJSON([]byte(`["not", "an", "object"]`)); err == nil {
+		t.Error("expected error for a non-object document")
+	}
+}
+
+func TestParseDotenv(t *testing.T) {
+	t.Parallel()
+
+	vars, err := terrakube.ParseDotenv([]byte(`# comment
+export AWS_REGION=eu-west-1
+TOKEN = 'literal $value # kept'
+GREETING="hello\nworld" # comment
+PLAIN=value # comment
+EMPTY=
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := [][2]string{
+		{"AWS_REGION", "eu-west-1"},
+		{"TOKEN", "literal $value # kept"},
+		{"GREETING", "hello\nworld"},
+		{"PLAIN", "value"},
+		{"EMPTY", ""},
+	}
+	if len(vars) != len(want) {
+		t.Fatalf("got %d variables, want %d", len(vars), len(want))
+	}
+	for i, w := range want {
+		if vars[i].Key != w[0] || vars[i].Value != w[1] || vars[i].Category != terrakube.CategoryEnv {
+			t.Errorf("vars[%d] = %s=%q %s, want %s=%q ENV", i, vars[i].Key, vars[i].Value, vars[i].Category, w[0], w[1])
+		}
+	}
+
+	if _, err := terrakube.ParseDotenv([]byte("OK=1\nnot a pair\n")); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected a line 2 error, got %v", err)
+	}
+}
+
+func TestLoadVariableFiles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"base.tfvars":        "region = \"eu-west-1\"\nsize = \"small\"\n",
+		"prod.tfvars.json":   `{"size": "large"}`,
+		".env":               "region=us-east-1\n",
+		"unsupported.config": "x",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	vars, err := terrakube.LoadVariableFiles(
+		filepath.Join(dir, "base.tfvars"),
+		filepath.Join(dir, "prod.tfvars.json"),
+		filepath.Join(dir, ".env"),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"TERRAFORM region=eu-west-1", "TERRAFORM size=large", "ENV region=us-east-1"}
+	if len(vars) != len(want) {
+		t.Fatalf("got %d variables, want %d", len(vars), len(want))
+	}
+	for i, w := range want {
+		if got := vars[i].Category + " " + vars[i].Key + "=" + vars[i].Value; got != w {
+			t.Errorf("vars[%d] = %q, want %q", i, got, w)
+		}
+	}
+
+	if _, err := terrakube.LoadVariableFiles(filepath.Join(dir, "unsupported.config")); err == nil {
+		t.Error("expected error for an unsupported file type")
+	}
+}
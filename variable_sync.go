@@ -0,0 +1,132 @@
+package terrakube
+
+import (
+	"context"
+	"fmt"
+)
+
+// VariableSyncOptions configures VariableService.Sync.
+type VariableSyncOptions struct {
+	// Delete removes existing variables that are not in the desired set.
+	Delete bool
+	// DryRun computes the result without changing any variable.
+	DryRun bool
+}
+
+// VariableSyncResult reports the outcome of VariableService.Sync. In a dry
+// run it lists the changes that would be made.
+type VariableSyncResult struct {
+	Created   []*Variable
+	Updated   []*Variable
+	Deleted   []*Variable
+	Unchanged []*Variable
+	// Unmanaged lists existing variables that are not in the desired set and
+	// were kept because Delete is unset.
+	Unmanaged []*Variable
+	// Preserved lists sensitive variables whose desired value is empty. Their
+	// stored value cannot be read back, so they are left untouched.
+	Preserved []*Variable
+}
+
+// variableKey identifies a variable within a workspace.
+func variableKey(key, category string) string {
+	return category + "/" + key
+}
+
+// Sync makes the workspace's variables match desired, matching them by Key
+// and Category. Sensitive values cannot be read back, so an existing
+// sensitive variable is always updated when desired sets a value, and left
+// untouched when desired leaves the value empty.
+// It returns a *ValidationError if orgID or workspaceID is empty or desired
+// holds an invalid or duplicate variable, and a *APIError on server errors.
+// On a server error the result lists the changes already made.
+func (s *VariableService) Sync(ctx context.Context, orgID, workspaceID string, desired []*Variable, opts VariableSyncOptions) (*VariableSyncResult, error) {
+	if err := validateID("organization ID", orgID); err != nil {
+		return nil, err
+	}
+	if err := validateID("workspace ID", workspaceID); err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	for _, v := range desired {
+		if err := validateID("variable key", v.Key); err != nil {
+			return nil, err
+		}
+		if v.Category != CategoryTerraform && v.Category != CategoryEnv {
+			return nil, &ValidationError{Field: "variable category", Message: fmt.Sprintf("%q of %s must be %s or %s", v.Category, v.Key, CategoryTerraform, CategoryEnv)}
+		}
+		k := variableKey(v.Key, v.Category)
+		if seen[k] {
+			return nil, &ValidationError{Field: "variable key", Message: fmt.Sprintf("%s %s is listed more than once", v.Category, v.Key)}
+		}
+		seen[k] = true
+	}
+
+	existing, err := s.List(ctx, orgID, workspaceID, nil)
+	if err != nil {
+		return nil, err
+	}
+	byKey := make(map[string]*Variable, len(existing))
+	for _, v := range existing {
+		byKey[variableKey(v.Key, v.Category)] = v
+	}
+
+	result := &VariableSyncResult{}
+	var creates, updates []*Variable
+	for _, want := range desired {
+		k := variableKey(want.Key, want.Category)
+		have, ok := byKey[k]
+		delete(byKey, k)
+		switch {
+		case !ok:
+			v := *want
+			v.ID = ""
+			creates = append(creates, &v)
+		case have.Sensitive && want.Value == "":
+			result.Preserved = append(result.Preserved, have)
+		case !have.Sensitive && have.Value == want.Value && have.Description == want.Description &&
+			have.Sensitive == want.Sensitive && have.Hcl == want.Hcl:
+			result.Unchanged = append(result.Unchanged, have)
+		default:
+			v := *have
+			v.Value, v.Description, v.Sensitive, v.Hcl = want.Value, want.Description, want.Sensitive, want.Hcl
+			updates = append(updates, &v)
+		}
+	}
+	var deletes []*Variable
+	for _, v := range existing {
+		if _, ok := byKey[variableKey(v.Key, v.Category)]; ok {
+			deletes = append(deletes, v)
+		}
+	}
+
+	if !opts.Delete {
+		result.Unmanaged, deletes = deletes, nil
+	}
+	if opts.DryRun {
+		result.Created, result.Updated, result.Deleted = creates, updates, deletes
+		return result, nil
+	}
+
+	for _, v := range creates {
+		created, err := s.Create(ctx, orgID, workspaceID, v)
+		if err != nil {
+			return result, fmt.Errorf("creating variable %s: %w", v.Key, err)
+		}
+		result.Created = append(result.Created, created)
+	}
+	for _, v := range updates {
+		// The API may answer an update with no content, so report what was sent.
+		if _, err := s.Update(ctx, orgID, workspaceID, v); err != nil {
+			return result, fmt.Errorf("updating variable %s: %w", v.Key, err)
+		}
+		result.Updated = append(result.Updated, v)
+	}
+	for _, v := range deletes {
+		if err := s.Delete(ctx, orgID, workspaceID, v.ID); err != nil {
+			return result, fmt.Errorf("deleting variable %s: %w", v.Key, err)
+		}
+		result.Deleted = append(result.Deleted, v)
+	}
+	return result, nil
+}
@@ -0,0 +1,184 @@
+package terrakube_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+	"testing"
+
+	terrakube "github.com/terrakube-io/terrakube-go"
+	"github.com/terrakube-io/terrakube-go/testutil"
+)
+
+// newVariableSyncServer serves the given workspace variables and records the
+// changes made to them.
+func newVariableSyncServer(t *testing.T, existing []*terrakube.Variable) (*terrakube.Client, *[]string) {
+	t.Helper()
+	srv := testutil.NewServer(t)
+
+	var mu sync.Mutex
+	var calls []string
+	record := func(s string) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls = append(calls, s)
+	}
+
+	base := "/api/v1/organization/org-1/workspace/ws-1/variable"
+	srv.HandleFunc("GET "+base, func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSONAPIList(t, w, http.StatusOK, existing)
+	})
+	srv.HandleFunc("POST "+base, func(w http.ResponseWriter, r *http.Request) {
+		attrs := decodeAttributes(t, r)
+		record("create " + attrs["key"].(string) + "=" + attrs["value"].(string))
+		testutil.WriteJSONAPI(t, w, http.StatusCreated, &terrakube.Variable{
+			ID: "var-new", Key: attrs["key"].(string), Value: attrs["value"].(string), Category: attrs["category"].(string),
+		})
+	})
+	srv.HandleFunc("PATCH "+base+"/{id}", func(w http.ResponseWriter, r *http.Request) {
+		attrs := decodeAttributes(t, r)
+		record("update " + r.PathValue("id") + " " + attrs["key"].(string) + "=" + attrs["value"].(string))
+		w.WriteHeader(http.StatusNoContent)
+	})
+	srv.HandleFunc("DELETE "+base+"/{id}", func(w http.ResponseWriter, r *http.Request) {
+		record("delete " + r.PathValue("id"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+	return newTestClient(t, srv), &calls
+}
+
+func testSyncVariables() ([]*terrakube.Variable, []*terrakube.Variable) {
+	existing := []*terrakube.Variable{
+		{ID: "var-1", Key: "region", Value: "eu-west-1", Category: terrakube.CategoryTerraform},
+		{ID: "var-2", Key: "size", Value: "small", Category: terrakube.CategoryTerraform},
+		{ID: "var-3", Key: "password", Category: terrakube.CategoryTerraform, Sensitive: true},
+		{ID: "var-4", Key: "token", Category: terrakube.CategoryEnv, Sensitive: true},
+		{ID: "var-5", Key: "old", Value: "x", Category: terrakube.CategoryTerraform},
+		{ID: "var-6", Key: "region", Value: "eu-west-1", Category: terrakube.CategoryEnv},
+	}
+	desired := []*terrakube.Variable{
+		{Key: "region", Value: "eu-west-1", Category: terrakube.CategoryTerraform},
+		{Key: "size", Value: "large", Category: terrakube.CategoryTerraform},
+		{Key: "password", Category: terrakube.CategoryTerraform, Sensitive: true},
+		{Key: "token", Value: "s3cret", Category: terrakube.CategoryEnv, Sensitive: true},
+		{Key: "new", Value: "y", Category: terrakube.CategoryTerraform},
+		{Key: "region", Value: "us-east-1", Category: terrakube.CategoryEnv},
+	}
+	return existing, desired
+}
+
+func variableKeys(vars []*terrakube.Variable) []string {
+	keys := make([]string, len(vars))
+	for i, v := range vars {
+		keys[i] = v.Category + "/" + v.Key
+	}
+	return keys
+}
+
+func assertKeys(t *testing.T, name string, vars []*terrakube.Variable, want ...string) {
+	t.Helper()
+	got := variableKeys(vars)
+	if len(got) != len(want) {
+		t.Errorf("%s = %v, want %v", name, got, want)
+		return
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("%s = %v, want %v", name, got, want)
+			return
+		}
+	}
+}
+
+func TestVariableService_Sync(t *testing.T) {
+	t.Parallel()
+
+	existing, desired := testSyncVariables()
+	c, calls := newVariableSyncServer(t, existing)
+
+	result, err := c.Variables.Sync(context.Background(), "org-1", "ws-1", desired, terrakube.VariableSyncOptions{Delete: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assertKeys(t, "Created", result.Created, "TERRAFORM/new")
+	assertKeys(t, "Updated", result.Updated, "TERRAFORM/size", "ENV/token", "ENV/region")
+	assertKeys(t, "Deleted", result.Deleted, "TERRAFORM/old")
+	assertKeys(t, "Unchanged", result.Unchanged, "TERRAFORM/region")
+	assertKeys(t, "Preserved", result.Preserved, "TERRAFORM/password")
+	assertKeys(t, "Unmanaged", result.Unmanaged)
+
+	got := append([]string(nil), *calls...)
+	sort.Strings(got)
+	want := []string{
+		"create new=y",
+		"delete var-5",
+		"update var-2 size=large",
+		"update var-4 token=s3cret",
+		"update var-6 region=us-east-1",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("calls = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("calls[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestVariableService_Sync_DryRun(t *testing.T) {
+	t.Parallel()
+
+	existing, desired := testSyncVariables()
+	c, calls := newVariableSyncServer(t, existing)
+
+	result, err := c.Variables.Sync(context.Background(), "org-1", "ws-1", desired, terrakube.VariableSyncOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(*calls) != 0 {
+		t.Errorf("dry run made changes: %v", *calls)
+	}
+
+	assertKeys(t, "Created", result.Created, "TERRAFORM/new")
+	assertKeys(t, "Updated", result.Updated, "TERRAFORM/size", "ENV/token", "ENV/region")
+	assertKeys(t, "Deleted", result.Deleted)
+	assertKeys(t, "Unmanaged", result.Unmanaged, "TERRAFORM/old")
+	if result.Updated[0].ID != "var-2" || result.Updated[0].Value != "large" {
+		t.Errorf("Updated[0] = %s %q, want var-2 %q", result.Updated[0].ID, result.Updated[0].Value, "large")
+	}
+}
+
+func TestVariableService_Sync_Validation(t *testing.T) {
+	t.Parallel()
+
+	c := newTestClientFromURL(t, "https://example.com")
+	ctx := context.Background()
+
+	_, err := c.Variables.Sync(ctx, "", "ws-1", nil, terrakube.VariableSyncOptions{})
+	assertValidationError(t, err, "organization ID")
+
+	_, err = c.Variables.Sync(ctx, "org-1", "", nil, terrakube.VariableSyncOptions{})
+	assertValidationError(t, err, "workspace ID")
+
+	_, err = c.Variables.Sync(ctx, "org-1", "ws-1", []*terrakube.Variable{{Category: terrakube.CategoryEnv}}, terrakube.VariableSyncOptions{})
+	assertValidationError(t, err, "variable key")
+
+	tests := map[string][]*terrakube.Variable{
+		"variable category": {{Key: "a", Category: "terraform"}},
+		"variable key": {
+			{Key: "a", Category: terrakube.CategoryEnv},
+			{Key: "a", Category: terrakube.CategoryEnv},
+		},
+	}
+	for field, desired := range tests {
+		_, err := c.Variables.Sync(ctx, "org-1", "ws-1", desired, terrakube.VariableSyncOptions{})
+		var verr *terrakube.ValidationError
+		if !errors.As(err, &verr) || verr.Field != field {
+			t.Errorf("expected a *ValidationError for %s, got %v", field, err)
+		}
+	}
+}
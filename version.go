@@ -0,0 +1,298 @@
+package terrakube
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrNoMatchingVersion indicates no available version satisfies a constraint.
+var ErrNoMatchingVersion = errors.New("no version matches the constraint")
+
+// Version is a semantic version as used by module and provider registries.
+type Version struct {
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease string
+	Metadata   string
+
+	original string
+}
+
+// ParseVersion parses a semantic version such as "1.2.3", "v1.2.3", or
+// "1.2.3-beta.1+build.5".
+func ParseVersion(s string) (*Version, error) {
+	v, segments, err := parseVersion(s)
+	if err != nil {
+		return nil, err
+	}
+	if segments != 3 {
+		return nil, fmt.Errorf("invalid version %q: expected MAJOR.MINOR.PATCH", s)
+	}
+	return v, nil
+}
+
+// parseVersion parses a full or partial version and returns the number of
+// numeric segments given. Missing segments are zero.
+func parseVersion(s string) (*Version, int, error) {
+	v := &Version{original: s}
+	rest := strings.TrimPrefix(strings.TrimSpace(s), "v")
+
+	if i := strings.IndexByte(rest, '+'); i >= 0 {
+		v.Metadata = rest[i+1:]
+		rest = rest[:i]
+	}
+	if i := strings.IndexByte(rest, '-'); i >= 0 {
+		v.Prerelease = rest[i+1:]
+		rest = rest[:i]
+		if v.Prerelease == "" {
+			return nil, 0, fmt.Errorf("invalid version %q: empty prerelease", s)
+		}
+	}
+
+	parts := strings.Split(rest, ".")
+	if len(parts) > 3 {
+		return nil, 0, fmt.Errorf("invalid version %q: too many segments", s)
+	}
+	nums := []*int{&v.Major, &v.Minor, &v.Patch}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return nil, 0, fmt.Errorf("invalid version %q: segment %q is not a number", s, p)
+		}
+		*nums[i] = n
+	}
+	return v, len(parts), nil
+}
+
+// String returns the version as originally written.
+func (v *Version) String() string {
+	if v.original != "" {
+		return v.original
+	}
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	if v.Metadata != "" {
+		s += "+" + v.Metadata
+	}
+	return s
+}
+
+// Compare returns -1, 0, or 1 as v is lower than, equal to, or higher than o,
+// following semantic versioning precedence. Build metadata is ignored.
+func (v *Version) Compare(o *Version) int {
+	for _, c := range [][2]int{{v.Major, o.Major}, {v.Minor, o.Minor}, {v.Patch, o.Patch}} {
+		if c[0] != c[1] {
+			if c[0] < c[1] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return comparePrerelease(v.Prerelease, o.Prerelease)
+}
+
+// comparePrerelease compares prerelease strings; a release sorts after any
+// prerelease of the same version.
+func comparePrerelease(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if an != bn {
+				if an < bn {
+					return -1
+				}
+				return 1
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		case as[i] != bs[i]:
+			if as[i] < bs[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case len(as) < len(bs):
+		return -1
+	case len(as) > len(bs):
+		return 1
+	}
+	return 0
+}
+
+// Constraints is a set of version constraints that must all be satisfied.
+type Constraints []*constraint
+
+type constraint struct {
+	op       string
+	version  *Version
+	segments int
+}
+
+// ParseConstraints parses a Terraform-style constraint string such as
+// "~> 1.2", ">= 1.0, < 2.0", or "1.4.0". An empty string matches any
+// release version.
+func ParseConstraints(s string) (Constraints, error) {
+	var cs Constraints
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		op := "="
+		for _, candidate := range []string{"~>", ">=", "<=", "!=", ">", "<", "="} {
+			if strings.HasPrefix(part, candidate) {
+				op = candidate
+				part = strings.TrimSpace(part[len(candidate):])
+				break
+			}
+		}
+
+		v, segments, err := parseVersion(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid constraint %q: %w", s, err)
+		}
+		cs = append(cs, &constraint{op: op, version: v, segments: segments})
+	}
+	return cs, nil
+}
+
+// Check reports whether v satisfies every constraint. As in Terraform, a
+// prerelease version only matches when a constraint names it exactly.
+func (cs Constraints) Check(v *Version) bool {
+	if v.Prerelease != "" && !cs.pinsPrerelease(v) {
+		return false
+	}
+	for _, c := range cs {
+		if !c.check(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func (cs Constraints) pinsPrerelease(v *Version) bool {
+	for _, c := range cs {
+		if c.op == "=" && c.version.Compare(v) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the constraints in their canonical form.
+func (cs Constraints) String() string {
+	parts := make([]string, len(cs))
+	for i, c := range cs {
+		parts[i] = c.op + " " + c.version.String()
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (c *constraint) check(v *Version) bool {
+	cmp := v.Compare(c.version)
+	switch c.op {
+	case "=":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case "~>":
+		if cmp < 0 {
+			return false
+		}
+		// Only the rightmost given segment may increase.
+		if c.segments < 3 {
+			return v.Major == c.version.Major
+		}
+		return v.Major == c.version.Major && v.Minor == c.version.Minor
+	}
+	return false
+}
+
+// SortVersions sorts versions in ascending semantic version order. Entries
+// that do not parse as versions sort first, in lexical order.
+func SortVersions(versions []string) {
+	parsed := make(map[string]*Version, len(versions))
+	for _, s := range versions {
+		if v, err := ParseVersion(s); err == nil {
+			parsed[s] = v
+		}
+	}
+	sort.SliceStable(versions, func(i, j int) bool {
+		a, b := parsed[versions[i]], parsed[versions[j]]
+		switch {
+		case a == nil && b == nil:
+			return versions[i] < versions[j]
+		case a == nil || b == nil:
+			return a == nil
+		}
+		return a.Compare(b) < 0
+	})
+}
+
+// LatestMatching returns the highest version in versions that satisfies the
+// constraints. Entries that do not parse as versions are skipped. It returns
+// an error wrapping ErrNoMatchingVersion if no version matches.
+func LatestMatching(versions []string, constraints string) (string, error) {
+	i, err := latestMatching(versions, "", constraints)
+	if err != nil {
+		return "", err
+	}
+	return versions[i], nil
+}
+
+// latestMatching returns the index of the highest version satisfying the
+// constraints after removing prefix, such as a module's tag prefix, from the
+// versions that have it.
+func latestMatching(versions []string, prefix, constraints string) (int, error) {
+	cs, err := ParseConstraints(constraints)
+	if err != nil {
+		return -1, err
+	}
+
+	best := -1
+	var bestVersion *Version
+	for i, s := range versions {
+		v, err := ParseVersion(strings.TrimPrefix(s, prefix))
+		if err != nil || !cs.Check(v) {
+			continue
+		}
+		if bestVersion == nil || v.Compare(bestVersion) > 0 {
+			best, bestVersion = i, v
+		}
+	}
+	if best < 0 {
+		return -1, fmt.Errorf("%w %q", ErrNoMatchingVersion, constraints)
+	}
+	return best, nil
+}
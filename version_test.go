@@ -0,0 +1,137 @@
+package terrakube_test
+
+import (
+	"errors"
+	"testing"
+
+	terrakube "github.com/terrakube-io/terrakube-go"
+)
+
+func TestParseVersion(t *testing.T) {
+	t.Parallel()
+
+	v, err := terrakube.ParseVersion("v1.2.3-beta.1+build.5")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v.Major != 1 || v.Minor != 2 || v.Patch != 3 || v.Prerelease != "beta.1" || v.Metadata != "build.5" {
+		t.Errorf("ParseVersion = %+v", v)
+	}
+
+	for _, bad := range []string{"", "1.2", "1.2.3.4", "1.x.3", "1.2.3-"} {
+		if _, err := terrakube.ParseVersion(bad); err == nil {
+			t.Errorf("ParseVersion(%q): expected error", bad)
+		}
+	}
+}
+
+func TestVersion_Compare(t *testing.T) {
+	t.Parallel()
+
+	ordered := []string{"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "1.0.1", "1.10.0", "2.0.0"}
+	for i := 0; i < len(ordered)-1; i++ {
+		a, _ := terrakube.ParseVersion(ordered[i])
+		b, _ := terrakube.ParseVersion(ordered[i+1])
+		if a.Compare(b) != -1 || b.Compare(a) != 1 {
+			t.Errorf("expected %s < %s", ordered[i], ordered[i+1])
+		}
+	}
+
+	a, _ := terrakube.ParseVersion("1.0.0+a")
+	b, _ := terrakube.ParseVersion("1.0.0+b")
+	if a.Compare(b) != 0 {
+		t.Error("build metadata should not affect precedence")
+	}
+}
+
+func TestConstraints_Check(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		constraint string
+		version    string
+		want       bool
+	}{
+		{"~> 1.2", "1.2.0", true},
+		{"~> 1.2", "1.9.4", true},
+		{"~> 1.2", "2.0.0", false},
+		{"~> 1.2", "1.1.9", false},
+		{"~> 1.2.3", "1.2.9", true},
+		{"~> 1.2.3", "1.3.0", false},
+		{">= 1.0, < 2.0", "1.5.0", true},
+		{">= 1.0, < 2.0", "2.0.0", false},
+		{"1.4.0", "1.4.0", true},
+		{"= 1.4.0", "1.4.1", false},
+		{"!= 1.4.0", "1.4.1", true},
+		{"", "3.0.0", true},
+		{">= 1.0", "2.0.0-rc.1", false},
+		{"2.0.0-rc.1", "2.0.0-rc.1", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.constraint+" "+tt.version, func(t *testing.T) {
+			t.Parallel()
+			cs, err := terrakube.ParseConstraints(tt.constraint)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			v, err := terrakube.ParseVersion(tt.version)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := cs.Check(v); got != tt.want {
+				t.Errorf("Check = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseConstraints_Invalid(t *testing.T) {
+	t.Parallel()
+
+	for _, bad := range []string{"~> one", ">= 1.0, < x", ">= 1.2.3.4"} {
+		if _, err := terrakube.ParseConstraints(bad); err == nil {
+			t.Errorf("ParseConstraints(%q): expected error", bad)
+		}
+	}
+}
+
+func TestLatestMatching(t *testing.T) {
+	t.Parallel()
+
+	versions := []string{"1.0.0", "1.3.1", "1.10.0", "2.0.0", "2.1.0-beta", "garbage"}
+
+	got, err := terrakube.LatestMatching(versions, "~> 1.2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "1.10.0" {
+		t.Errorf("LatestMatching = %q, want %q", got, "1.10.0")
+	}
+
+	got, err = terrakube.LatestMatching(versions, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "2.0.0" {
+		t.Errorf("LatestMatching = %q, want %q", got, "2.0.0")
+	}
+
+	if _, err := terrakube.LatestMatching(versions, ">= 3.0"); !errors.Is(err, terrakube.ErrNoMatchingVersion) {
+		t.Errorf("error = %v, want %v", err, terrakube.ErrNoMatchingVersion)
+	}
+}
+
+func TestSortVersions(t *testing.T) {
+	t.Parallel()
+
+	versions := []string{"1.10.0", "bogus", "1.2.0", "v1.9.0", "1.2.0-beta", "0.9.0"}
+	terrakube.SortVersions(versions)
+
+	want := []string{"bogus", "0.9.0", "1.2.0-beta", "1.2.0", "v1.9.0", "1.10.0"}
+	for i := range want {
+		if versions[i] != want[i] {
+			t.Fatalf("SortVersions = %v, want %v", versions, want)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package terrakube
+
+import (
+	"context"
+	"fmt"
+)
+
+// WorkspaceCloneOverrides configures Workspaces.Clone.
+type WorkspaceCloneOverrides struct {
+	// Name is the name of the new workspace. It is required.
+	Name string
+	// Branch and Folder, when set, replace the source workspace's values.
+	Branch string
+	Folder string
+	// Workspace, if set, can modify the new workspace before it is created.
+	Workspace func(ws *Workspace)
+	// Variable, if set, is called with a copy of each variable before it is
+	// created and can rewrite its value, for example with a secret
+	// reference for WithSecretResolver. Returning false skips the variable.
+	Variable func(v *Variable) bool
+}
+
+// WorkspaceCloneResult reports what Workspaces.Clone created.
+type WorkspaceCloneResult struct {
+	Workspace            *Workspace
+	Variables            []*Variable
+	Tags                 []*WorkspaceTag
+	Schedules            []*WorkspaceSchedule
+	Access               []*WorkspaceAccess
+	Webhooks             []*Webhook
+	WebhookEvents        []*WebhookEvent
+	CollectionReferences []*CollectionReference
+	// UncopiedSecrets lists the source's sensitive variables that were not
+	// created because the API does not return their values and the
+	// Variable hook did not set one.
+	UncopiedSecrets []*Variable
+}
+
+// workspaceSnapshot holds a workspace and its children as read from the API.
+type workspaceSnapshot struct {
+	workspace  *Workspace
+	variables  []*Variable
+	tags       []*WorkspaceTag
+	schedules  []*WorkspaceSchedule
+	access     []*WorkspaceAccess
+	webhooks   []*Webhook
+	events     map[string][]*WebhookEvent
+	references []*CollectionReference
+}
+
+// Clone creates a new workspace from the source workspace's settings and
+// copies its variables, tags, schedules, access grants, webhooks with their
+// events, and collection references. Everything is read before anything is
+// created. If creating fails part way, the result holds what was created so
+// far along with the error, so the caller can clean up.
+// It returns a *ValidationError if orgID, sourceID, or the new name is empty
+// and a *APIError on server errors.
+func (s *WorkspaceService) Clone(ctx context.Context, orgID, sourceID string, overrides WorkspaceCloneOverrides) (*WorkspaceCloneResult, error) {
+	if err := validateID("organization ID", orgID); err != nil {
+		return nil, err
+	}
+	if err := validateID("workspace ID", sourceID); err != nil {
+		return nil, err
+	}
+	if err := validateID("name", overrides.Name); err != nil {
+		return nil, err
+	}
+
+	src, err := s.snapshot(ctx, orgID, sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	ws := &Workspace{
+		Name:             overrides.Name,
+		Description:      src.workspace.Description,
+		Source:           src.workspace.Source,
+		Branch:           src.workspace.Branch,
+		Folder:           src.workspace.Folder,
+		TemplateID:       src.workspace.TemplateID,
+		IaCType:          src.workspace.IaCType,
+		IaCVersion:       src.workspace.IaCVersion,
+		ExecutionMode:    src.workspace.ExecutionMode,
+		AllowRemoteApply: src.workspace.AllowRemoteApply,
+		ModuleSSHKey:     src.workspace.ModuleSSHKey,
+		Vcs:              src.workspace.Vcs,
+	}
+	if overrides.Branch != "" {
+		ws.Branch = overrides.Branch
+	}
+	if overrides.Folder != "" {
+		ws.Folder = overrides.Folder
+	}
+	if overrides.Workspace != nil {
+		overrides.Workspace(ws)
+	}
+
+	result := &WorkspaceCloneResult{}
+	created, err := s.Create(ctx, orgID, ws)
+	if err != nil {
+		return nil, err
+	}
+	result.Workspace = created
+	id := created.ID
+
+	for _, v := range src.variables {
+		v := &Variable{Key: v.Key, Value: v.Value, Description: v.Description, Category: v.Category, Sensitive: v.Sensitive, Hcl: v.Hcl}
+		if overrides.Variable != nil && !overrides.Variable(v) {
+			continue
+		}
+		if v.Sensitive && v.Value == "" {
+			result.UncopiedSecrets = append(result.UncopiedSecrets, v)
+			continue
+		}
+		c, err := s.client.Variables.Create(ctx, orgID, id, v)
+		if err != nil {
+			return result, fmt.Errorf("creating variable %s: %w", v.Key, err)
+		}
+		result.Variables = append(result.Variables, c)
+	}
+
+	for _, t := range src.tags {
+		c, err := s.client.WorkspaceTags.Create(ctx, orgID, id, &WorkspaceTag{TagID: t.TagID})
+		if err != nil {
+			return result, fmt.Errorf("creating workspace tag %s: %w", t.TagID, err)
+		}
+		result.Tags = append(result.Tags, c)
+	}
+
+	for _, sch := range src.schedules {
+		c, err := s.client.WorkspaceSchedules.Create(ctx, id, &WorkspaceSchedule{Schedule: sch.Schedule, TemplateID: sch.TemplateID})
+		if err != nil {
+			return result, fmt.Errorf("creating schedule %s: %w", sch.Schedule, err)
+		}
+		result.Schedules = append(result.Schedules, c)
+	}
+
+	for _, a := range src.access {
+		c, err := s.client.WorkspaceAccess.Create(ctx, orgID, id, &WorkspaceAccess{
+			Name: a.Name, ManageState: a.ManageState, ManageWorkspace: a.ManageWorkspace, ManageJob: a.ManageJob,
+		})
+		if err != nil {
+			return result, fmt.Errorf("creating access for %s: %w", a.Name, err)
+		}
+		result.Access = append(result.Access, c)
+	}
+
+	for _, wh := range src.webhooks {
+		// The remote hook belongs to the source; Terrakube registers a new one.
+		c, err := s.client.Webhooks.Create(ctx, orgID, id, &Webhook{
+			Path: wh.Path, Branch: wh.Branch, TemplateID: wh.TemplateID, Event: wh.Event,
+		})
+		if err != nil {
+			return result, fmt.Errorf("creating webhook: %w", err)
+		}
+		result.Webhooks = append(result.Webhooks, c)
+
+		for _, ev := range src.events[wh.ID] {
+			e, err := s.client.WebhookEvents.Create(ctx, orgID, id, c.ID, &WebhookEvent{
+				Branch: ev.Branch, Event: ev.Event, Path: ev.Path, Priority: ev.Priority, TemplateID: ev.TemplateID,
+				Webhook: &Webhook{ID: c.ID},
+			})
+			if err != nil {
+				return result, fmt.Errorf("creating webhook event: %w", err)
+			}
+			result.WebhookEvents = append(result.WebhookEvents, e)
+		}
+	}
+
+	for _, ref := range src.references {
+		c, err := s.client.CollectionReferences.Create(ctx, orgID, ref.Collection.ID, &CollectionReference{
+			Description: ref.Description,
+			Workspace:   &Workspace{ID: id},
+			Collection:  &Collection{ID: ref.Collection.ID},
+		})
+		if err != nil {
+			return result, fmt.Errorf("attaching collection %s: %w", ref.Collection.Name, err)
+		}
+		result.CollectionReferences = append(result.CollectionReferences, c)
+	}
+
+	return result, nil
+}
+
+// snapshot reads a workspace and its children.
+func (s *WorkspaceService) snapshot(ctx context.Context, orgID, workspaceID string) (*workspaceSnapshot, error) {
+	snap := &workspaceSnapshot{events: map[string][]*WebhookEvent{}}
+	var err error
+
+	if snap.workspace, err = s.Get(ctx, orgID, workspaceID); err != nil {
+		return nil, err
+	}
+	if snap.variables, err = s.client.Variables.List(ctx, orgID, workspaceID, nil); err != nil {
+		return nil, err
+	}
+	if snap.tags, err = s.client.WorkspaceTags.List(ctx, orgID, workspaceID, nil); err != nil {
+		return nil, err
+	}
+	if snap.schedules, err = s.client.WorkspaceSchedules.List(ctx, workspaceID, nil); err != nil {
+		return nil, err
+	}
+	if snap.access, err = s.client.WorkspaceAccess.List(ctx, orgID, workspaceID, nil); err != nil {
+		return nil, err
+	}
+	if snap.webhooks, err = s.client.Webhooks.List(ctx, orgID, workspaceID, nil); err != nil {
+		return nil, err
+	}
+	for _, wh := range snap.webhooks {
+		if snap.events[wh.ID], err = s.client.WebhookEvents.List(ctx, orgID, workspaceID, wh.ID, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	if snap.references, err = s.workspaceReferences(ctx, orgID, workspaceID); err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
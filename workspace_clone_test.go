@@ -0,0 +1,174 @@
+package terrakube_test
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	terrakube "github.com/terrakube-io/terrakube-go"
+	"github.com/terrakube-io/terrakube-go/testutil"
+)
+
+func TestWorkspaceService_Clone(t *testing.T) {
+	t.Parallel()
+
+	srv := testutil.NewServer(t)
+	org := "/api/v1/organization/org-1"
+	src := org + "/workspace/ws-src"
+	dst := org + "/workspace/ws-new"
+
+	var mu sync.Mutex
+	var created []string
+	record := func(s string) {
+		mu.Lock()
+		defer mu.Unlock()
+		created = append(created, s)
+	}
+
+	srv.HandleFunc("GET "+src, func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSONAPI(t, w, http.StatusOK, &terrakube.Workspace{
+			ID: "ws-src", Name: "app-prod", Source: "https://github.com/acme/app.git", Branch: "main", Folder: "/prod",
+			IaCType: "terraform", IaCVersion: "1.9.0", ExecutionMode: "remote", Locked: true,
+		})
+	})
+	srv.HandleFunc("GET "+src+"/variable", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSONAPIList(t, w, http.StatusOK, []*terrakube.Variable{
+			{ID: "var-1", Key: "env", Value: "prod", Category: terrakube.CategoryTerraform},
+			{ID: "var-2", Key: "db_password", Category: terrakube.CategoryTerraform, Sensitive: true},
+			{ID: "var-3", Key: "API_TOKEN", Category: terrakube.CategoryEnv, Sensitive: true},
+		})
+	})
+	srv.HandleFunc("GET "+src+"/workspaceTag", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSONAPIList(t, w, http.StatusOK, []*terrakube.WorkspaceTag{{ID: "wt-1", TagID: "tag-1"}})
+	})
+	srv.HandleFunc("GET /api/v1/workspace/ws-src/schedule", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSONAPIList(t, w, http.StatusOK, []*terrakube.WorkspaceSchedule{{ID: "sch-1", Schedule: "0 2 * * *", TemplateID: "tpl-1"}})
+	})
+	srv.HandleFunc("GET "+src+"/access", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSONAPIList(t, w, http.StatusOK, []*terrakube.WorkspaceAccess{{ID: "acc-1", Name: "devs", ManageJob: true}})
+	})
+	srv.HandleFunc("GET "+src+"/webhook", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSONAPIList(t, w, http.StatusOK, []*terrakube.Webhook{{ID: "wh-1", Path: "/prod", Branch: "main", RemoteHookID: "remote-1"}})
+	})
+	srv.HandleFunc("GET "+src+"/webhook/wh-1/events", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSONAPIList(t, w, http.StatusOK, []*terrakube.WebhookEvent{{ID: "ev-1", Event: "PUSH", Branch: "main", Priority: 1, TemplateID: "tpl-2"}})
+	})
+	srv.HandleFunc("GET "+org+"/collection", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSONAPIList(t, w, http.StatusOK, []*terrakube.Collection{{ID: "col-1", Name: "shared", Priority: 1}})
+	})
+	srv.HandleFunc("GET "+org+"/collection/col-1/reference", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSON(t, w, http.StatusOK, map[string]interface{}{
+			"data": []map[string]interface{}{{
+				"type":       "reference",
+				"id":         "ref-1",
+				"attributes": map[string]string{"description": "shared settings"},
+				"relationships": map[string]interface{}{
+					"workspace": map[string]interface{}{"data": map[string]string{"type": "workspace", "id": "ws-src"}},
+				},
+			}},
+		})
+	})
+
+	srv.HandleFunc("POST "+org+"/workspace", func(w http.ResponseWriter, r *http.Request) {
+		attrs := decodeAttributes(t, r)
+		record("workspace " + attrs["name"].(string) + " " + attrs["branch"].(string) + " " + attrs["folder"].(string))
+		if attrs["locked"] == true {
+			t.Error("clone should not copy the lock")
+		}
+		testutil.WriteJSONAPI(t, w, http.StatusCreated, &terrakube.Workspace{ID: "ws-new", Name: attrs["name"].(string)})
+	})
+	srv.HandleFunc("POST "+dst+"/variable", func(w http.ResponseWriter, r *http.Request) {
+		attrs := decodeAttributes(t, r)
+		record("variable " + attrs["key"].(string) + "=" + attrs["value"].(string))
+		testutil.WriteJSONAPI(t, w, http.StatusCreated, &terrakube.Variable{ID: "var-new", Key: attrs["key"].(string)})
+	})
+	srv.HandleFunc("POST "+dst+"/workspaceTag", func(w http.ResponseWriter, r *http.Request) {
+		record("tag " + decodeAttributes(t, r)["tagId"].(string))
+		testutil.WriteJSONAPI(t, w, http.StatusCreated, &terrakube.WorkspaceTag{ID: "wt-new"})
+	})
+	srv.HandleFunc("POST /api/v1/workspace/ws-new/schedule", func(w http.ResponseWriter, r *http.Request) {
+		record("schedule " + decodeAttributes(t, r)["cron"].(string))
+		testutil.WriteJSONAPI(t, w, http.StatusCreated, &terrakube.WorkspaceSchedule{ID: "sch-new"})
+	})
+	srv.HandleFunc("POST "+dst+"/access", func(w http.ResponseWriter, r *http.Request) {
+		record("access " + decodeAttributes(t, r)["name"].(string))
+		testutil.WriteJSONAPI(t, w, http.StatusCreated, &terrakube.WorkspaceAccess{ID: "acc-new"})
+	})
+	srv.HandleFunc("POST "+dst+"/webhook", func(w http.ResponseWriter, r *http.Request) {
+		attrs := decodeAttributes(t, r)
+		record("webhook " + attrs["path"].(string) + " remote=" + attrs["remoteHookId"].(string))
+		testutil.WriteJSONAPI(t, w, http.StatusCreated, &terrakube.Webhook{ID: "wh-new"})
+	})
+	srv.HandleFunc("POST "+dst+"/webhook/wh-new/event", func(w http.ResponseWriter, r *http.Request) {
+		record("event " + decodeAttributes(t, r)["event"].(string))
+		testutil.WriteJSONAPI(t, w, http.StatusCreated, &terrakube.WebhookEvent{ID: "ev-new"})
+	})
+	srv.HandleFunc("POST "+org+"/collection/col-1/reference", func(w http.ResponseWriter, r *http.Request) {
+		record("reference " + decodeAttributes(t, r)["description"].(string))
+		testutil.WriteJSONAPI(t, w, http.StatusCreated, &terrakube.CollectionReference{ID: "ref-new"})
+	})
+
+	c := newTestClient(t, srv)
+	result, err := c.Workspaces.Clone(context.Background(), "org-1", "ws-src", terrakube.WorkspaceCloneOverrides{
+		Name:   "app-staging",
+		Branch: "staging",
+		Variable: func(v *terrakube.Variable) bool {
+			switch v.Key {
+			case "env":
+				v.Value = "staging"
+			case "API_TOKEN":
+				v.Value = "staging-token"
+			}
+			return true
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Workspace.ID != "ws-new" {
+		t.Errorf("Workspace.ID = %q, want ws-new", result.Workspace.ID)
+	}
+	if len(result.UncopiedSecrets) != 1 || result.UncopiedSecrets[0].Key != "db_password" {
+		t.Errorf("UncopiedSecrets = %v, want db_password", result.UncopiedSecrets)
+	}
+	if len(result.Variables) != 2 || len(result.Tags) != 1 || len(result.Schedules) != 1 || len(result.Access) != 1 ||
+		len(result.Webhooks) != 1 || len(result.WebhookEvents) != 1 || len(result.CollectionReferences) != 1 {
+		t.Errorf("unexpected result counts: %+v", result)
+	}
+
+	sort.Strings(created)
+	want := []string{
+		"access devs",
+		"event PUSH",
+		"reference shared settings",
+		"schedule 0 2 * * *",
+		"tag tag-1",
+		"variable API_TOKEN=staging-token",
+		"variable env=staging",
+		"webhook /prod remote=",
+		"workspace app-staging staging /prod",
+	}
+	if strings.Join(created, "\n") != strings.Join(want, "\n") {
+		t.Errorf("created:\n%s\nwant:\n%s", strings.Join(created, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestWorkspaceService_Clone_Validation(t *testing.T) {
+	t.Parallel()
+
+	c := newTestClientFromURL(t, "https://example.com")
+	ctx := context.Background()
+
+	_, err := c.Workspaces.Clone(ctx, "", "ws-1", terrakube.WorkspaceCloneOverrides{Name: "copy"})
+	assertValidationError(t, err, "organization ID")
+
+	_, err = c.Workspaces.Clone(ctx, "org-1", "", terrakube.WorkspaceCloneOverrides{Name: "copy"})
+	assertValidationError(t, err, "workspace ID")
+
+	_, err = c.Workspaces.Clone(ctx, "org-1", "ws-1", terrakube.WorkspaceCloneOverrides{})
+	assertValidationError(t, err, "name")
+}
@@ -0,0 +1,213 @@
+package terrakube
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Sentinel errors returned by workspace locking.
+var (
+	// ErrWorkspaceLocked indicates the workspace is already locked by someone else.
+	ErrWorkspaceLocked = errors.New("workspace is already locked")
+	// ErrLockLost indicates a lease's lock was released or replaced by someone else.
+	ErrLockLost = errors.New("workspace lock is no longer held by this lease")
+)
+
+// Lease is a workspace lock acquired by Lock. Call Unlock to release it.
+// A Lease is safe for concurrent use.
+type Lease struct {
+	OrganizationID string
+	WorkspaceID    string
+	// ID identifies the lease. It is recorded in the workspace's lock
+	// description so that unlocks and relocks by others can be detected.
+	ID     string
+	Reason string
+
+	service  *WorkspaceService
+	mu       sync.Mutex
+	released bool
+}
+
+// Lock locks a workspace and returns a lease that must be released with
+// Unlock. The reason is recorded in the workspace's lock description, and
+// the workspace is read back to confirm the lock is ours.
+// It returns a *ValidationError if orgID or workspaceID is empty, an error
+// wrapping ErrWorkspaceLocked if the workspace is already locked or another
+// caller locked it at the same time, and a *APIError on server errors.
+func (s *WorkspaceService) Lock(ctx context.Context, orgID, workspaceID, reason string) (*Lease, error) {
+	return s.lock(ctx, orgID, workspaceID, reason, false)
+}
+
+// ForceLock is like Lock but takes over an existing lock instead of failing.
+func (s *WorkspaceService) ForceLock(ctx context.Context, orgID, workspaceID, reason string) (*Lease, error) {
+	return s.lock(ctx, orgID, workspaceID, reason, true)
+}
+
+// WithLock locks a workspace, calls fn, and releases the lock when fn
+// returns or panics. The lock is released even if ctx has been cancelled.
+// It returns fn's error joined with any error from releasing the lock.
+func (s *WorkspaceService) WithLock(ctx context.Context, orgID, workspaceID, reason string, fn func(ctx context.Context) error) (err error) {
+	lease, err := s.Lock(ctx, orgID, workspaceID, reason)
+	if err != nil {
+		return err
+	}
+
+	// Deferred so the lock is also released while a panic unwinds.
+	defer func() {
+		err = errors.Join(err, lease.Unlock(context.WithoutCancel(ctx)))
+	}()
+
+	return fn(ctx)
+}
+
+func (s *WorkspaceService) lock(ctx context.Context, orgID, workspaceID, reason string, force bool) (*Lease, error) {
+	if err := validateID("organization ID", orgID); err != nil {
+		return nil, err
+	}
+	if err := validateID("workspace ID", workspaceID); err != nil {
+		return nil, err
+	}
+
+	ws, err := s.Get(ctx, orgID, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if ws.Locked && !force {
+		return nil, fmt.Errorf("workspace %s: %w (%s)", workspaceID, ErrWorkspaceLocked, lockDescription(ws))
+	}
+
+	id, err := newLeaseID()
+	if err != nil {
+		return nil, err
+	}
+	lease := &Lease{
+		OrganizationID: orgID,
+		WorkspaceID:    workspaceID,
+		ID:             id,
+		Reason:         reason,
+		service:        s,
+	}
+
+	desc := lease.description()
+	if err := s.setLock(ctx, orgID, workspaceID, &desc); err != nil {
+		return nil, err
+	}
+
+	// The update is not conditional, so another caller may have locked the
+	// workspace between the read and the write. Whoever wrote last holds it.
+	if _, err := lease.current(ctx); err != nil {
+		if errors.Is(err, ErrLockLost) {
+			return nil, fmt.Errorf("workspace %s: %w (%s)", workspaceID, ErrWorkspaceLocked, err)
+		}
+		return nil, err
+	}
+	return lease, nil
+}
+
+// Check reports whether the lease still holds the workspace lock.
+// It returns an error wrapping ErrLockLost if the workspace was unlocked or
+// relocked by someone else, and a *APIError on server errors.
+func (l *Lease) Check(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.released {
+		return fmt.Errorf("lease %s: %w", l.ID, ErrLockLost)
+	}
+	_, err := l.current(ctx)
+	return err
+}
+
+// Unlock releases the workspace lock. Calling Unlock again after a successful
+// release is a no-op. It returns an error wrapping ErrLockLost, without
+// modifying the workspace, if the lock was released or replaced by someone
+// else, and a *APIError on server errors.
+func (l *Lease) Unlock(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.released {
+		return nil
+	}
+
+	if _, err := l.current(ctx); err != nil {
+		if errors.Is(err, ErrLockLost) {
+			l.released = true
+		}
+		return err
+	}
+
+	if err := l.service.setLock(ctx, l.OrganizationID, l.WorkspaceID, nil); err != nil {
+		return err
+	}
+	l.released = true
+	return nil
+}
+
+// workspaceLockPatch is the partial workspace sent to lock or unlock it, so
+// that attributes changed on the server since the workspace was fetched are
+// left alone.
+type workspaceLockPatch struct {
+	ID              string  `jsonapi:"primary,workspace"`
+	Locked          bool    `jsonapi:"attr,locked"`
+	LockDescription *string `jsonapi:"attr,lockDescription"`
+}
+
+// setLock patches only the lock attributes of a workspace. A nil
+// description unlocks it.
+func (s *WorkspaceService) setLock(ctx context.Context, orgID, workspaceID string, desc *string) error {
+	patch := &workspaceLockPatch{ID: workspaceID, Locked: desc != nil, LockDescription: desc}
+	path := s.client.apiPath("organization", orgID, "workspace", workspaceID)
+	req, err := s.client.request(ctx, http.MethodPatch, path, patch)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.do(ctx, req, nil)
+	return err
+}
+
+// current fetches the workspace and verifies the lease still holds its lock.
+func (l *Lease) current(ctx context.Context) (*Workspace, error) {
+	ws, err := l.service.Get(ctx, l.OrganizationID, l.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if !ws.Locked {
+		return nil, fmt.Errorf("lease %s: %w: workspace was unlocked", l.ID, ErrLockLost)
+	}
+	if ws.LockDescription == nil || !strings.HasSuffix(*ws.LockDescription, l.marker()) {
+		return nil, fmt.Errorf("lease %s: %w: workspace was relocked (%s)", l.ID, ErrLockLost, lockDescription(ws))
+	}
+	return ws, nil
+}
+
+func (l *Lease) marker() string {
+	return "[lease " + l.ID + "]"
+}
+
+func (l *Lease) description() string {
+	if l.Reason == "" {
+		return l.marker()
+	}
+	return l.Reason + " " + l.marker()
+}
+
+// lockDescription returns a workspace's lock description for error messages.
+func lockDescription(ws *Workspace) string {
+	if ws.LockDescription == nil || *ws.LockDescription == "" {
+		return "no description"
+	}
+	return *ws.LockDescription
+}
+
+func newLeaseID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generating lease ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
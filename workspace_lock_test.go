@@ -0,0 +1,291 @@
+package terrakube_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	terrakube "github.com/terrakube-io/terrakube-go"
+	"github.com/terrakube-io/terrakube-go/testutil"
+)
+
+// fakeLockWorkspace is an in-memory workspace served over GET and PATCH.
+type fakeLockWorkspace struct {
+	mu      sync.Mutex
+	ws      terrakube.Workspace
+	patches int
+	// attrs holds the attribute names of each PATCH, in order.
+	attrs [][]string
+	// rival, if set, replaces the description of the next lock written,
+	// as if another caller's lock had landed right after it.
+	rival *string
+}
+
+func (f *fakeLockWorkspace) set(locked bool, desc *string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.ws.Locked = locked
+	f.ws.LockDescription = desc
+}
+
+func (f *fakeLockWorkspace) snapshot() terrakube.Workspace {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.ws
+}
+
+func newLockServer(t *testing.T, f *fakeLockWorkspace) *testutil.Server {
+	t.Helper()
+	f.ws.ID = "ws-1"
+	f.ws.Name = "prod"
+
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("GET /api/v1/organization/org-1/workspace/ws-1", func(w http.ResponseWriter, _ *http.Request) {
+		ws := f.snapshot()
+		testutil.WriteJSONAPI(t, w, http.StatusOK, &ws)
+	})
+	srv.HandleFunc("PATCH /api/v1/organization/org-1/workspace/ws-1", func(w http.ResponseWriter, r *http.Request) {
+		attrs := decodeAttributes(t, r)
+		names := make([]string, 0, len(attrs))
+		for name := range attrs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		f.mu.Lock()
+		f.patches++
+		f.attrs = append(f.attrs, names)
+		f.ws.Locked, _ = attrs["locked"].(bool)
+		if desc, ok := attrs["lockDescription"].(string); ok {
+			f.ws.LockDescription = &desc
+		} else {
+			f.ws.LockDescription = nil
+		}
+		if f.ws.Locked && f.rival != nil {
+			f.ws.LockDescription, f.rival = f.rival, nil
+		}
+		ws := f.ws
+		f.mu.Unlock()
+		testutil.WriteJSONAPI(t, w, http.StatusOK, &ws)
+	})
+	return srv
+}
+
+func TestWorkspaceService_Lock(t *testing.T) {
+	t.Parallel()
+
+	f := &fakeLockWorkspace{}
+	client := newTestClient(t, newLockServer(t, f))
+
+	lease, err := client.Workspaces.Lock(context.Background(), "org-1", "ws-1", "db migration")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ws := f.snapshot()
+	if !ws.Locked || ws.LockDescription == nil || !strings.HasPrefix(*ws.LockDescription, "db migration [lease ") {
+		t.Fatalf("workspace = locked %v, description %v", ws.Locked, ws.LockDescription)
+	}
+	if err := lease.Check(context.Background()); err != nil {
+		t.Errorf("Check: unexpected error: %v", err)
+	}
+
+	if err := lease.Unlock(context.Background()); err != nil {
+		t.Fatalf("Unlock: unexpected error: %v", err)
+	}
+	if ws := f.snapshot(); ws.Locked || ws.LockDescription != nil {
+		t.Errorf("workspace still locked after Unlock: %v %v", ws.Locked, ws.LockDescription)
+	}
+	if err := lease.Unlock(context.Background()); err != nil {
+		t.Errorf("second Unlock: unexpected error: %v", err)
+	}
+	if f.patches != 2 {
+		t.Errorf("got %d updates, want 2", f.patches)
+	}
+}
+
+func TestWorkspaceService_Lock_PatchesOnlyLock(t *testing.T) {
+	t.Parallel()
+
+	f := &fakeLockWorkspace{}
+	client := newTestClient(t, newLockServer(t, f))
+
+	lease, err := client.Workspaces.Lock(context.Background(), "org-1", "ws-1", "db migration")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := lease.Unlock(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := [][]string{{"lockDescription", "locked"}, {"lockDescription", "locked"}}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !reflect.DeepEqual(f.attrs, want) {
+		t.Errorf("patched attributes = %v, want %v", f.attrs, want)
+	}
+}
+
+func TestWorkspaceService_Lock_AlreadyLocked(t *testing.T) {
+	t.Parallel()
+
+	f := &fakeLockWorkspace{}
+	f.set(true, strPtr("held by ci"))
+	client := newTestClient(t, newLockServer(t, f))
+
+	_, err := client.Workspaces.Lock(context.Background(), "org-1", "ws-1", "mine")
+	if !errors.Is(err, terrakube.ErrWorkspaceLocked) {
+		t.Fatalf("error = %v, want %v", err, terrakube.ErrWorkspaceLocked)
+	}
+	if f.patches != 0 {
+		t.Errorf("got %d updates, want none", f.patches)
+	}
+
+	lease, err := client.Workspaces.ForceLock(context.Background(), "org-1", "ws-1", "mine")
+	if err != nil {
+		t.Fatalf("ForceLock: unexpected error: %v", err)
+	}
+	if err := lease.Check(context.Background()); err != nil {
+		t.Errorf("Check: unexpected error: %v", err)
+	}
+}
+
+func TestWorkspaceService_Lock_LostRace(t *testing.T) {
+	t.Parallel()
+
+	f := &fakeLockWorkspace{rival: strPtr("other [lease ffff]")}
+	client := newTestClient(t, newLockServer(t, f))
+
+	_, err := client.Workspaces.Lock(context.Background(), "org-1", "ws-1", "mine")
+	if !errors.Is(err, terrakube.ErrWorkspaceLocked) {
+		t.Fatalf("error = %v, want %v", err, terrakube.ErrWorkspaceLocked)
+	}
+	if ws := f.snapshot(); !ws.Locked || *ws.LockDescription != "other [lease ffff]" {
+		t.Errorf("the winning lock was modified: %v %v", ws.Locked, ws.LockDescription)
+	}
+}
+
+func TestLease_Unlock_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	f := &fakeLockWorkspace{}
+	client := newTestClient(t, newLockServer(t, f))
+
+	lease, err := client.Workspaces.Lock(context.Background(), "org-1", "ws-1", "mine")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = lease.Unlock(context.Background())
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			t.Errorf("Unlock: unexpected error: %v", err)
+		}
+	}
+	if f.patches != 2 {
+		t.Errorf("got %d updates, want one lock and one unlock", f.patches)
+	}
+}
+
+func TestLease_Unlock_Lost(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		locked bool
+		desc   *string
+	}{
+		{"unlocked by someone else", false, nil},
+		{"relocked by someone else", true, strPtr("other [lease 0000]")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			f := &fakeLockWorkspace{}
+			client := newTestClient(t, newLockServer(t, f))
+
+			lease, err := client.Workspaces.Lock(context.Background(), "org-1", "ws-1", "mine")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			f.set(tt.locked, tt.desc)
+
+			if err := lease.Unlock(context.Background()); !errors.Is(err, terrakube.ErrLockLost) {
+				t.Fatalf("error = %v, want %v", err, terrakube.ErrLockLost)
+			}
+			if ws := f.snapshot(); ws.Locked != tt.locked {
+				t.Error("Unlock modified a lock it no longer held")
+			}
+		})
+	}
+}
+
+func TestWorkspaceService_WithLock(t *testing.T) {
+	t.Parallel()
+
+	f := &fakeLockWorkspace{}
+	client := newTestClient(t, newLockServer(t, f))
+
+	fnErr := errors.New("apply failed")
+	err := client.Workspaces.WithLock(context.Background(), "org-1", "ws-1", "apply", func(context.Context) error {
+		if !f.snapshot().Locked {
+			t.Error("workspace not locked inside fn")
+		}
+		return fnErr
+	})
+	if !errors.Is(err, fnErr) {
+		t.Errorf("error = %v, want %v", err, fnErr)
+	}
+	if f.snapshot().Locked {
+		t.Error("workspace still locked after WithLock returned")
+	}
+}
+
+func TestWorkspaceService_WithLock_Panic(t *testing.T) {
+	t.Parallel()
+
+	f := &fakeLockWorkspace{}
+	client := newTestClient(t, newLockServer(t, f))
+
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Errorf("recovered %v, want %q", r, "boom")
+			}
+		}()
+		_ = client.Workspaces.WithLock(context.Background(), "org-1", "ws-1", "apply", func(context.Context) error {
+			panic("boom")
+		})
+	}()
+
+	if f.snapshot().Locked {
+		t.Error("workspace still locked after panic")
+	}
+}
+
+func TestWorkspaceService_Lock_EmptyIDs(t *testing.T) {
+	t.Parallel()
+
+	client := newTestClientFromURL(t, "https://example.com")
+
+	_, err := client.Workspaces.Lock(context.Background(), "", "ws-1", "reason")
+	assertValidationError(t, err, "organization ID")
+	_, err = client.Workspaces.Lock(context.Background(), "org-1", "", "reason")
+	assertValidationError(t, err, "workspace ID")
+}
@@ -0,0 +1,141 @@
+package terrakube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// WorkspaceOutput is a root module output of a workspace's latest state.
+type WorkspaceOutput struct {
+	Name  string
+	Value interface{}
+	// Type is the output's type constraint in Terraform's JSON type syntax,
+	// for example "string" or ["list","string"].
+	Type      json.RawMessage
+	Sensitive bool
+}
+
+// TypeName returns the output's primitive type ("string", "number", "bool")
+// or the kind of its collection or structural type ("list", "set", "map",
+// "object", "tuple"). It returns "dynamic" when the type is not recorded.
+func (o *WorkspaceOutput) TypeName() string {
+	var name string
+	if json.Unmarshal(o.Type, &name) == nil && name != "" {
+		return name
+	}
+	var complex []json.RawMessage
+	if json.Unmarshal(o.Type, &complex) == nil && len(complex) > 0 {
+		if json.Unmarshal(complex[0], &name) == nil {
+			return name
+		}
+	}
+	return "dynamic"
+}
+
+// Decode stores the output value in the value pointed to by v, using the
+// encoding/json decoding rules.
+func (o *WorkspaceOutput) Decode(v interface{}) error {
+	data, err := json.Marshal(o.Value)
+	if err != nil {
+		return fmt.Errorf("encoding output %s: %w", o.Name, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("decoding output %s: %w", o.Name, err)
+	}
+	return nil
+}
+
+// stateCache holds the latest raw state of each workspace with its MD5
+// checksum. Only one state per workspace is kept, and hits are parsed again
+// so that callers never share a *State.
+type stateCache struct {
+	mu     sync.Mutex
+	states map[string]cachedState
+}
+
+// cachedState is a workspace's latest state as downloaded.
+type cachedState struct {
+	md5  string
+	data []byte
+}
+
+func (c *stateCache) get(workspace, md5sum string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, ok := c.states[workspace]
+	if !ok || s.md5 != md5sum {
+		return nil, false
+	}
+	return s.data, true
+}
+
+func (c *stateCache) put(workspace, md5sum string, data []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.states[workspace] = cachedState{md5: md5sum, data: data}
+}
+
+// Outputs returns the root module outputs recorded in the workspace's latest
+// state, keyed by name. When the client was created with WithStateCache, the
+// downloaded state is reused while the latest history entry's checksum is
+// unchanged.
+// It returns a *ValidationError if orgID or workspaceID is empty, an error
+// wrapping ErrNoState if the workspace has no state, and a *APIError on server errors.
+func (s *WorkspaceService) Outputs(ctx context.Context, orgID, workspaceID string) (map[string]*WorkspaceOutput, error) {
+	if err := validateID("organization ID", orgID); err != nil {
+		return nil, err
+	}
+	if err := validateID("workspace ID", workspaceID); err != nil {
+		return nil, err
+	}
+
+	state, err := s.client.History.latestState(ctx, orgID, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := make(map[string]*WorkspaceOutput, len(state.Outputs))
+	for name, o := range state.Outputs {
+		outputs[name] = &WorkspaceOutput{
+			Name:      name,
+			Value:     o.Value,
+			Type:      o.Type,
+			Sensitive: o.Sensitive,
+		}
+	}
+	return outputs, nil
+}
+
+// latestState downloads and parses the workspace's highest-serial state,
+// consulting the client's state cache when one is configured.
+func (s *HistoryService) latestState(ctx context.Context, orgID, workspaceID string) (*State, error) {
+	h, err := s.Latest(ctx, orgID, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	cache := s.client.stateCache
+	key := orgID + "/" + workspaceID
+	cacheable := cache != nil && h.Md5 != nil && *h.Md5 != ""
+	if cacheable {
+		if data, ok := cache.get(key, *h.Md5); ok {
+			return ParseState(data)
+		}
+	}
+
+	data, err := s.downloadState(ctx, h)
+	if err != nil {
+		return nil, err
+	}
+	state, err := ParseState(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if cacheable {
+		cache.put(key, *h.Md5, data)
+	}
+	return state, nil
+}
@@ -0,0 +1,221 @@
+package terrakube_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	terrakube "github.com/terrakube-io/terrakube-go"
+	"github.com/terrakube-io/terrakube-go/testutil"
+)
+
+const testOutputsStateJSON = `{
+  "version": 4, "serial": 3, "lineage": "lin-1",
+  "outputs": {
+    "vpc_id": {"value": "vpc-123", "type": "string"},
+    "subnet_ids": {"value": ["a", "b"], "type": ["list", "string"]},
+    "db_password": {"value": "hunter2", "type": "string", "sensitive": true}
+  },
+  "resources": []
+}`
+
+// newOutputsServer serves a workspace whose latest history entry points at
+// testOutputsStateJSON, counting state downloads.
+func newOutputsServer(t *testing.T, downloads *atomic.Int32) *testutil.Server {
+	t.Helper()
+	md5sum := terrakube.StateChecksum([]byte(testOutputsStateJSON))
+
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("GET /api/v1/organization/org-1/workspace/ws-1/history", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSONAPIList(t, w, http.StatusOK, []*terrakube.History{
+			{ID: "h-2", Serial: 2, Output: "/tfstate/h-2.json"},
+			{ID: "h-3", Serial: 3, Output: "/tfstate/h-3.json", Md5: &md5sum},
+		})
+	})
+	srv.HandleFunc("GET /tfstate/h-3.json", func(w http.ResponseWriter, _ *http.Request) {
+		downloads.Add(1)
+		_, _ = w.Write([]byte(testOutputsStateJSON))
+	})
+	return srv
+}
+
+func TestWorkspaceService_Outputs(t *testing.T) {
+	t.Parallel()
+
+	var downloads atomic.Int32
+	client := newTestClient(t, newOutputsServer(t, &downloads))
+
+	outputs, err := client.Workspaces.Outputs(context.Background(), "org-1", "ws-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(outputs) != 3 {
+		t.Fatalf("got %d outputs, want 3", len(outputs))
+	}
+
+	vpc := outputs["vpc_id"]
+	if vpc.TypeName() != "string" || vpc.Value != "vpc-123" || vpc.Sensitive {
+		t.Errorf("vpc_id = %+v (type %s)", vpc, vpc.TypeName())
+	}
+
+	subnets := outputs["subnet_ids"]
+	if subnets.TypeName() != "list" {
+		t.Errorf("subnet_ids TypeName = %q, want %q", subnets.TypeName(), "list")
+	}
+	var ids []string
+	if err := subnets.Decode(&ids); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ids) != 2 || ids[0] != "a" {
+		t.Errorf("subnet_ids = %v, want [a b]", ids)
+	}
+
+	if !outputs["db_password"].Sensitive {
+		t.Error("db_password should be sensitive")
+	}
+
+	if _, err := client.Workspaces.Outputs(context.Background(), "org-1", "ws-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if downloads.Load() != 2 {
+		t.Errorf("state downloaded %d times without cache, want 2", downloads.Load())
+	}
+}
+
+func TestWorkspaceService_Outputs_Cached(t *testing.T) {
+	t.Parallel()
+
+	var downloads atomic.Int32
+	srv := newOutputsServer(t, &downloads)
+
+	client, err := terrakube.NewClient(
+		terrakube.WithEndpoint(srv.URL),
+		terrakube.WithToken("test-token"),
+		terrakube.WithStateCache(),
+	)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		outputs, err := client.Workspaces.Outputs(context.Background(), "org-1", "ws-1")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if outputs["vpc_id"].Value != "vpc-123" {
+			t.Errorf("vpc_id = %v, want %q", outputs["vpc_id"].Value, "vpc-123")
+		}
+	}
+	if downloads.Load() != 1 {
+		t.Errorf("state downloaded %d times with cache, want 1", downloads.Load())
+	}
+}
+
+func TestWorkspaceService_Outputs_CachedCopies(t *testing.T) {
+	t.Parallel()
+
+	var downloads atomic.Int32
+	srv := newOutputsServer(t, &downloads)
+
+	client, err := terrakube.NewClient(
+		terrakube.WithEndpoint(srv.URL),
+		terrakube.WithToken("test-token"),
+		terrakube.WithStateCache(),
+	)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	first, err := client.Workspaces.Outputs(context.Background(), "org-1", "ws-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	first["subnet_ids"].Value.([]interface{})[0] = "changed"
+
+	second, err := client.Workspaces.Outputs(context.Background(), "org-1", "ws-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := second["subnet_ids"].Value.([]interface{})[0]; got != "a" {
+		t.Errorf("subnet_ids[0] = %v after another caller's change, want %q", got, "a")
+	}
+	if downloads.Load() != 1 {
+		t.Errorf("state downloaded %d times with cache, want 1", downloads.Load())
+	}
+}
+
+func TestWorkspaceService_Outputs_CacheKeepsLatest(t *testing.T) {
+	t.Parallel()
+
+	states := []string{testOutputsStateJSON, strings.Replace(testOutputsStateJSON, `"serial": 3`, `"serial": 4`, 1)}
+	var current, downloads atomic.Int32
+
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("GET /api/v1/organization/org-1/workspace/ws-1/history", func(w http.ResponseWriter, _ *http.Request) {
+		i := current.Load()
+		md5sum := terrakube.StateChecksum([]byte(states[i]))
+		testutil.WriteJSONAPIList(t, w, http.StatusOK, []*terrakube.History{
+			{ID: fmt.Sprintf("h-%d", i), Serial: 3 + int(i), Output: fmt.Sprintf("/tfstate/h-%d.json", i), Md5: &md5sum},
+		})
+	})
+	srv.HandleFunc("GET /tfstate/{file}", func(w http.ResponseWriter, r *http.Request) {
+		downloads.Add(1)
+		i := 0
+		if r.PathValue("file") == "h-1.json" {
+			i = 1
+		}
+		_, _ = w.Write([]byte(states[i]))
+	})
+
+	client, err := terrakube.NewClient(
+		terrakube.WithEndpoint(srv.URL),
+		terrakube.WithToken("test-token"),
+		terrakube.WithStateCache(),
+	)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	// Each change of the latest state replaces the cached one, so going back
+	// to an earlier state downloads it again.
+	for _, i := range []int32{0, 0, 1, 1, 0} {
+		current.Store(i)
+		if _, err := client.Workspaces.Outputs(context.Background(), "org-1", "ws-1"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if downloads.Load() != 3 {
+		t.Errorf("state downloaded %d times, want 3", downloads.Load())
+	}
+}
+
+func TestWorkspaceService_Outputs_NoState(t *testing.T) {
+	t.Parallel()
+
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("GET /api/v1/organization/org-1/workspace/ws-1/history", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSONAPIList(t, w, http.StatusOK, []*terrakube.History{})
+	})
+
+	client := newTestClient(t, srv)
+
+	_, err := client.Workspaces.Outputs(context.Background(), "org-1", "ws-1")
+	if !errors.Is(err, terrakube.ErrNoState) {
+		t.Fatalf("error = %v, want %v", err, terrakube.ErrNoState)
+	}
+}
+
+func TestWorkspaceService_Outputs_EmptyIDs(t *testing.T) {
+	t.Parallel()
+
+	client := newTestClientFromURL(t, "https://example.com")
+
+	_, err := client.Workspaces.Outputs(context.Background(), "", "ws-1")
+	assertValidationError(t, err, "organization ID")
+	_, err = client.Workspaces.Outputs(context.Background(), "org-1", "")
+	assertValidationError(t, err, "workspace ID")
+}
@@ -0,0 +1,189 @@
+package terrakube
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Run commands recorded on jobs created by the workspace run helpers.
+const (
+	RunCommandPlan    = "plan"
+	RunCommandApply   = "apply"
+	RunCommandDestroy = "destroy"
+)
+
+// RunOptions configures a job queued by WorkspaceService.Plan, Apply, or Destroy.
+type RunOptions struct {
+	// TemplateID is the template to run. It takes precedence over Template.
+	TemplateID string
+	// Template is the name of an organization template to run. When both
+	// TemplateID and Template are empty, Plan and Apply use the workspace's
+	// default template; Destroy requires one of them.
+	Template string
+	// SkipRefresh disables the state refresh that normally precedes a plan.
+	SkipRefresh bool
+	// RefreshOnly only reconciles state with real infrastructure.
+	RefreshOnly bool
+	// Branch overrides the workspace branch for this run.
+	Branch string
+	// CommitID pins the run to a specific commit.
+	CommitID string
+	// Targets limits the run to the given resource addresses, like
+	// terraform's -target option. The job runs a copy of the template whose
+	// terraform flows pass them through TF_CLI_ARGS_plan, TF_CLI_ARGS_apply,
+	// and TF_CLI_ARGS_destroy.
+	Targets []string
+}
+
+// Plan queues a plan job for the workspace and returns the created job.
+// It returns a *ValidationError if orgID or workspaceID is empty, no template
+// can be resolved, or a target cannot be applied, and a *APIError on server
+// errors.
+func (s *WorkspaceService) Plan(ctx context.Context, orgID, workspaceID string, opts RunOptions) (*Job, error) {
+	return s.run(ctx, orgID, workspaceID, RunCommandPlan, opts)
+}
+
+// Apply queues an apply job for the workspace and returns the created job.
+// It returns a *ValidationError if orgID or workspaceID is empty, no template
+// can be resolved, or a target cannot be applied, and a *APIError on server
+// errors.
+func (s *WorkspaceService) Apply(ctx context.Context, orgID, workspaceID string, opts RunOptions) (*Job, error) {
+	return s.run(ctx, orgID, workspaceID, RunCommandApply, opts)
+}
+
+// Destroy queues a destroy job for the workspace and returns the created job.
+// The template must be given in opts and must run a destroy flow; the
+// workspace's default template is never used, since it would plan and apply.
+// It returns a *ValidationError if orgID or workspaceID is empty, no template
+// is given or found, or a target cannot be applied, and a *APIError on server
+// errors.
+func (s *WorkspaceService) Destroy(ctx context.Context, orgID, workspaceID string, opts RunOptions) (*Job, error) {
+	return s.run(ctx, orgID, workspaceID, RunCommandDestroy, opts)
+}
+
+// run resolves the template for a workspace run and creates the job.
+func (s *WorkspaceService) run(ctx context.Context, orgID, workspaceID, command string, opts RunOptions) (*Job, error) {
+	if err := validateID("organization ID", orgID); err != nil {
+		return nil, err
+	}
+	if err := validateID("workspace ID", workspaceID); err != nil {
+		return nil, err
+	}
+
+	if command == RunCommandDestroy && opts.TemplateID == "" && opts.Template == "" {
+		return nil, &ValidationError{Field: "template", Message: "must be set for a destroy run"}
+	}
+	for _, target := range opts.Targets {
+		if target == "" || strings.ContainsAny(target, "'\n") {
+			return nil, &ValidationError{Field: "targets", Message: fmt.Sprintf("%q is not a resource address", target)}
+		}
+	}
+	templateID, err := s.resolveTemplate(ctx, orgID, workspaceID, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &Job{
+		Command:           command,
+		Workspace:         &Workspace{ID: workspaceID},
+		TemplateReference: &templateID,
+		Refresh:           !opts.SkipRefresh,
+		RefreshOnly:       opts.RefreshOnly,
+	}
+	if opts.Branch != "" {
+		job.OverrideBranch = &opts.Branch
+	}
+	if opts.CommitID != "" {
+		job.CommitID = &opts.CommitID
+	}
+	if len(opts.Targets) > 0 {
+		tcl, err := s.targetedTemplate(ctx, orgID, templateID, opts.Targets)
+		if err != nil {
+			return nil, err
+		}
+		job.Tcl = &tcl
+	}
+
+	return s.client.Jobs.Create(ctx, orgID, job)
+}
+
+// resolveTemplate returns the template ID for a run: the explicit ID, the ID of
+// the named template, or the workspace's default template.
+func (s *WorkspaceService) resolveTemplate(ctx context.Context, orgID, workspaceID string, opts RunOptions) (string, error) {
+	if opts.TemplateID != "" {
+		return opts.TemplateID, nil
+	}
+
+	if opts.Template != "" {
+		templates, err := s.client.Templates.List(ctx, orgID, &ListOptions{Filter: rsqlEqual("name", opts.Template)})
+		if err != nil {
+			return "", err
+		}
+		for _, t := range templates {
+			if t.Name == opts.Template {
+				return t.ID, nil
+			}
+		}
+		return "", &ValidationError{Field: "template", Message: fmt.Sprintf("%q not found in organization", opts.Template)}
+	}
+
+	ws, err := s.Get(ctx, orgID, workspaceID)
+	if err != nil {
+		return "", err
+	}
+	if ws.TemplateID == "" {
+		return "", &ValidationError{Field: "template", Message: "must be set when the workspace has no default template"}
+	}
+	return ws.TemplateID, nil
+}
+
+// targetArgsEnv maps each terraform flow to the environment variable that
+// terraform reads extra arguments for its command from.
+var targetArgsEnv = map[FlowType]string{
+	FlowTerraformPlan:        "TF_CLI_ARGS_plan",
+	FlowTerraformPlanDestroy: "TF_CLI_ARGS_plan",
+	FlowTerraformApply:       "TF_CLI_ARGS_apply",
+	FlowTerraformDestroy:     "TF_CLI_ARGS_destroy",
+}
+
+// targetedTemplate returns the content of a template with -target arguments
+// added to the environment of each of its terraform flows.
+func (s *WorkspaceService) targetedTemplate(ctx context.Context, orgID, templateID string, targets []string) (string, error) {
+	tpl, err := s.client.Templates.Get(ctx, orgID, templateID)
+	if err != nil {
+		return "", err
+	}
+	tcl, err := ParseTemplate(tpl.Content)
+	if err != nil {
+		return "", err
+	}
+
+	args := make([]string, len(targets))
+	for i, target := range targets {
+		// Quoted so addresses with spaces or brackets stay one argument.
+		args[i] = "'-target=" + target + "'"
+	}
+	arg := strings.Join(args, " ")
+
+	targeted := false
+	for _, f := range tcl.Flow {
+		name, ok := targetArgsEnv[f.Type]
+		if !ok {
+			continue
+		}
+		if f.InputsEnv == nil {
+			f.InputsEnv = map[string]string{}
+		}
+		if prev := f.InputsEnv[name]; prev != "" {
+			f.InputsEnv[name] = prev + " " + arg
+		} else {
+			f.InputsEnv[name] = arg
+		}
+		targeted = true
+	}
+	if !targeted {
+		return "", &ValidationError{Field: "targets", Message: fmt.Sprintf("template %q has no terraform flow to target", tpl.Name)}
+	}
+	return MarshalTemplate(tcl)
+}
@@ -0,0 +1,335 @@
+package terrakube_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+
+	terrakube "github.com/terrakube-io/terrakube-go"
+	"github.com/terrakube-io/terrakube-go/testutil"
+)
+
+// decodeJobPayload extracts the attributes and workspace relationship from a job request body.
+func decodeJobPayload(t *testing.T, r *http.Request) (map[string]interface{}, string) {
+	t.Helper()
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		t.Fatalf("failed to read body: %v", err)
+	}
+	var payload struct {
+		Data struct {
+			Attributes    map[string]interface{} `json:"attributes"`
+			Relationships struct {
+				Workspace struct {
+					Data struct {
+						ID string `json:"id"`
+					} `json:"data"`
+				} `json:"workspace"`
+			} `json:"relationships"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("failed to unmarshal body: %v", err)
+	}
+	return payload.Data.Attributes, payload.Data.Relationships.Workspace.Data.ID
+}
+
+func TestWorkspaceService_Plan_DefaultTemplate(t *testing.T) {
+	t.Parallel()
+
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("GET /api/v1/organization/org-1/workspace/ws-1", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSONAPI(t, w, http.StatusOK, &terrakube.Workspace{ID: "ws-1", Name: "dev", TemplateID: "tpl-default"})
+	})
+	srv.HandleFunc("POST /api/v1/organization/org-1/job", func(w http.ResponseWriter, r *http.Request) {
+		attrs, wsID := decodeJobPayload(t, r)
+		if wsID != "ws-1" {
+			t.Errorf("workspace relationship = %q, want %q", wsID, "ws-1")
+		}
+		if attrs["templateReference"] != "tpl-default" {
+			t.Errorf("templateReference = %v, want %q", attrs["templateReference"], "tpl-default")
+		}
+		if attrs["command"] != terrakube.RunCommandPlan {
+			t.Errorf("command = %v, want %q", attrs["command"], terrakube.RunCommandPlan)
+		}
+		if attrs["refresh"] != true {
+			t.Errorf("refresh = %v, want true", attrs["refresh"])
+		}
+		testutil.WriteJSONAPI(t, w, http.StatusCreated, &terrakube.Job{ID: "job-1", Status: terrakube.JobStatusPending})
+	})
+
+	client := newTestClient(t, srv)
+
+	job, err := client.Workspaces.Plan(context.Background(), "org-1", "ws-1", terrakube.RunOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if job.ID != "job-1" {
+		t.Errorf("ID = %q, want %q", job.ID, "job-1")
+	}
+}
+
+func TestWorkspaceService_Apply_NamedTemplate(t *testing.T) {
+	t.Parallel()
+
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("GET /api/v1/organization/org-1/template", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("filter[template]"); got != `name=="Plan and apply"` {
+			t.Errorf("filter = %q, want %q", got, `name=="Plan and apply"`)
+		}
+		testutil.WriteJSONAPIList(t, w, http.StatusOK, []*terrakube.Template{{ID: "tpl-apply", Name: "Plan and apply"}})
+	})
+	srv.HandleFunc("POST /api/v1/organization/org-1/job", func(w http.ResponseWriter, r *http.Request) {
+		attrs, _ := decodeJobPayload(t, r)
+		if attrs["templateReference"] != "tpl-apply" {
+			t.Errorf("templateReference = %v, want %q", attrs["templateReference"], "tpl-apply")
+		}
+		if attrs["overrideBranch"] != "feature" {
+			t.Errorf("overrideBranch = %v, want %q", attrs["overrideBranch"], "feature")
+		}
+		if attrs["commitId"] != "abc123" {
+			t.Errorf("commitId = %v, want %q", attrs["commitId"], "abc123")
+		}
+		if attrs["refreshOnly"] != true {
+			t.Errorf("refreshOnly = %v, want true", attrs["refreshOnly"])
+		}
+		testutil.WriteJSONAPI(t, w, http.StatusCreated, &terrakube.Job{ID: "job-2", Command: terrakube.RunCommandApply})
+	})
+
+	client := newTestClient(t, srv)
+
+	job, err := client.Workspaces.Apply(context.Background(), "org-1", "ws-1", terrakube.RunOptions{
+		Template:    "Plan and apply",
+		Branch:      "feature",
+		CommitID:    "abc123",
+		RefreshOnly: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if job.ID != "job-2" {
+		t.Errorf("ID = %q, want %q", job.ID, "job-2")
+	}
+}
+
+func TestWorkspaceService_Destroy_TemplateID(t *testing.T) {
+	t.Parallel()
+
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("POST /api/v1/organization/org-1/job", func(w http.ResponseWriter, r *http.Request) {
+		attrs, _ := decodeJobPayload(t, r)
+		if attrs["templateReference"] != "tpl-destroy" {
+			t.Errorf("templateReference = %v, want %q", attrs["templateReference"], "tpl-destroy")
+		}
+		if attrs["command"] != terrakube.RunCommandDestroy {
+			t.Errorf("command = %v, want %q", attrs["command"], terrakube.RunCommandDestroy)
+		}
+		if attrs["refresh"] != false {
+			t.Errorf("refresh = %v, want false", attrs["refresh"])
+		}
+		testutil.WriteJSONAPI(t, w, http.StatusCreated, &terrakube.Job{ID: "job-3"})
+	})
+
+	client := newTestClient(t, srv)
+
+	_, err := client.Workspaces.Destroy(context.Background(), "org-1", "ws-1", terrakube.RunOptions{
+		TemplateID:  "tpl-destroy",
+		SkipRefresh: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestWorkspaceService_Destroy_NoTemplate(t *testing.T) {
+	t.Parallel()
+
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("GET /api/v1/organization/org-1/workspace/ws-1", func(w http.ResponseWriter, _ *http.Request) {
+		t.Error("Destroy must not fall back to the workspace's default template")
+		testutil.WriteJSONAPI(t, w, http.StatusOK, &terrakube.Workspace{ID: "ws-1", TemplateID: "tpl-default"})
+	})
+
+	client := newTestClient(t, srv)
+
+	_, err := client.Workspaces.Destroy(context.Background(), "org-1", "ws-1", terrakube.RunOptions{})
+	var ve *terrakube.ValidationError
+	if !errors.As(err, &ve) || ve.Field != "template" {
+		t.Fatalf("expected a template *ValidationError, got %v", err)
+	}
+}
+
+func TestWorkspaceService_Plan_TemplateNotFound(t *testing.T) {
+	t.Parallel()
+
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("GET /api/v1/organization/org-1/template", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSONAPIList(t, w, http.StatusOK, []*terrakube.Template{})
+	})
+
+	client := newTestClient(t, srv)
+
+	_, err := client.Workspaces.Plan(context.Background(), "org-1", "ws-1", terrakube.RunOptions{Template: "missing"})
+	var ve *terrakube.ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("expected *ValidationError, got %T: %v", err, err)
+	}
+	if ve.Field != "template" {
+		t.Errorf("ValidationError.Field = %q, want %q", ve.Field, "template")
+	}
+}
+
+func TestWorkspaceService_Plan_TemplateNameQuoted(t *testing.T) {
+	t.Parallel()
+
+	name := `Plan, then "apply";name==x \ done`
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("GET /api/v1/organization/org-1/template", func(w http.ResponseWriter, r *http.Request) {
+		want := `name=="Plan, then \"apply\";name==x \\ done"`
+		if got := r.URL.Query().Get("filter[template]"); got != want {
+			t.Errorf("filter = %q, want %q", got, want)
+		}
+		testutil.WriteJSONAPIList(t, w, http.StatusOK, []*terrakube.Template{{ID: "tpl-1", Name: name}})
+	})
+	srv.HandleFunc("POST /api/v1/organization/org-1/job", func(w http.ResponseWriter, r *http.Request) {
+		attrs, _ := decodeJobPayload(t, r)
+		if attrs["templateReference"] != "tpl-1" {
+			t.Errorf("templateReference = %v, want %q", attrs["templateReference"], "tpl-1")
+		}
+		testutil.WriteJSONAPI(t, w, http.StatusCreated, &terrakube.Job{ID: "job-1"})
+	})
+
+	client := newTestClient(t, srv)
+
+	if _, err := client.Workspaces.Plan(context.Background(), "org-1", "ws-1", terrakube.RunOptions{Template: name}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestWorkspaceService_Apply_Targets(t *testing.T) {
+	t.Parallel()
+
+	content := `flow:
+  - type: terraformPlan
+    name: Plan
+    step: 100
+    inputsEnv:
+      TF_CLI_ARGS_plan: -lock-timeout=60s
+  - type: approval
+    name: Approve
+    step: 150
+    team: ops
+  - type: terraformApply
+    name: Apply
+    step: 200
+`
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("GET /api/v1/organization/org-1/template/tpl-apply", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSONAPI(t, w, http.StatusOK, &terrakube.Template{ID: "tpl-apply", Name: "Plan and apply", Content: content})
+	})
+	srv.HandleFunc("POST /api/v1/organization/org-1/job", func(w http.ResponseWriter, r *http.Request) {
+		attrs, _ := decodeJobPayload(t, r)
+		if attrs["templateReference"] != "tpl-apply" {
+			t.Errorf("templateReference = %v, want %q", attrs["templateReference"], "tpl-apply")
+		}
+		raw, _ := attrs["tcl"].(string)
+		tcl, err := terrakube.ParseTemplate(raw)
+		if err != nil {
+			t.Errorf("job tcl: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		args := `'-target=aws_instance.web["a b"]' '-target=module.db'`
+		want := []map[string]string{
+			{"TF_CLI_ARGS_plan": "-lock-timeout=60s " + args},
+			nil,
+			{"TF_CLI_ARGS_apply": args},
+		}
+		if len(tcl.Flow) != len(want) {
+			t.Errorf("job tcl has %d flows, want %d", len(tcl.Flow), len(want))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		for i, f := range tcl.Flow {
+			if !reflect.DeepEqual(f.InputsEnv, want[i]) {
+				t.Errorf("flow %s inputsEnv = %v, want %v", f.Name, f.InputsEnv, want[i])
+			}
+		}
+		testutil.WriteJSONAPI(t, w, http.StatusCreated, &terrakube.Job{ID: "job-1"})
+	})
+
+	client := newTestClient(t, srv)
+
+	_, err := client.Workspaces.Apply(context.Background(), "org-1", "ws-1", terrakube.RunOptions{
+		TemplateID: "tpl-apply",
+		Targets:    []string{`aws_instance.web["a b"]`, "module.db"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestWorkspaceService_Plan_InvalidTargets(t *testing.T) {
+	t.Parallel()
+
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("GET /api/v1/organization/org-1/template/tpl-script", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSONAPI(t, w, http.StatusOK, &terrakube.Template{ID: "tpl-script", Name: "Script", Content: "flow:\n  - type: customScripts\n    step: 100\n"})
+	})
+
+	client := newTestClient(t, srv)
+	ctx := context.Background()
+
+	for _, targets := range [][]string{{""}, {"it's"}, {"module.db"}} {
+		_, err := client.Workspaces.Plan(ctx, "org-1", "ws-1", terrakube.RunOptions{TemplateID: "tpl-script", Targets: targets})
+		var ve *terrakube.ValidationError
+		if !errors.As(err, &ve) || ve.Field != "targets" {
+			t.Errorf("targets %q: expected a targets *ValidationError, got %v", targets, err)
+		}
+	}
+}
+
+func TestWorkspaceService_Plan_NoDefaultTemplate(t *testing.T) {
+	t.Parallel()
+
+	srv := testutil.NewServer(t)
+	srv.HandleFunc("GET /api/v1/organization/org-1/workspace/ws-1", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSONAPI(t, w, http.StatusOK, &terrakube.Workspace{ID: "ws-1", Name: "dev"})
+	})
+
+	client := newTestClient(t, srv)
+
+	_, err := client.Workspaces.Plan(context.Background(), "org-1", "ws-1", terrakube.RunOptions{})
+	var ve *terrakube.ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("expected *ValidationError, got %T: %v", err, err)
+	}
+}
+
+func TestWorkspaceService_Plan_EmptyIDs(t *testing.T) {
+	t.Parallel()
+
+	client := newTestClientFromURL(t, "https://example.com")
+
+	tests := []struct {
+		name  string
+		orgID string
+		wsID  string
+		field string
+	}{
+		{"empty org ID", "", "ws-1", "organization ID"},
+		{"empty workspace ID", "org-1", "", "workspace ID"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := client.Workspaces.Plan(context.Background(), tt.orgID, tt.wsID, terrakube.RunOptions{})
+			assertValidationError(t, err, tt.field)
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package terrakube
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// VariableSource identifies where a workspace's runtime variable comes from.
+type VariableSource string
+
+// Variable sources, from lowest to highest precedence.
+const (
+	VariableSourceOrganization VariableSource = "organization"
+	VariableSourceCollection   VariableSource = "collection"
+	VariableSourceWorkspace    VariableSource = "workspace"
+)
+
+// VariableCandidate is one definition of a variable for a workspace.
+type VariableCandidate struct {
+	Source VariableSource
+	// ID is the ID of the organization variable, collection item, or
+	// workspace variable.
+	ID          string
+	Value       string
+	Description string
+	Sensitive   bool
+	Hcl         bool
+	// CollectionID, CollectionName, and Priority are set for collection items.
+	CollectionID   string
+	CollectionName string
+	Priority       int32
+}
+
+// EffectiveVariable is a variable as a workspace's jobs see it.
+type EffectiveVariable struct {
+	Key      string
+	Category string
+	// Winner is the definition that is used.
+	Winner *VariableCandidate
+	// Shadowed lists the overridden definitions, highest precedence first.
+	Shadowed []*VariableCandidate
+}
+
+// EffectiveVariables returns the variables a workspace's jobs receive,
+// sorted by category and key, merging definitions with the same Key and
+// Category the way Terrakube does: workspace variables override collection
+// items, which override organization variables. Among collections attached
+// to the workspace, the one with the highest Priority wins; equal
+// priorities are ordered by collection name.
+// It returns a *ValidationError if orgID or workspaceID is empty and a *APIError on server errors.
+func (s *WorkspaceService) EffectiveVariables(ctx context.Context, orgID, workspaceID string) ([]*EffectiveVariable, error) {
+	if err := validateID("organization ID", orgID); err != nil {
+		return nil, err
+	}
+	if err := validateID("workspace ID", workspaceID); err != nil {
+		return nil, err
+	}
+
+	// Candidates are added from highest to lowest precedence, so the first
+	// one seen for a key wins.
+	byKey := map[string]*EffectiveVariable{}
+	var result []*EffectiveVariable
+	add := func(key, category string, c *VariableCandidate) {
+		k := variableKey(key, category)
+		if ev, ok := byKey[k]; ok {
+			ev.Shadowed = append(ev.Shadowed, c)
+			return
+		}
+		ev := &EffectiveVariable{Key: key, Category: category, Winner: c}
+		byKey[k] = ev
+		result = append(result, ev)
+	}
+
+	vars, err := s.client.Variables.List(ctx, orgID, workspaceID, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range vars {
+		add(v.Key, v.Category, &VariableCandidate{
+			Source: VariableSourceWorkspace, ID: v.ID, Value: v.Value, Description: v.Description,
+			Sensitive: v.Sensitive, Hcl: v.Hcl,
+		})
+	}
+
+	refs, err := s.workspaceReferences(ctx, orgID, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	for _, ref := range refs {
+		col := ref.Collection
+		items, err := s.client.CollectionItems.List(ctx, orgID, col.ID, nil)
+		if err != nil {
+			return nil, fmt.Errorf("listing items of collection %s: %w", col.Name, err)
+		}
+		for _, item := range items {
+			c := &VariableCandidate{
+				Source: VariableSourceCollection, ID: item.ID, Value: item.Value,
+				Sensitive: item.Sensitive, Hcl: item.Hcl,
+				CollectionID: col.ID, CollectionName: col.Name, Priority: col.Priority,
+			}
+			if item.Description != nil {
+				c.Description = *item.Description
+			}
+			add(item.Key, item.Category, c)
+		}
+	}
+
+	globals, err := s.client.OrganizationVariables.List(ctx, orgID, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range globals {
+		c := &VariableCandidate{
+			Source: VariableSourceOrganization, ID: v.ID, Value: v.Value, Description: v.Description, Hcl: v.Hcl,
+		}
+		if v.Sensitive != nil {
+			c.Sensitive = *v.Sensitive
+		}
+		add(v.Key, v.Category, c)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Category != result[j].Category {
+			return result[i].Category < result[j].Category
+		}
+		return result[i].Key < result[j].Key
+	})
+	return result, nil
+}
+
+// workspaceReferences returns the collection references attaching
+// collections to the workspace, with Collection set, highest precedence first.
+func (s *WorkspaceService) workspaceReferences(ctx context.Context, orgID, workspaceID string) ([]*CollectionReference, error) {
+	collections, err := s.client.Collections.List(ctx, orgID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var attached []*CollectionReference
+	for _, col := range collections {
+		refs, err := s.client.CollectionReferences.List(ctx, orgID, col.ID, nil)
+		if err != nil {
+			return nil, fmt.Errorf("listing references of collection %s: %w", col.Name, err)
+		}
+		for _, ref := range refs {
+			if ref.Workspace != nil && ref.Workspace.ID == workspaceID {
+				ref.Collection = col
+				attached = append(attached, ref)
+				break
+			}
+		}
+	}
+	sort.SliceStable(attached, func(i, j int) bool {
+		a, b := attached[i].Collection, attached[j].Collection
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+		return a.Name < b.Name
+	})
+	return attached, nil
+}
@@ -0,0 +1,137 @@
+package terrakube_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	terrakube "github.com/terrakube-io/terrakube-go"
+	"github.com/terrakube-io/terrakube-go/testutil"
+)
+
+func TestWorkspaceService_EffectiveVariables(t *testing.T) {
+	t.Parallel()
+
+	srv := testutil.NewServer(t)
+	org := "/api/v1/organization/org-1"
+	sensitive := true
+
+	srv.HandleFunc("GET "+org+"/globalvar", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSONAPIList(t, w, http.StatusOK, []*terrakube.OrganizationVariable{
+			{ID: "gv-1", Key: "region", Value: "us-east-1", Category: terrakube.CategoryTerraform},
+			{ID: "gv-2", Key: "TOKEN", Value: "", Category: terrakube.CategoryEnv, Sensitive: &sensitive},
+			{ID: "gv-3", Key: "owner", Value: "platform", Category: terrakube.CategoryTerraform},
+		})
+	})
+	srv.HandleFunc("GET "+org+"/collection", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSONAPIList(t, w, http.StatusOK, []*terrakube.Collection{
+			{ID: "col-low", Name: "low", Priority: 1},
+			{ID: "col-high", Name: "high", Priority: 10},
+			{ID: "col-other", Name: "other", Priority: 50},
+		})
+	})
+	srv.HandleFunc("GET "+org+"/collection/{id}/reference", func(w http.ResponseWriter, r *http.Request) {
+		ws := "ws-1"
+		if r.PathValue("id") == "col-other" {
+			ws = "ws-2"
+		}
+		// WriteJSONAPIList drops relationships, so write the document by hand.
+		testutil.WriteJSON(t, w, http.StatusOK, map[string]interface{}{
+			"data": []map[string]interface{}{{
+				"type": "reference",
+				"id":   "ref-" + r.PathValue("id"),
+				"relationships": map[string]interface{}{
+					"workspace": map[string]interface{}{
+						"data": map[string]string{"type": "workspace", "id": ws},
+					},
+				},
+			}},
+		})
+	})
+	srv.HandleFunc("GET "+org+"/collection/{id}/item", func(w http.ResponseWriter, r *http.Request) {
+		var items []*terrakube.CollectionItem
+		switch r.PathValue("id") {
+		case "col-low":
+			items = []*terrakube.CollectionItem{
+				{ID: "item-1", Key: "region", Value: "eu-west-1", Category: terrakube.CategoryTerraform},
+				{ID: "item-2", Key: "size", Value: "small", Category: terrakube.CategoryTerraform},
+			}
+		case "col-high":
+			items = []*terrakube.CollectionItem{
+				{ID: "item-3", Key: "region", Value: "eu-central-1", Category: terrakube.CategoryTerraform},
+			}
+		default:
+			t.Errorf("unexpected item listing for %s", r.PathValue("id"))
+		}
+		testutil.WriteJSONAPIList(t, w, http.StatusOK, items)
+	})
+	srv.HandleFunc("GET "+org+"/workspace/ws-1/variable", func(w http.ResponseWriter, _ *http.Request) {
+		testutil.WriteJSONAPIList(t, w, http.StatusOK, []*terrakube.Variable{
+			{ID: "var-1", Key: "size", Value: "large", Category: terrakube.CategoryTerraform},
+			{ID: "var-2", Key: "region", Value: "ENV region", Category: terrakube.CategoryEnv},
+		})
+	})
+
+	c := newTestClient(t, srv)
+	got, err := c.Workspaces.EffectiveVariables(context.Background(), "org-1", "ws-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	type want struct {
+		key, winner string
+		shadowed    []string
+	}
+	wants := []want{
+		{"ENV/TOKEN", "gv-2", nil},
+		{"ENV/region", "var-2", nil},
+		{"TERRAFORM/owner", "gv-3", nil},
+		{"TERRAFORM/region", "item-3", []string{"item-1", "gv-1"}},
+		{"TERRAFORM/size", "var-1", []string{"item-2"}},
+	}
+	if len(got) != len(wants) {
+		t.Fatalf("got %d variables, want %d", len(got), len(wants))
+	}
+	for i, w := range wants {
+		ev := got[i]
+		if key := ev.Category + "/" + ev.Key; key != w.key || ev.Winner.ID != w.winner {
+			t.Errorf("variable %d = %s from %s, want %s from %s", i, key, ev.Winner.ID, w.key, w.winner)
+		}
+		if len(ev.Shadowed) != len(w.shadowed) {
+			t.Errorf("%s shadowed %d candidates, want %v", w.key, len(ev.Shadowed), w.shadowed)
+			continue
+		}
+		for j, id := range w.shadowed {
+			if ev.Shadowed[j].ID != id {
+				t.Errorf("%s shadowed[%d] = %s, want %s", w.key, j, ev.Shadowed[j].ID, id)
+			}
+		}
+	}
+
+	region := got[3]
+	if region.Winner.Source != terrakube.VariableSourceCollection || region.Winner.CollectionName != "high" || region.Winner.Priority != 10 {
+		t.Errorf("region winner = %+v, want collection high", region.Winner)
+	}
+	if region.Shadowed[1].Source != terrakube.VariableSourceOrganization {
+		t.Errorf("region shadowed[1] source = %s, want organization", region.Shadowed[1].Source)
+	}
+	if !got[0].Winner.Sensitive {
+		t.Error("TOKEN should be sensitive")
+	}
+	if got[4].Winner.Source != terrakube.VariableSourceWorkspace {
+		t.Errorf("size source = %s, want workspace", got[4].Winner.Source)
+	}
+}
+
+func TestWorkspaceService_EffectiveVariables_Validation(t *testing.T) {
+	t.Parallel()
+
+	c := newTestClientFromURL(t, "https://example.com")
+	ctx := context.Background()
+
+	_, err := c.Workspaces.EffectiveVariables(ctx, "", "ws-1")
+	assertValidationError(t, err, "organization ID")
+
+	_, err = c.Workspaces.EffectiveVariables(ctx, "org-1", "")
+	assertValidationError(t, err, "workspace ID")
+}